@@ -0,0 +1,27 @@
+package main
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui/requestlog"
+)
+
+// auditRejection records an answer the response page refused, and why, in the
+// audit log the desktop app keeps
+func auditRejection(id string, details RequestDetails, answer, client, outcome string) {
+	err := requestlog.AppendAudit(filepath.Join(filepath.Dir(configPath), requestlog.AuditFileName), requestlog.AuditEntry{
+		Time:      time.Now(),
+		Event:     "rejected",
+		RequestID: id,
+		Question:  details.Question,
+		Options:   details.Options,
+		Via:       "web",
+		ClientIP:  client,
+		Answer:    answer,
+		Outcome:   outcome,
+	})
+	if err != nil {
+		logInfo("⚠️ Could not write audit log: " + err.Error())
+	}
+}
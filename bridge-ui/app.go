@@ -12,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/HarshalPatel1972/remote-bridge/responseui"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
@@ -25,13 +26,82 @@ type App struct {
 
 // BridgeConfig represents the full configuration structure
 type BridgeConfig struct {
-	Channel    string         `json:"channel"`
-	Source     string         `json:"source"`
-	Telegram   TelegramConfig `json:"telegram"`
-	Gmail      GmailConfig    `json:"gmail"`
-	WhatsApp   WhatsAppConfig `json:"whatsapp"`
-	SMS        SMSConfig      `json:"sms"`
-	NgrokToken string         `json:"ngrokToken"`
+	Channel        string             `json:"channel"`
+	Channels       []ChannelSetting   `json:"channels,omitempty"` // Fan-out list; empty = Channel only
+	Source         string             `json:"source"`
+	Telegram       TelegramConfig     `json:"telegram"`
+	Gmail          GmailConfig        `json:"gmail"`
+	WhatsApp       WhatsAppConfig     `json:"whatsapp"`
+	SMS            SMSConfig          `json:"sms"`
+	Slack          SlackConfig        `json:"slack"`
+	Webhook        WebhookConfig      `json:"webhook"`
+	Matrix         MatrixConfig       `json:"matrix"`
+	NgrokToken     string             `json:"ngrokToken"`
+	TimeoutSeconds int                `json:"timeout_seconds,omitempty"` // Default wait for ask_remote_human; 0 = 15 minutes
+	Escalation     []EscalationStep   `json:"escalation,omitempty"`      // Re-send unanswered requests
+	Approvers      []Approver         `json:"approvers,omitempty"`
+	Routes         []Route            `json:"routes,omitempty"`   // First match wins; no match = any one answer
+	SecondFactor   SecondFactorConfig `json:"secondFactor"`       // Confirms answers to high-risk questions
+	AskToken       string             `json:"askToken,omitempty"` // Bearer token the MCP adapter sends to /ask; generated on first run
+	ResponsePage   responseui.Config  `json:"responsePage"`       // Brand, language and theme of the response pages
+}
+
+// Approver is a named person and the channels used to reach them
+type Approver struct {
+	Contact
+	Channels []string `json:"channels"`
+}
+
+// Route sends matching questions to a set of approvers
+type Route struct {
+	Category  string   `json:"category,omitempty"`  // Matches the ask_remote_human category argument
+	Workspace string   `json:"workspace,omitempty"` // Substring of the agent's workspace path
+	Approvers []string `json:"approvers"`           // Approver names
+	Quorum    string   `json:"quorum,omitempty"`    // "any" (default), "all" or a count such as "2"
+}
+
+// ChannelSetting enables or disables one channel in the fan-out list
+type ChannelSetting struct {
+	Name    string `json:"name"` // "telegram", "slack", etc.
+	Enabled bool   `json:"enabled"`
+}
+
+// enabledChannels returns the channels to notify, falling back to the single Channel field
+func (c BridgeConfig) enabledChannels() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, ch := range c.Channels {
+		if ch.Enabled && ch.Name != "" && !seen[ch.Name] {
+			seen[ch.Name] = true
+			names = append(names, ch.Name)
+		}
+	}
+	if len(names) == 0 && c.Channel != "" {
+		names = append(names, c.Channel)
+	}
+	return names
+}
+
+// EscalationStep re-sends a still-unanswered request on more channels
+type EscalationStep struct {
+	AfterMinutes int      `json:"after_minutes"` // Measured from when the question was asked
+	Channels     []string `json:"channels"`
+	Contact      *Contact `json:"contact,omitempty"` // Backup approver; nil = the usual recipient
+}
+
+// Contact overrides who each channel reaches; empty fields keep the channel's own recipient.
+// The identities also tell approvers apart: only Telegram, SMS, Slack, Matrix and
+// signed webhook answers can be attributed, so routed requests ignore anonymous web links.
+// The webhook shares one secret, so it may speak for a single approver only.
+type Contact struct {
+	Name           string `json:"name,omitempty"`
+	TelegramChatID string `json:"telegram_chat_id,omitempty"` // Private chat ID (= user ID)
+	Phone          string `json:"phone,omitempty"`            // SMS number
+	Email          string `json:"email,omitempty"`
+	SlackChannel   string `json:"slack_channel,omitempty"` // Channel ID, or a user ID for a DM
+	SlackUser      string `json:"slack_user,omitempty"`    // Member ID, when sharing a channel
+	MatrixUser     string `json:"matrix_user,omitempty"`   // e.g. "@alice:example.org"
+	Webhook        bool   `json:"webhook,omitempty"`       // Signed webhook answers count as this approver's
 }
 
 type TelegramConfig struct {
@@ -42,6 +112,9 @@ type TelegramConfig struct {
 type GmailConfig struct {
 	Email       string `json:"email"`
 	AppPassword string `json:"app_password"`
+	To          string `json:"to,omitempty"`        // Recipient (defaults to Email)
+	SMTPHost    string `json:"smtp_host,omitempty"` // Defaults to smtp.gmail.com
+	SMTPPort    int    `json:"smtp_port,omitempty"` // 587 = STARTTLS, 465 = implicit TLS
 }
 
 type WhatsAppConfig struct {
@@ -54,6 +127,27 @@ type SMSConfig struct {
 	TwilioToken string `json:"twilio_token"`
 	From        string `json:"from"`
 	To          string `json:"to"`
+	BaseURL     string `json:"base_url,omitempty"` // Defaults to https://api.twilio.com
+}
+
+type SlackConfig struct {
+	BotToken      string `json:"bot_token"`
+	Channel       string `json:"channel"`
+	SigningSecret string `json:"signing_secret"`
+	AllowedUsers  string `json:"allowed_users"`          // Comma-separated member IDs who may press buttons, e.g. "U0123ABCD"
+	APIBaseURL    string `json:"api_base_url,omitempty"` // Defaults to https://slack.com/api
+}
+
+type WebhookConfig struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret"` // HMAC-SHA256 key for X-Momentum-Signature
+}
+
+type MatrixConfig struct {
+	Homeserver   string `json:"homeserver"`
+	AccessToken  string `json:"access_token"`
+	RoomID       string `json:"room_id"`
+	AllowedUsers string `json:"allowed_users"` // Comma-separated user IDs, e.g. "@me:example.org"
 }
 
 // RecentChannel represents a recently configured channel
@@ -74,6 +168,7 @@ func NewApp() *App {
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 	a.bridge.SetContext(ctx)
+	a.watchPendingRequests()
 	
 	// Auto-kill any existing bridge/ngrok processes on startup
 	a.KillExistingBridges()
@@ -141,9 +236,11 @@ func (a *App) StartBridge() string {
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return fmt.Sprintf("Error parsing config: %v", err)
 	}
-
-	if cfg.NgrokToken == "" {
-		return "Error: Ngrok token not configured"
+	if err := ensureAskToken(configPath, &cfg); err != nil {
+		return fmt.Sprintf("Error saving /ask token: %v", err)
+	}
+	if err := hashStoredPIN(configPath, &cfg); err != nil {
+		return fmt.Sprintf("Error saving PIN: %v", err)
 	}
 
 	if err := a.bridge.Start(cfg); err != nil {
@@ -180,13 +277,15 @@ func (a *App) SaveConfig(jsonConfig string) string {
 	if err := json.Unmarshal([]byte(jsonConfig), &cfg); err != nil {
 		return fmt.Sprintf("Error: Invalid JSON - %v", err)
 	}
-
-	prettyJSON, err := json.MarshalIndent(cfg, "", "  ")
-	if err != nil {
-		return fmt.Sprintf("Error: %v", err)
+	if cfg.AskToken == "" {
+		// Forms built before the token existed must not wipe it
+		if old, err := loadConfig(configPath); err == nil {
+			cfg.AskToken = old.AskToken
+		}
 	}
+	cfg.SecondFactor.hashPIN()
 
-	if err := ioutil.WriteFile(configPath, prettyJSON, 0644); err != nil {
+	if err := saveConfig(configPath, cfg); err != nil {
 		return fmt.Sprintf("Error saving config: %v", err)
 	}
 
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui"
+)
+
+// Vote is one approver's answer to a request
+type Vote = responseui.Vote
+
+// route returns the first route matching the question, or nil
+func (c BridgeConfig) route(category, workspace string) *Route {
+	for i, r := range c.Routes {
+		if r.Category != "" && !strings.EqualFold(r.Category, category) {
+			continue
+		}
+		if r.Workspace != "" && !strings.Contains(strings.ToLower(workspace), strings.ToLower(r.Workspace)) {
+			continue
+		}
+		return &c.Routes[i]
+	}
+	return nil
+}
+
+// approversFor looks up a route's approvers by name, skipping unknown names
+func (c BridgeConfig) approversFor(r Route) []Approver {
+	var approvers []Approver
+	for _, name := range r.Approvers {
+		for _, approver := range c.Approvers {
+			if approver.Name == name && !containsApprover(approvers, name) {
+				approvers = append(approvers, approver)
+			}
+		}
+	}
+	return approvers
+}
+
+// needed converts the route's quorum into a number of matching answers
+func (r Route) needed(approvers int) int {
+	switch q := strings.ToLower(strings.TrimSpace(r.Quorum)); q {
+	case "", "any":
+		return 1
+	case "all":
+		return approvers
+	default:
+		n, err := strconv.Atoi(q)
+		if err != nil || n < 1 {
+			return 1
+		}
+		if n > approvers {
+			return approvers
+		}
+		return n
+	}
+}
+
+// notifyApprovers sends the question to every approver over their own channels
+func (b *BridgeService) notifyApprovers(approvers []Approver, question string, options []string, requestID string) {
+	cfg := b.config()
+	var wg sync.WaitGroup
+	for _, approver := range approvers {
+		wg.Add(1)
+		go func(approver Approver) {
+			defer wg.Done()
+			b.fanOut(cfg.withContact(&approver.Contact), approver.Channels, question, options, requestID)
+		}(approver)
+	}
+	wg.Wait()
+}
+
+// approverFor maps a channel identity (Telegram user ID, phone, Slack member, Matrix user) to an approver name
+func (c BridgeConfig) approverFor(channel, id string) string {
+	if id == "" {
+		return ""
+	}
+	for _, approver := range c.Approvers {
+		var match bool
+		switch channel {
+		case "telegram":
+			match = strings.TrimSpace(approver.TelegramChatID) == id
+		case "sms":
+			match = approver.Phone == id
+		case "slack":
+			match = approver.SlackUser == id || approver.SlackChannel == id
+		case "matrix":
+			match = approver.MatrixUser == id
+		}
+		if match {
+			return approver.Name
+		}
+	}
+	return ""
+}
+
+// webhookApprover is the approver the webhook receiver answers for: the first
+// one marked "webhook", or "" when none is, so webhook answers never count
+// towards a quorum
+func (c BridgeConfig) webhookApprover() string {
+	for _, approver := range c.Approvers {
+		if approver.Webhook {
+			return approver.Name
+		}
+	}
+	return ""
+}
+
+// tally decides a request: the first answer for unrouted requests, otherwise the
+// first answer to reach Needed votes. An empty decision means the quorum became unreachable.
+func (d RequestData) tally() (answer string, decided bool) {
+	if len(d.Approvers) == 0 {
+		if len(d.Votes) == 0 {
+			return "", false
+		}
+		return d.Votes[0].Answer, true
+	}
+
+	counts := make(map[string]int)
+	best := 0
+	for _, vote := range d.Votes {
+		key := d.Schema.Key(vote.Answer)
+		counts[key]++
+		if counts[key] >= d.Needed {
+			return vote.Answer, true
+		}
+		if counts[key] > best {
+			best = counts[key]
+		}
+	}
+
+	if best+len(d.Approvers)-len(d.Votes) < d.Needed {
+		return "", true
+	}
+	return "", false
+}
+
+// hasVoted reports whether an approver already answered
+func (d RequestData) hasVoted(approver string) bool {
+	for _, vote := range d.Votes {
+		if vote.Approver == approver {
+			return true
+		}
+	}
+	return false
+}
+
+func containsApprover(approvers []Approver, name string) bool {
+	for _, approver := range approvers {
+		if approver.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui"
+	"github.com/HarshalPatel1972/remote-bridge/responseui/requestlog"
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultRequestTimeout is how long ask_remote_human waits for an answer unless configured
+const defaultRequestTimeout = 15 * time.Minute
+
+// AskRequest is one ask_remote_human call, whichever transport it arrived on
+type AskRequest struct {
+	Question      string
+	Options       []string
+	Category      string             // Optional, used for routing
+	Workspace     string             // Agent's workspace path, used for routing
+	Timeout       time.Duration      // 0 = config timeout_seconds or 15 minutes
+	DefaultOption string             // Answer used when nobody replies in time
+	Risk          string             // "low", "medium" or "high"; high needs a PIN or TOTP code when one is set up
+	Context       responseui.Context // Details, diff, command and file shown on the response page
+	Schema        responseui.Schema  // Shape of the answer; zero = options plus free text
+	RequestID     string             // Earlier request to wait on again after the bridge restarted
+}
+
+// AskResult is the decision returned to the agent
+type AskResult struct {
+	RequestID string `json:"request_id"`
+	Answer    string `json:"answer"`              // Empty when unanswered or no quorum was reached
+	Source    string `json:"source"`              // "human" or "timeout"
+	TimedOut  bool   `json:"timed_out"`           // No decision before the deadline
+	Needed    int    `json:"needed,omitempty"`    // Matching answers required; 0 = first answer wins
+	Approvers int    `json:"approvers,omitempty"` // Number of eligible approvers
+	Votes     []Vote `json:"votes,omitempty"`
+
+	Value interface{} `json:"value,omitempty"` // The answer as a typed value when the request had an answer schema
+}
+
+// newAskTool declares ask_remote_human with the arguments shared by every MCP mode
+func newAskTool(description string) mcp.Tool {
+	return mcp.NewTool("ask_remote_human",
+		mcp.WithDescription(description),
+		mcp.WithString("question", mcp.Required(), mcp.Description("The question to ask the user")),
+		mcp.WithArray("options", mcp.Description("Available response options (the choices for answer_schema types choice and multi)")),
+		mcp.WithString("category", mcp.Description("Optional category (e.g. \"deploy\") used to route the question to approvers")),
+		mcp.WithNumber("timeout_seconds", mcp.Description("How long to wait for an answer (default 15 minutes)")),
+		mcp.WithString("default_option", mcp.Description("Option to use if nobody answers in time")),
+		mcp.WithString("risk_level", mcp.Enum("low", "medium", "high"), mcp.Description("How risky the action is; \"high\" (e.g. destructive operations) requires the user's PIN or authenticator code")),
+		mcp.WithString("details", mcp.Description("Markdown with more background, shown under the question on the response page")),
+		mcp.WithString("diff", mcp.Description("Unified diff of the proposed change, shown in a diff viewer (max 256 KB)")),
+		mcp.WithString("command", mcp.Description("Command line the agent wants to run")),
+		mcp.WithString("file_path", mcp.Description("Path of the file the question is about")),
+		mcp.WithString("file_snippet", mcp.Description("Excerpt of file_path to show, highlighted by its extension")),
+		mcp.WithArray("links", mcp.Description("Related http(s) URLs, such as a pull request or CI run")),
+		mcp.WithObject("answer_schema", mcp.Description(answerSchemaDescription)),
+		mcp.WithString("request_id", mcp.Description("ID of a request asked before the bridge restarted, to wait for its answer instead of asking again")),
+	)
+}
+
+// answerSchemaDescription documents answer_schema for agents
+const answerSchemaDescription = `Shape of the answer. Without it the user picks an option or types anything. ` +
+	`{"type":"choice"} one of the options; {"type":"multi","min":1,"max":2} one or more options; {"type":"yes_no"}; ` +
+	`{"type":"number","min":1,"max":10}; {"type":"text","pattern":"[A-Z]+-\\d+"} text matching a regular expression; ` +
+	`{"type":"form","fields":[{"name":"replicas","label":"Replicas","type":"number","min":1},{"name":"env","type":"choice","options":["staging","prod"],"optional":true}]} several named answers. ` +
+	`The answer comes back as JSON with a typed "value".`
+
+// askArguments reads the ask_remote_human tool arguments
+func askArguments(request mcp.CallToolRequest) (AskRequest, error) {
+	var req AskRequest
+	var err error
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		req.Question, _ = args["question"].(string)
+		req.Category, _ = args["category"].(string)
+		req.DefaultOption, _ = args["default_option"].(string)
+		req.Risk, _ = args["risk_level"].(string)
+		req.RequestID, _ = args["request_id"].(string)
+		req.Context = responseui.ContextArguments(args)
+		if seconds, ok := args["timeout_seconds"].(float64); ok {
+			req.Timeout = time.Duration(seconds * float64(time.Second))
+		}
+		if opts, ok := args["options"].([]interface{}); ok {
+			for _, o := range opts {
+				if str, ok := o.(string); ok {
+					req.Options = append(req.Options, str)
+				}
+			}
+		}
+		req.Schema, err = responseui.SchemaArguments(args, req.Options)
+	}
+	return req, err
+}
+
+// askToolResult converts the outcome of ask into an MCP tool result
+func askToolResult(result AskResult, err error) *mcp.CallToolResult {
+	if err == context.Canceled {
+		return mcp.NewToolResultError("Request cancelled")
+	}
+	if err != nil {
+		return mcp.NewToolResultError(err.Error())
+	}
+	return mcp.NewToolResultStructured(result, result.Text())
+}
+
+// ask registers a request, notifies the routed approvers (or the enabled channels)
+// and waits until it is decided, times out or ctx is done
+func (b *BridgeService) ask(ctx context.Context, req AskRequest) (AskResult, error) {
+	if err := req.Schema.Validate(); err != nil {
+		return AskResult{}, err
+	}
+	if req.Schema.Structured() {
+		if req.DefaultOption != "" {
+			defaultOption, err := req.Schema.Normalize(req.DefaultOption)
+			if err != nil {
+				return AskResult{}, fmt.Errorf("default_option: %v", err)
+			}
+			req.DefaultOption = defaultOption
+		}
+		if req.Schema.Type == responseui.TypeYesNo && len(req.Options) == 0 {
+			req.Options = []string{"Yes", "No"} // Buttons for the chat channels
+		}
+	} else if req.DefaultOption != "" && len(req.Options) > 0 && !containsString(req.Options, req.DefaultOption) {
+		return AskResult{}, fmt.Errorf("default_option %q is not one of the options", req.DefaultOption)
+	}
+	if req.Risk != "" && !containsString([]string{"low", "medium", "high"}, req.Risk) {
+		return AskResult{}, fmt.Errorf("risk_level %q must be low, medium or high", req.Risk)
+	}
+	if err := req.Context.Validate(); err != nil {
+		return AskResult{}, err
+	}
+
+	// One snapshot for the whole request; settings can be saved meanwhile
+	cfg := b.config()
+
+	if req.Workspace == "" {
+		// In MCP mode the editor launches us inside the workspace
+		req.Workspace, _ = os.Getwd()
+	}
+
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = cfg.requestTimeout()
+	}
+
+	stopEscalation := func() {}
+	requestID, responseChan, adopted, err := b.adoptOrphan(req)
+	if err != nil {
+		return AskResult{}, err
+	}
+	if adopted {
+		// A request restored from before a restart: wait for that one
+		// (it may already be answered) instead of notifying again
+		b.log(fmt.Sprintf("♻️ Reattached to request %s", requestID))
+		timeout = time.Until(b.requestDeadline(requestID))
+	} else {
+		requestID = uuid.New().String()[:8]
+		data := RequestData{Question: req.Question, Options: req.Options, Context: req.Context, Schema: req.Schema, CreatedAt: time.Now(), Deadline: time.Now().Add(timeout)}
+		var approvers []Approver
+		if route := cfg.route(req.Category, req.Workspace); route != nil {
+			approvers = cfg.approversFor(*route)
+			for _, approver := range approvers {
+				data.Approvers = append(data.Approvers, approver.Name)
+			}
+			if len(approvers) > 0 {
+				data.Needed = route.needed(len(approvers))
+			}
+		}
+		if req.Risk == "high" {
+			switch {
+			case len(approvers) > 0 && cfg.SecondFactor.enabled():
+				// Approvers answer in their channels, which can't take a code
+				b.log(fmt.Sprintf("🔐 Refused high-risk question routed to %s: approvers can't give a PIN or TOTP code", strings.Join(data.Approvers, ", ")))
+				return AskResult{}, fmt.Errorf("risk_level high needs the user's PIN or authenticator code, but this question is routed to approvers, who answer in their channels and can't give one")
+			case !cfg.SecondFactor.enabled():
+				b.log(fmt.Sprintf("⚠️ High-risk request %s but no PIN or TOTP is set up in Settings", requestID))
+			default:
+				data.SecondFactor = true
+			}
+		}
+
+		// Create response channel
+		responseChan = make(chan string, 1)
+		b.pendingMu.Lock()
+		b.pendingRequests[requestID] = responseChan
+		b.requestData[requestID] = data
+		b.pendingMu.Unlock()
+
+		if err := b.store.Save(StoredRequest{
+			ID:        requestID,
+			Question:  req.Question,
+			Options:   req.Options,
+			Category:  req.Category,
+			Workspace: req.Workspace,
+			Risk:      req.Risk,
+			Context:   requestlog.OptionalContext(req.Context),
+			Schema:    requestlog.OptionalSchema(req.Schema),
+			Status:    "pending",
+			Approvers: data.Approvers,
+			Needed:    data.Needed,
+			CreatedAt: data.CreatedAt,
+			Deadline:  data.Deadline,
+
+			SecondFactor: data.SecondFactor,
+		}); err != nil {
+			b.log(fmt.Sprintf("⚠️ Could not persist request %s: %v", requestID, err))
+		}
+		b.emit("pendingRequest", PendingRequest{ID: requestID, Question: req.Question, Options: req.Options, Deadline: data.Deadline})
+
+		// Send notification, escalating while it stays unanswered. Phones get a
+		// summary of the context; the response page shows all of it.
+		question := req.Schema.WithHint(req.Context.WithSummary(req.Question))
+		buttons := req.Schema.Buttons(req.Options)
+		if data.SecondFactor {
+			// In-chat buttons and replies will be refused, so say where to answer
+			question = "🔐 HIGH RISK - answer on the response page with your PIN or authenticator code\n\n" + question
+		}
+		stopEscalation = b.startEscalation(requestID, question, buttons)
+		if len(approvers) > 0 {
+			b.log(fmt.Sprintf("👥 Routing %s to %s (%d of %d needed)", requestID, strings.Join(data.Approvers, ", "), data.Needed, len(approvers)))
+			b.notifyApprovers(approvers, question, buttons, requestID)
+		} else {
+			b.sendNotification(question, buttons, requestID)
+		}
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var answer string
+	select {
+	case answer = <-responseChan:
+	case <-timer.C:
+		b.expireRequest(requestID, req.DefaultOption)
+		answer = <-responseChan // The fallback, or an answer that won the race
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	stopEscalation()
+
+	// Cleanup
+	b.pendingMu.Lock()
+	data := b.requestData[requestID]
+	delete(b.pendingRequests, requestID)
+	delete(b.requestData, requestID)
+	b.pendingMu.Unlock()
+
+	if err != nil {
+		if !data.Decided {
+			b.store.Update(requestID, func(r *StoredRequest) { r.Status = "cancelled" })
+			b.audit("decision", requestID, data, Vote{}, "cancelled")
+		}
+		return AskResult{}, err
+	}
+	b.store.Update(requestID, func(r *StoredRequest) { r.Delivered = true })
+
+	source := "human"
+	if data.TimedOut {
+		source = "timeout"
+		b.log(fmt.Sprintf("⌛ Request %s timed out after %s", requestID, timeout))
+	}
+	return AskResult{
+		RequestID: requestID,
+		Answer:    answer,
+		Source:    source,
+		TimedOut:  data.TimedOut,
+		Needed:    data.Needed,
+		Approvers: len(data.Approvers),
+		Votes:     data.Votes,
+		Value:     data.Schema.Value(answer),
+	}, nil
+}
+
+// expireRequest decides a request with its default option (or no answer) unless a human got there first
+func (b *BridgeService) expireRequest(requestID, defaultOption string) {
+	b.pendingMu.Lock()
+	ch, exists := b.pendingRequests[requestID]
+	data := b.requestData[requestID]
+	if !exists || data.Decided {
+		b.pendingMu.Unlock()
+		return
+	}
+	data.Decided = true
+	data.TimedOut = true
+	b.requestData[requestID] = data
+	b.pendingMu.Unlock()
+
+	ch <- defaultOption
+	b.store.Update(requestID, func(r *StoredRequest) {
+		r.Status, r.Answer, r.Via = "expired", defaultOption, "timeout"
+	})
+	b.audit("decision", requestID, data, Vote{Answer: defaultOption, Via: "timeout"}, "expired")
+	refs := b.takeChannelRefs(requestID)
+	go b.closeOtherChannels(requestID, data, defaultOption, "timeout", refs, "")
+}
+
+// requestDeadline returns when a pending request times out
+func (b *BridgeService) requestDeadline(requestID string) time.Time {
+	b.pendingMu.Lock()
+	defer b.pendingMu.Unlock()
+	if deadline := b.requestData[requestID].Deadline; !deadline.IsZero() {
+		return deadline.UTC()
+	}
+	return time.Now().UTC().Add(b.config().requestTimeout())
+}
+
+// requestTimeout is the configured default wait for an answer
+func (c BridgeConfig) requestTimeout() time.Duration {
+	if c.TimeoutSeconds > 0 {
+		return time.Duration(c.TimeoutSeconds) * time.Second
+	}
+	return defaultRequestTimeout
+}
+
+// Text renders the result for the agent; unrouted human answers are returned as-is
+// and answers to an answer schema as the result's JSON
+func (r AskResult) Text() string {
+	if r.Value != nil {
+		data, _ := json.MarshalIndent(r, "", "  ")
+		return string(data)
+	}
+	if r.TimedOut {
+		if r.Answer == "" {
+			return "No human answered before the timeout."
+		}
+		return fmt.Sprintf("%s\n\n(No human answered before the timeout; this is the default option.)", r.Answer)
+	}
+	if r.Needed == 0 {
+		return r.Answer
+	}
+
+	var sb strings.Builder
+	if r.Answer != "" {
+		fmt.Fprintf(&sb, "%s\n\n", r.Answer)
+	} else {
+		sb.WriteString("No consensus: the approvers disagreed and the quorum can no longer be reached.\n\n")
+	}
+	fmt.Fprintf(&sb, "Quorum: %d of %d approvers\n", r.Needed, r.Approvers)
+	for _, vote := range r.Votes {
+		fmt.Fprintf(&sb, "- %s (%s): %s\n", vote.Approver, vote.Via, vote.Answer)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui"
+	"github.com/HarshalPatel1972/remote-bridge/responseui/requestlog"
+)
+
+// newStoredBridge is a bridge with its own request store and no channels
+func newStoredBridge(t *testing.T) *BridgeService {
+	t.Helper()
+	b := NewBridgeService()
+	store, err := requestlog.Open(filepath.Join(t.TempDir(), requestlog.FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.store = store
+	return b
+}
+
+func TestAskTimeout(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        AskRequest
+		wantAnswer string
+	}{
+		{"default option", AskRequest{Options: []string{"Yes", "No"}, DefaultOption: "No"}, "No"},
+		{"no default", AskRequest{Options: []string{"Yes", "No"}}, ""},
+		{"default normalized by the schema", AskRequest{Schema: responseui.Schema{Field: responseui.Field{Type: responseui.TypeYesNo}}, DefaultOption: "N"}, "no"},
+	}
+
+	for _, tt := range tests {
+		b := newStoredBridge(t)
+		tt.req.Question, tt.req.Workspace, tt.req.Timeout = "Deploy?", "/work", 50*time.Millisecond
+		result, err := b.ask(context.Background(), tt.req)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !result.TimedOut || result.Source != "timeout" || result.Answer != tt.wantAnswer {
+			t.Errorf("%s: got %+v", tt.name, result)
+		}
+		stored, _ := b.store.Get(result.RequestID)
+		if stored.Status != "expired" || stored.Answer != tt.wantAnswer || !stored.Delivered {
+			t.Errorf("%s: stored %s %q, delivered %v", tt.name, stored.Status, stored.Answer, stored.Delivered)
+		}
+		if len(b.pendingRequests) != 0 || len(b.requestData) != 0 {
+			t.Errorf("%s: request left pending", tt.name)
+		}
+	}
+}
+
+// TestExpireAnsweredRequest checks that the timeout doesn't overwrite an answer
+// recorded just before it fired
+func TestExpireAnsweredRequest(t *testing.T) {
+	for _, defaultOption := range []string{"", "No"} {
+		b := newStoredBridge(t)
+		ch := make(chan string, 1)
+		b.pendingRequests["req1"] = ch
+		b.requestData["req1"] = RequestData{Question: "Deploy?", Options: []string{"Yes", "No"}}
+		b.store.Save(StoredRequest{ID: "req1", Question: "Deploy?", Status: "pending"})
+
+		if err := b.submitVote("req1", Vote{Answer: "Yes", Via: "web"}); err != nil {
+			t.Fatal(err)
+		}
+		b.expireRequest("req1", defaultOption)
+
+		if answer := <-ch; answer != "Yes" || len(ch) != 0 {
+			t.Errorf("default %q: answer %q, %d more", defaultOption, answer, len(ch))
+		}
+		if b.requestData["req1"].TimedOut {
+			t.Errorf("default %q: marked timed out", defaultOption)
+		}
+		if stored, _ := b.store.Get("req1"); stored.Status != "answered" || stored.Answer != "Yes" {
+			t.Errorf("default %q: stored %s %q", defaultOption, stored.Status, stored.Answer)
+		}
+	}
+}
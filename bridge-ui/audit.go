@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui/requestlog"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// AuditEntry is one line of the audit log, which the remote bridge also writes
+type AuditEntry = requestlog.AuditEntry
+
+// AuditFilter narrows an audit query; empty fields match everything
+type AuditFilter struct {
+	RequestID string `json:"request_id"`
+	Event     string `json:"event"`
+	Since     string `json:"since"`  // RFC 3339 or YYYY-MM-DD
+	Until     string `json:"until"`  // RFC 3339 or YYYY-MM-DD (inclusive)
+	Search    string `json:"search"` // Case-insensitive match on question, answer, approver or user
+}
+
+// auditLogPath returns the audit log path next to the config file
+func auditLogPath() string {
+	return filepath.Join(filepath.Dir(getConfigPath()), requestlog.AuditFileName)
+}
+
+// audit appends an event for a request. vote carries the responder for responses;
+// for decisions it is the deciding answer.
+func (b *BridgeService) audit(event, requestID string, data RequestData, vote Vote, outcome string) {
+	entry := AuditEntry{
+		Time:         time.Now(),
+		Event:        event,
+		RequestID:    requestID,
+		Question:     data.Question,
+		Options:      data.Options,
+		Deliveries:   data.Deliveries,
+		Approver:     vote.Approver,
+		User:         vote.User,
+		Via:          vote.Via,
+		ClientIP:     vote.ClientIP,
+		Answer:       vote.Answer,
+		Outcome:      outcome,
+		SecondFactor: vote.SecondFactor,
+	}
+	for _, delivery := range data.Deliveries {
+		if delivery.OK && !containsString(entry.Notified, delivery.Channel) {
+			entry.Notified = append(entry.Notified, delivery.Channel)
+		}
+	}
+	if !data.CreatedAt.IsZero() {
+		entry.LatencyMs = entry.Time.Sub(data.CreatedAt).Milliseconds()
+	}
+
+	if err := requestlog.AppendAudit(auditLogPath(), entry); err != nil {
+		b.log(fmt.Sprintf("⚠️ Could not write audit log: %v", err))
+	}
+}
+
+// queryAudit returns the entries matching filter, oldest first
+func queryAudit(path string, filter AuditFilter) ([]AuditEntry, error) {
+	since, err := parseAuditTime(filter.Since, false)
+	if err != nil {
+		return nil, err
+	}
+	until, err := parseAuditTime(filter.Until, true)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []AuditEntry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	search := strings.ToLower(filter.Search)
+	entries := []AuditEntry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil {
+			continue
+		}
+		switch {
+		case filter.RequestID != "" && entry.RequestID != filter.RequestID,
+			filter.Event != "" && entry.Event != filter.Event,
+			!since.IsZero() && entry.Time.Before(since),
+			!until.IsZero() && !entry.Time.Before(until),
+			search != "" && !strings.Contains(strings.ToLower(entry.Question+"\n"+entry.Answer+"\n"+entry.Approver+"\n"+entry.User), search):
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// parseAuditTime accepts RFC 3339 or a local date; a date used as an upper bound covers the whole day
+func parseAuditTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD or RFC 3339", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// writeAudit exports entries as "csv" or "json"
+func writeAudit(w io.Writer, entries []AuditEntry, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"time", "event", "request_id", "question", "options", "notified", "deliveries", "approver", "user", "via", "client_ip", "answer", "outcome", "latency_ms", "second_factor"})
+		for _, e := range entries {
+			var deliveries []string
+			for _, d := range e.Deliveries {
+				status := "ok"
+				if !d.OK {
+					status = "failed: " + d.Error
+				}
+				deliveries = append(deliveries, d.Channel+" "+status)
+			}
+			cw.Write([]string{
+				e.Time.Format(time.RFC3339),
+				e.Event,
+				e.RequestID,
+				e.Question,
+				strings.Join(e.Options, " | "),
+				strings.Join(e.Notified, ", "),
+				strings.Join(deliveries, "; "),
+				e.Approver,
+				e.User,
+				e.Via,
+				e.ClientIP,
+				e.Answer,
+				e.Outcome,
+				strconv.FormatInt(e.LatencyMs, 10),
+				e.SecondFactor,
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("unknown format %q: use csv or json", format)
+	}
+}
+
+// QueryAudit returns the audit log entries matching filter, oldest first
+func (a *App) QueryAudit(filter AuditFilter) ([]AuditEntry, error) {
+	return queryAudit(auditLogPath(), filter)
+}
+
+// ExportAudit asks where to save and writes the matching entries as "csv" or "json"
+func (a *App) ExportAudit(format string, filter AuditFilter) string {
+	entries, err := queryAudit(auditLogPath(), filter)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export audit log",
+		DefaultFilename: fmt.Sprintf("momentum-audit-%s.%s", time.Now().Format("2006-01-02"), format),
+		Filters:         []runtime.FileFilter{{DisplayName: strings.ToUpper(format), Pattern: "*." + format}},
+	})
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if path == "" {
+		return "Export cancelled"
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	defer f.Close()
+	if err := writeAudit(f, entries, format); err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	return fmt.Sprintf("Exported %d entries to %s", len(entries), path)
+}
+
+// runAuditCommand implements `bridge-ui audit`, printing or saving the audit log
+func runAuditCommand(args []string) error {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	format := fs.String("format", "csv", "Output format: csv or json")
+	output := fs.String("o", "", "Write to this file instead of stdout")
+	var filter AuditFilter
+	fs.StringVar(&filter.RequestID, "request", "", "Only this request ID")
+	fs.StringVar(&filter.Event, "event", "", "Only this event: response, rejected or decision")
+	fs.StringVar(&filter.Since, "since", "", "From this date (YYYY-MM-DD or RFC 3339)")
+	fs.StringVar(&filter.Until, "until", "", "Up to and including this date")
+	fs.StringVar(&filter.Search, "search", "", "Text to look for in question, answer or responder")
+	fs.Parse(args)
+
+	entries, err := queryAudit(auditLogPath(), filter)
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return writeAudit(w, entries, *format)
+}
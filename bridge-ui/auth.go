@@ -0,0 +1,31 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// ensureAskToken generates the /ask bearer token on first run and saves it in
+// bridge-config.json, where the MCP adapter reads it
+func ensureAskToken(path string, cfg *BridgeConfig) error {
+	if cfg.AskToken != "" {
+		return nil
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+	cfg.AskToken = hex.EncodeToString(key)
+	return saveConfig(path, *cfg)
+}
+
+// askAuthorized checks the Authorization: Bearer header against the configured token.
+// With no token every call is refused.
+func (b *BridgeService) askAuthorized(r *http.Request) bool {
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	want := b.config().AskToken
+	return want != "" && subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1
+}
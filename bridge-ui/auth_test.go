@@ -0,0 +1,46 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestAskEndpointsAuth checks that /ask and /permission refuse callers without
+// the adapter's bearer token; with it the request goes on to the (bad) body
+func TestAskEndpointsAuth(t *testing.T) {
+	const token = "0123456789abcdef"
+	handlers := map[string]func(*BridgeService, http.ResponseWriter, *http.Request){
+		"/ask":        (*BridgeService).handleAsk,
+		"/permission": (*BridgeService).handlePermission,
+	}
+	tests := []struct {
+		name       string
+		configured string
+		header     string
+		want       int
+	}{
+		{"no header", token, "", 401},
+		{"wrong token", token, "Bearer fedcba9876543210", 401},
+		{"token without Bearer", token, "Basic " + token, 401},
+		{"prefix of the token", token, "Bearer " + token[:8], 401},
+		{"no token configured", "", "Bearer ", 401},
+		{"right token", token, "Bearer " + token, 400},
+	}
+	for path, handler := range handlers {
+		for _, tt := range tests {
+			b := NewBridgeService()
+			b.cfg.AskToken = tt.configured
+			r := httptest.NewRequest("POST", path, strings.NewReader("not json"))
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler(b, w, r)
+			if w.Code != tt.want {
+				t.Errorf("%s %s: status %d, want %d", path, tt.name, w.Code, tt.want)
+			}
+		}
+	}
+}
@@ -3,23 +3,30 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
-	"github.com/google/uuid"
+	"github.com/HarshalPatel1972/remote-bridge/responseui"
+	"github.com/HarshalPatel1972/remote-bridge/responseui/links"
+	"github.com/HarshalPatel1972/remote-bridge/responseui/requestlog"
+	"github.com/HarshalPatel1972/remote-bridge/responseui/secondfactor"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 	"golang.ngrok.com/ngrok"
 	"golang.ngrok.com/ngrok/config"
-
-	tgbotapi "gopkg.in/telegram-bot-api.v4"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // BridgeService manages the MCP server and Ngrok tunnel
@@ -27,20 +34,53 @@ type BridgeService struct {
 	ctx       context.Context // Wails app context for events
 	cancel    context.CancelFunc
 	tunnel    ngrok.Tunnel
+	listener  net.Listener // Local listener when running without ngrok
 	running   bool
 	mu        sync.Mutex
 	publicURL string
 	cfg       BridgeConfig
+	tgBot     *tgbotapi.BotAPI
 
 	// Pending requests waiting for user response
 	pendingRequests map[string]chan string
 	requestData     map[string]RequestData
 	pendingMu       sync.Mutex
+
+	// Request IDs notified by SMS, oldest first (replies carry no thread ID)
+	smsRequests []string
+	// Request ID -> Telegram messages, so replies can be matched to requests
+	tgMessages map[string][]telegramMessageRef
+	// On-disk request log, and restored requests no ask call has reattached to yet
+	store     *requestlog.Log
+	storeOnce sync.Once
+	restored  bool
+	orphans   map[string]bool
+
+	// Matrix event ID -> request ID, for replies and reactions
+	mxEvents map[string]string
+	// Request ID -> Slack message, so it can be updated when answered elsewhere
+	slackMessages map[string][]slackMessageRef
+	// Recent wrong PIN/TOTP codes, over all requests
+	factors *secondfactor.Limiter
 }
 
 type RequestData struct {
-	Question string
-	Options  []string
+	Question   string
+	Options    []string
+	Context    responseui.Context
+	Schema     responseui.Schema
+	Deliveries []ChannelDelivery
+
+	Approvers []string // Eligible approvers; empty = the first answer from anyone wins
+	Needed    int      // Matching answers required when Approvers is set
+	Votes     []Vote
+	UsedLinks []string // requestlog.LinkID of the response links that answered it
+	Decided   bool
+	TimedOut  bool // Decided by the timeout rather than a human
+	CreatedAt time.Time
+	Deadline  time.Time // When the request times out
+
+	SecondFactor bool // High risk: answers need a PIN or TOTP code
 }
 
 // NewBridgeService creates a new bridge service instance
@@ -48,6 +88,11 @@ func NewBridgeService() *BridgeService {
 	return &BridgeService{
 		pendingRequests: make(map[string]chan string),
 		requestData:     make(map[string]RequestData),
+		tgMessages:      make(map[string][]telegramMessageRef),
+		mxEvents:        make(map[string]string),
+		slackMessages:   make(map[string][]slackMessageRef),
+		orphans:         make(map[string]bool),
+		factors:         factorLimiter(),
 	}
 }
 
@@ -58,6 +103,12 @@ func (b *BridgeService) SetContext(ctx context.Context) {
 
 // log emits a log message to the frontend
 func (b *BridgeService) log(message string) {
+	b.emit("log", message)
+	fmt.Println("[BRIDGE]", message)
+}
+
+// emit sends an event to the frontend when running with a UI
+func (b *BridgeService) emit(event string, data interface{}) {
 	// Only emit to UI if we have a valid Wails context
 	if b.ctx != nil {
 		// Try to emit, but don't fail if context is invalid
@@ -68,10 +119,9 @@ func (b *BridgeService) log(message string) {
 		}()
 		// Only call EventsEmit if we're in UI mode
 		if b.ctx != context.Background() {
-			runtime.EventsEmit(b.ctx, "log", message)
+			runtime.EventsEmit(b.ctx, event, data)
 		}
 	}
-	fmt.Println("[BRIDGE]", message)
 }
 
 // IsRunning returns the current state
@@ -81,6 +131,13 @@ func (b *BridgeService) IsRunning() bool {
 	return b.running
 }
 
+// config returns the configuration the bridge was started with
+func (b *BridgeService) config() BridgeConfig {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.cfg
+}
+
 // Start initializes the Ngrok tunnel and MCP server
 func (b *BridgeService) Start(cfg BridgeConfig) error {
 	b.mu.Lock()
@@ -97,9 +154,39 @@ func (b *BridgeService) Start(cfg BridgeConfig) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	b.cancel = cancel
 
+	// Without a token, serve locally - in-chat buttons (Telegram) still work
+	if cfg.NgrokToken == "" {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			b.mu.Lock()
+			b.running = false
+			b.mu.Unlock()
+			errMsg := fmt.Sprintf("Failed to start local server: %v", err)
+			b.log("❌ " + errMsg)
+			return fmt.Errorf(errMsg)
+		}
+		b.listener = listener
+		b.publicURL = "http://" + listener.Addr().String()
+
+		b.log("🚀 Starting Remote Bridge...")
+		b.log("⚠️ No Ngrok token - response links only work on this machine")
+		b.log(fmt.Sprintf("🌐 HTTP Server listening on %s", b.publicURL))
+
+		if b.ctx != nil && b.ctx != context.Background() {
+			runtime.EventsEmit(b.ctx, "publicURL", b.publicURL)
+		}
+
+		go b.startTelegram(ctx)
+		go b.startMatrix(ctx)
+		go b.runHTTPServer(ctx, listener)
+		return nil
+	}
+
 	// Log that we're ATTEMPTING to start (not success yet)
 	b.log("🔄 Attempting to start ngrok tunnel...")
-	b.log(fmt.Sprintf("📝 Using auth token: %s...", cfg.NgrokToken[:10]))
+	if len(cfg.NgrokToken) > 10 {
+		b.log(fmt.Sprintf("📝 Using auth token: %s...", cfg.NgrokToken[:10]))
+	}
 
 	// Start Ngrok tunnel (DON'T log success yet - ngrok might fail!)
 	tunnel, err := ngrok.Listen(ctx,
@@ -128,6 +215,10 @@ func (b *BridgeService) Start(cfg BridgeConfig) error {
 		runtime.EventsEmit(b.ctx, "publicURL", b.publicURL)
 	}
 
+	// Answer Telegram buttons and Matrix replies in-chat
+	go b.startTelegram(ctx)
+	go b.startMatrix(ctx)
+
 	// Start HTTP handler as goroutine
 	// Tunnel stays alive because it's stored in b.tunnel
 	go b.runHTTPServer(ctx, tunnel)
@@ -153,6 +244,11 @@ func (b *BridgeService) Stop() {
 	if b.tunnel != nil {
 		b.tunnel.CloseWithContext(context.Background())
 	}
+	if b.listener != nil {
+		b.listener.Close()
+		b.listener = nil
+	}
+	b.publicURL = ""
 
 	// Kill any lingering ngrok processes
 	exec.Command("powershell", "-Command",
@@ -172,14 +268,13 @@ func (b *BridgeService) runMCPServer(ctx context.Context, tunnel ngrok.Tunnel) {
 	s := server.NewMCPServer("Remote Bridge", "1.0.0")
 
 	// Register the ask_remote_human tool
-	askTool := mcp.NewTool("ask_remote_human",
-		mcp.WithDescription("Ask the user a question via configured notification channels (Telegram/WhatsApp/SMS)"),
-		mcp.WithString("question", mcp.Required(), mcp.Description("The question to ask the user")),
-		mcp.WithArray("options", mcp.Required(), mcp.Description("Available response options")),
-	)
+	askTool := newAskTool("Ask the user a question via configured notification channels (Telegram/WhatsApp/SMS)")
 
 	s.AddTool(askTool, func(c context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		return b.handleAskHuman(request)
+		return b.handleAskHuman(c, request)
+	})
+	s.AddTool(newPermissionTool(), func(c context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return b.handleRequestPermission(c, request)
 	})
 
 	b.log("✅ MCP Server Ready - Waiting for requests...")
@@ -193,53 +288,158 @@ func (b *BridgeService) runMCPServer(ctx context.Context, tunnel ngrok.Tunnel) {
 }
 
 // handleAskHuman processes the ask_remote_human tool call
-func (b *BridgeService) handleAskHuman(request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (b *BridgeService) handleAskHuman(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	b.log("🔔 Received ask_remote_human request")
 
-	var question string
-	var options []string
+	req, err := askArguments(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	b.log(fmt.Sprintf("📨 Question: %s", req.Question))
+	b.log(fmt.Sprintf("📋 Options: %v", req.Options))
 
-	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
-		if q, ok := args["question"].(string); ok {
-			question = q
-		}
-		if opts, ok := args["options"].([]interface{}); ok {
-			for _, o := range opts {
-				if str, ok := o.(string); ok {
-					options = append(options, str)
-				}
-			}
-		}
+	// Wait for response (or the timeout fallback)
+	result, err := b.ask(ctx, req)
+	if err == nil {
+		b.log(fmt.Sprintf("✅ User Response (%s): %s", result.Source, result.Answer))
 	}
 
-	b.log(fmt.Sprintf("📨 Question: %s", question))
-	b.log(fmt.Sprintf("📋 Options: %v", options))
+	return askToolResult(result, err), nil
+}
 
-	// Generate request ID
-	requestID := uuid.New().String()[:8]
+// handleRequestPermission processes the request_permission tool call
+func (b *BridgeService) handleRequestPermission(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	req := permissionArguments(request)
+	b.log(fmt.Sprintf("🛡️ Permission requested: %s", req.Action))
 
-	// Create response channel
-	responseChan := make(chan string, 1)
-	b.pendingMu.Lock()
-	b.pendingRequests[requestID] = responseChan
-	b.requestData[requestID] = RequestData{Question: question, Options: options}
-	b.pendingMu.Unlock()
+	result, err := b.requestPermission(ctx, req)
+	if err == nil {
+		b.log(fmt.Sprintf("✅ Permission %s: approved=%t", result.RequestID, result.Approved))
+	}
+	return permissionToolResult(result, err), nil
+}
 
-	// Send notification
-	b.sendNotification(question, options, requestID)
+// Reasons submitVote refuses an answer, worded for the person who gave it
+var (
+	errRequestGone          = errors.New("this request has expired or was already answered")
+	errNotApprover          = errors.New("you are not an approver for this request, or already voted")
+	errSecondFactorRequired = errors.New("this request needs a PIN or authenticator code - answer it on the response page")
+	errLinkUsed             = errors.New("this link has already been used to answer")
+)
 
-	// Wait for response (with timeout handled by MCP)
-	response := <-responseChan
+// refusal words a submitVote error for the person who answered in a chat channel
+func refusal(err error) string {
+	switch err {
+	case errRequestGone:
+		return "⌛ This request has expired or was already answered."
+	case errSecondFactorRequired:
+		return "🔐 This request needs your PIN or authenticator code. Answer it on the response page."
+	case errNotApprover:
+		return "⛔ You are not an approver for this request, or you already voted."
+	}
+	return "⚠️ Answer refused: " + err.Error()
+}
 
-	// Cleanup
+// resolveRequest records an answer to a pending request without blocking.
+// Returns false if the request is unknown, already decided, or the approver may not vote.
+// Once decided (first answer, or the quorum for routed requests) the other channels are marked as answered.
+func (b *BridgeService) resolveRequest(requestID string, vote Vote) bool {
+	return b.submitVote(requestID, vote) == nil
+}
+
+// submitVote is resolveRequest for channels that tell the person why an
+// answer was refused
+func (b *BridgeService) submitVote(requestID string, vote Vote) error {
+	_, err := b.castVote(requestID, vote, "", "")
+	return err
+}
+
+// submitLinkVote is submitVote for an answer from a single-use response link.
+// The link is spent only if the answer counts.
+func (b *BridgeService) submitLinkVote(requestID, token string, vote Vote) error {
+	_, err := b.castVote(requestID, vote, requestlog.LinkID(token), "")
+	return err
+}
+
+// submitMessageVote is submitVote for an answer given on a notification, named
+// by its message key. It reports whether the vote decided the request; only
+// then does the handler close that message, which the others are closed around.
+func (b *BridgeService) submitMessageVote(requestID string, vote Vote, message string) (bool, error) {
+	return b.castVote(requestID, vote, "", message)
+}
+
+// castVote records a vote, and spends linkID with it unless linkID is empty
+func (b *BridgeService) castVote(requestID string, vote Vote, linkID, message string) (bool, error) {
+	answer, via, approver := vote.Answer, vote.Via, vote.Approver
 	b.pendingMu.Lock()
-	delete(b.pendingRequests, requestID)
-	delete(b.requestData, requestID)
+	ch, exists := b.pendingRequests[requestID]
+	data := b.requestData[requestID]
+	if !exists || data.Decided {
+		b.pendingMu.Unlock()
+		return false, errRequestGone
+	}
+	if linkID != "" && containsString(data.UsedLinks, linkID) {
+		b.pendingMu.Unlock()
+		return false, errLinkUsed
+	}
+	if len(data.Approvers) > 0 && (!containsString(data.Approvers, approver) || data.hasVoted(approver)) {
+		b.pendingMu.Unlock()
+		b.log(fmt.Sprintf("🚫 Ignored answer to %s from %q via %s: not an approver or already voted", requestID, approver, via))
+		b.audit("rejected", requestID, data, vote, "")
+		return false, errNotApprover
+	}
+	if data.SecondFactor && vote.SecondFactor == "" {
+		b.pendingMu.Unlock()
+		b.log(fmt.Sprintf("🔐 Ignored answer to high-risk %s via %s: needs a PIN or authenticator code on the response page", requestID, via))
+		b.audit("rejected", requestID, data, vote, "second_factor_required")
+		return false, errSecondFactorRequired
+	}
+	// Answer schemas: refuse answers that don't fit, and store the rest in one
+	// form so that equal answers count as the same vote
+	normalized, err := data.Schema.Normalize(answer)
+	if err != nil {
+		b.pendingMu.Unlock()
+		b.log(fmt.Sprintf("🚫 Ignored answer %q to %s via %s: %v", answer, requestID, via, err))
+		b.audit("rejected", requestID, data, vote, "invalid_answer")
+		return false, err
+	}
+	vote.Answer, answer = normalized, normalized
+
+	vote.At = time.Now()
+	data.Votes = append(data.Votes, vote)
+	if linkID != "" {
+		data.UsedLinks = append(data.UsedLinks, linkID)
+	}
+	decision, decided := data.tally()
+	data.Decided = decided
+	b.requestData[requestID] = data
 	b.pendingMu.Unlock()
 
-	b.log(fmt.Sprintf("✅ User Response: %s", response))
+	b.store.Update(requestID, func(r *StoredRequest) {
+		r.Votes, r.UsedLinks = data.Votes, data.UsedLinks
+		if decided {
+			r.Status, r.Answer, r.Via = "answered", decision, via
+		}
+	})
+	b.audit("response", requestID, data, vote, "")
+
+	if !decided {
+		b.log(fmt.Sprintf("🗳️ %s voted %q on %s (%d matching answers needed)", approver, answer, requestID, data.Needed))
+		return false, nil
+	}
 
-	return mcp.NewToolResultText(response), nil
+	ch <- decision // Buffered; only the deciding answer is sent
+	answer = decision
+	outcome := "answered"
+	if decision == "" {
+		outcome = "no_consensus"
+	}
+	vote.Answer = decision
+	b.audit("decision", requestID, data, vote, outcome)
+
+	refs := b.takeChannelRefs(requestID)
+	go b.closeOtherChannels(requestID, data, answer, via, refs, message)
+	return true, nil
 }
 
 // getTunnelFilePath returns the path to tunnel-url.txt, checking both dev and production locations
@@ -281,80 +481,86 @@ func (b *BridgeService) getPublicURL() string {
 	return "" // Failed to find URL
 }
 
-// sendNotification sends the question to configured channels
-func (b *BridgeService) sendNotification(question string, options []string, requestID string) {
-	switch b.cfg.Channel {
-	case "telegram":
-		b.sendTelegram(question, options, requestID)
-	case "whatsapp":
-		b.sendWhatsApp(question, options, requestID)
-	default:
-		b.log(fmt.Sprintf("⚠️ Channel '%s' not implemented yet", b.cfg.Channel))
+// clientIP returns the address an HTTP answer came from. Behind the ngrok tunnel
+// the client is the last X-Forwarded-For hop, which ngrok appends itself.
+func (b *BridgeService) clientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" && b.tunnel != nil {
+		hops := strings.Split(forwarded, ",")
+		return strings.TrimSpace(hops[len(hops)-1])
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
 	}
+	return host
 }
 
-// sendTelegram sends notification via Telegram
-func (b *BridgeService) sendTelegram(question string, options []string, requestID string) {
-	if b.cfg.Telegram.BotToken == "" || b.cfg.Telegram.ChatID == "" {
-		b.log("⚠️ Telegram not configured")
-		return
+// buildResponseURL returns the signed /respond link for a request, optionally
+// pre-filled with an answer. The link stops working at expires.
+func buildResponseURL(publicURL, requestID, answer string, expires time.Time) string {
+	params := url.Values{}
+	params.Set("t", linkSigner().Sign(links.Claims{RequestID: requestID, Answer: answer, Expires: expires.Unix()}))
+	return fmt.Sprintf("%s/respond?%s", publicURL, params.Encode())
+}
+
+// sendTelegram sends notification via Telegram with one callback button per option
+func (b *BridgeService) sendTelegram(cfg BridgeConfig, question string, options []string, requestID string) error {
+	if cfg.Telegram.BotToken == "" || cfg.Telegram.ChatID == "" {
+		return fmt.Errorf("Telegram not configured")
 	}
 
 	// [FIX] Get URL from memory OR file
 	publicURL := b.getPublicURL()
-	if publicURL == "" {
-		b.log("❌ Error: Could not find Bridge Public URL. Is the UI running?")
-		return
-	}
 
 	b.log("📤 Sending Telegram notification...")
 
-	bot, err := tgbotapi.NewBotAPI(b.cfg.Telegram.BotToken)
+	bot, err := b.telegramBot()
 	if err != nil {
-		b.log(fmt.Sprintf("❌ Telegram Error: %v", err))
-		return
+		return err
 	}
 
 	var chatID int64
-	fmt.Sscanf(b.cfg.Telegram.ChatID, "%d", &chatID)
+	fmt.Sscanf(cfg.Telegram.ChatID, "%d", &chatID)
 
-	responseURL := fmt.Sprintf("%s/respond?id=%s", publicURL, requestID)
-
-	// Use exact template user provided with double quotes for attributes
 	msgText := fmt.Sprintf(
 		"<b>🤖 Input Needed</b>\n\n"+
 			"%s\n\n"+
-			"I've hit a decision point and need your guidance to continue.\n\n"+
-			"<a href=\"%s\">📲 Launch Interface</a>\n\n"+
-			"Link: %s",
-		question,
-		responseURL,
-		responseURL,
+			"I've hit a decision point and need your guidance to continue. "+
+			"Tap an option or reply to this message with your own answer.",
+		html.EscapeString(question),
 	)
 
+	// Buttons answer in-chat; the web form is only linked when publicly reachable
+	rows := telegramOptionButtons(options, requestID)
+	if strings.HasPrefix(publicURL, "https://") {
+		responseURL := buildResponseURL(publicURL, requestID, "", b.requestDeadline(requestID))
+		msgText += fmt.Sprintf("\n\n<a href=\"%s\">📲 Launch Interface</a>", responseURL)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL("✏️ Custom answer", responseURL),
+		))
+	}
+
 	msg := tgbotapi.NewMessage(chatID, msgText)
 	msg.ParseMode = "HTML"
+	if len(rows) > 0 {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	}
 
-	// Add inline keyboard button
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonURL("Tap to Respond", responseURL),
-		),
-	)
-	msg.ReplyMarkup = keyboard
-
-	if _, err := bot.Send(msg); err != nil {
-		b.log(fmt.Sprintf("❌ Telegram Send Error: %v", err))
-	} else {
-		b.log("✅ Telegram notification sent!")
+	sent, err := bot.Send(msg)
+	if err != nil {
+		return err
 	}
+
+	b.pendingMu.Lock()
+	b.tgMessages[requestID] = append(b.tgMessages[requestID], telegramMessageRef{ChatID: chatID, MessageID: sent.MessageID})
+	b.pendingMu.Unlock()
+	return nil
 }
 
 // sendWhatsApp sends notification via CallMeBot
-func (b *BridgeService) sendWhatsApp(question string, options []string, requestID string) {
-	if b.cfg.WhatsApp.APIKey == "" || b.cfg.WhatsApp.Phone == "" {
-		b.log("⚠️ WhatsApp not configured")
-		return
+func (b *BridgeService) sendWhatsApp(cfg BridgeConfig, question string, options []string, requestID string) error {
+	if cfg.WhatsApp.APIKey == "" || cfg.WhatsApp.Phone == "" {
+		return fmt.Errorf("WhatsApp not configured")
 	}
 
 	b.log("📤 Sending WhatsApp notification...")
@@ -362,259 +568,282 @@ func (b *BridgeService) sendWhatsApp(question string, options []string, requestI
 	// Build message with response links
 	message := fmt.Sprintf("🤖 AI Agent Question:\n\n%s\n\n", question)
 	for _, opt := range options {
-		message += fmt.Sprintf("➡️ %s/respond?id=%s&answer=%s\n", b.publicURL, requestID, opt)
+		message += fmt.Sprintf("➡️ %s\n", buildResponseURL(b.publicURL, requestID, opt, b.requestDeadline(requestID)))
 	}
 
 	// CallMeBot API
 	url := fmt.Sprintf("https://api.callmebot.com/whatsapp.php?phone=%s&text=%s&apikey=%s",
-		b.cfg.WhatsApp.Phone, message, b.cfg.WhatsApp.APIKey)
+		cfg.WhatsApp.Phone, message, cfg.WhatsApp.APIKey)
 
 	resp, err := http.Get(url)
 	if err != nil {
-		b.log(fmt.Sprintf("❌ WhatsApp Error: %v", err))
-		return
+		return err
 	}
 	resp.Body.Close()
-	b.log("✅ WhatsApp notification sent!")
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("CallMeBot API returned status %d", resp.StatusCode)
+	}
+	return nil
 }
 
 // runHTTPServer handles response callbacks
-func (b *BridgeService) runHTTPServer(ctx context.Context, tunnel ngrok.Tunnel) {
+func (b *BridgeService) runHTTPServer(ctx context.Context, tunnel net.Listener) {
 	mux := http.NewServeMux()
 
 	// Save tunnel URL to file for MCP adapter
 	tunnelPath := getTunnelFilePath()
 	ioutil.WriteFile(tunnelPath, []byte(b.publicURL), 0644)
 
-	mux.HandleFunc("/respond", func(w http.ResponseWriter, r *http.Request) {
-		requestID := r.URL.Query().Get("id")
-		answer := r.URL.Query().Get("answer")
+	// Requests this process saves or picks up - those pending when the bridge
+	// last stopped - are answered from the desktop app through this loopback URL
+	desktopURL := ""
+	if desktop, err := b.serveDesktopAnswers(); err != nil {
+		b.log(fmt.Sprintf("⚠️ Desktop answers for this bridge's requests unavailable: %v", err))
+	} else {
+		defer desktop.Close()
+		desktopURL = "http://" + desktop.Addr().String()
+	}
+	b.openStore().SetURL(desktopURL)
+	b.restoreRequests()
 
-		b.pendingMu.Lock()
-		ch, exists := b.pendingRequests[requestID]
-		data := b.requestData[requestID]
-		b.pendingMu.Unlock()
+	mux.HandleFunc("/respond", b.handleRespond)
 
-		if !exists {
-			http.Error(w, "Request not found or expired", 404)
-			return
-		}
+	// New /ask endpoint for MCP adapter
+	mux.HandleFunc("/ask", b.handleAsk)
 
-		// If no answer provided, show the interactive form
-		if answer == "" && r.Method == "GET" {
-			w.Header().Set("Content-Type", "text/html; charset=utf-8")
-			optionsHTML := ""
-			for _, opt := range data.Options {
-				optionsHTML += fmt.Sprintf(`<button class="option-btn" onclick="submitAnswer('%s')">%s</button>`, opt, opt)
-			}
+	// request_permission through the MCP adapter; authenticated like /ask
+	mux.HandleFunc("/permission", b.handlePermission)
 
-			fmt.Fprintf(w, `<!DOCTYPE html>
-<html>
-<head>
-	<meta charset="UTF-8">
-	<meta name="viewport" content="width=device-width, initial-scale=1.0">
-	<title>Remote Bridge - Respond</title>
-	<style>
-		body {
-			font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
-			background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%);
-			min-height: 100vh;
-			display: flex;
-			align-items: center;
-			justify-content: center;
-			margin: 0;
-			padding: 20px;
-		}
-		.container {
-			background: white;
-			border-radius: 20px;
-			box-shadow: 0 20px 60px rgba(0,0,0,0.3);
-			max-width: 500px;
-			width: 100%%;
-			padding: 40px;
-		}
-		h1 {
-			color: #333;
-			margin: 0 0 10px 0;
-			font-size: 24px;
-		}
-		.subtitle {
-			color: #666;
-			margin: 0 0 30px 0;
-			font-size: 14px;
-		}
-		.question {
-			background: #f8f9fa;
-			padding: 20px;
-			border-radius: 10px;
-			margin-bottom: 30px;
-			color: #333;
-			font-size: 16px;
-			line-height: 1.5;
-		}
-		.options {
-			display: flex;
-			flex-direction: column;
-			gap: 10px;
-			margin-bottom: 20px;
-		}
-		.option-btn {
-			background: #667eea;
-			color: white;
-			border: none;
-			padding: 15px 20px;
-			border-radius: 10px;
-			font-size: 16px;
-			cursor: pointer;
-			transition: all 0.3s;
-		}
-		.option-btn:hover {
-			background: #5568d3;
-			transform: translateY(-2px);
-			box-shadow: 0 5px 15px rgba(102, 126, 234, 0.4);
-		}
-		.divider {
-			text-align: center;
-			margin: 20px 0;
-			color: #999;
-			font-size: 14px;
-		}
-		.custom-input {
-			width: 100%%;
-			padding: 15px;
-			border: 2px solid #e0e0e0;
-			border-radius: 10px;
-			font-size: 16px;
-			box-sizing: border-box;
-			margin-bottom: 10px;
-		}
-		.custom-input:focus {
-			outline: none;
-			border-color: #667eea;
-		}
-		.submit-btn {
-			width: 100%%;
-			background: #764ba2;
-			color: white;
-			border: none;
-			padding: 15px;
-			border-radius: 10px;
-			font-size: 16px;
-			cursor: pointer;
-			font-weight: 600;
-		}
-		.submit-btn:hover {
-			background: #653a8a;
-		}
-	</style>
-</head>
-<body>
-	<div class="container">
-		<h1>🤖 Agent Question</h1>
-		<p class="subtitle">Please provide your response</p>
-		<div class="question">%s</div>
-		<div class="options">%s</div>
-		<div class="divider">OR</div>
-		<input type="text" id="customAnswer" class="custom-input" placeholder="Type your custom answer...">
-		<button class="submit-btn" onclick="submitCustom()">Send Custom Answer</button>
-	</div>
-	<script>
-		function submitAnswer(answer) {
-			window.location.href = '/respond?id=%s&answer=' + encodeURIComponent(answer);
-		}
-		function submitCustom() {
-			const custom = document.getElementById('customAnswer').value;
-			if (custom.trim()) {
-				window.location.href = '/respond?id=%s&answer=' + encodeURIComponent(custom);
-			} else {
-				alert('Please enter an answer');
-			}
-		}
-	</script>
-</body>
-</html>`, data.Question, optionsHTML, requestID, requestID)
-			return
-		}
+	// Inbound Twilio webhook for SMS replies
+	mux.HandleFunc("/sms/inbound", b.handleSMSInbound)
 
-		// Answer provided - process it
-		if answer != "" {
-			b.log(fmt.Sprintf("📥 Response received: %s -> %s", requestID, answer))
-			ch <- answer
-			w.Header().Set("Content-Type", "text/html; charset=utf-8")
-			fmt.Fprintf(w, `<!DOCTYPE html>
-<html>
-<head>
-	<meta charset="UTF-8">
-	<style>
-		body { font-family: sans-serif; background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); min-height: 100vh; display: flex; align-items: center; justify-content: center; margin: 0; }
-		.container { background: white; border-radius: 20px; padding: 40px; text-align: center; box-shadow: 0 20px 60px rgba(0,0,0,0.3); }
-		h1 { color: #4CAF50; margin: 0 0 20px 0; }
-		p { color: #666; margin: 10px 0; }
-		.answer { background: #f8f9fa; padding: 15px; border-radius: 10px; margin: 20px 0; color: #333; font-weight: 600; }
-	</style>
-</head>
-<body>
-	<div class="container">
-		<h1>✅ Response Sent!</h1>
-		<p>You answered:</p>
-		<div class="answer">%s</div>
-		<p>You can close this window.</p>
-	</div>
-</body>
-</html>`, answer)
-		}
+	// Slack Block Kit button clicks
+	mux.HandleFunc("/slack/interactions", b.handleSlackInteraction)
+
+	// Signed answers from the generic webhook receiver
+	mux.HandleFunc("/webhook/answer", b.handleWebhookAnswer)
+
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 	})
 
-	// New /ask endpoint for MCP adapter
-	mux.HandleFunc("/ask", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != "POST" {
-			http.Error(w, "POST required", 405)
-			return
-		}
+	b.log("🌐 HTTP Server listening on tunnel...")
+	http.Serve(tunnel, mux)
+}
 
-		var req struct {
-			Question string   `json:"question"`
-			Options  []string `json:"options"`
-		}
-		
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid JSON", 400)
-			return
-		}
+// handleAsk takes ask_human calls from the MCP adapter and replies with the answer
+func (b *BridgeService) handleAsk(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "POST required", 405)
+		return
+	}
+	// The tunnel makes /ask public; only the MCP adapter holding the token may ask
+	if !b.askAuthorized(r) {
+		b.log(fmt.Sprintf("🚫 Rejected unauthenticated /ask from %s", b.clientIP(r)))
+		http.Error(w, "Unauthorized", 401)
+		return
+	}
 
-		b.log(fmt.Sprintf("🔔 HTTP Request: %s", req.Question))
+	var req struct {
+		Question       string   `json:"question"`
+		Options        []string `json:"options"`
+		Category       string   `json:"category"`
+		Workspace      string   `json:"workspace"`
+		TimeoutSeconds int      `json:"timeout_seconds"`
+		DefaultOption  string   `json:"default_option"`
+		RiskLevel      string   `json:"risk_level"`
+		responseui.Context
+		AnswerSchema responseui.Schema `json:"answer_schema"`
+		RequestID    string            `json:"request_id"`
+	}
 
-		// Generate request ID
-		requestID := uuid.New().String()[:8]
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", 400)
+		return
+	}
 
-		// Create response channel
-		responseChan := make(chan string, 1)
-		b.pendingMu.Lock()
-		b.pendingRequests[requestID] = responseChan
-	b.requestData[requestID] = RequestData{Question: req.Question, Options: req.Options}
-		b.pendingMu.Unlock()
+	b.log(fmt.Sprintf("🔔 HTTP Request: %s", req.Question))
+
+	// Wait for response (until the timeout or the adapter disconnects)
+	result, err := b.ask(r.Context(), AskRequest{
+		Question:      req.Question,
+		Options:       req.Options,
+		Category:      req.Category,
+		Workspace:     req.Workspace,
+		Timeout:       time.Duration(req.TimeoutSeconds) * time.Second,
+		DefaultOption: req.DefaultOption,
+		Risk:          req.RiskLevel,
+		Context:       req.Context,
+		Schema:        req.AnswerSchema.WithOptions(req.Options),
+		RequestID:     req.RequestID,
+	})
+	if err != nil {
+		if r.Context().Err() == nil {
+			http.Error(w, err.Error(), 400)
+		}
+		return
+	}
 
-		// Send notification
-		b.sendNotification(req.Question, req.Options, requestID)
+	b.log(fmt.Sprintf("✅ Response (%s): %s", result.Source, result.Answer))
 
-		// Wait for response
-		answer := <-responseChan
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		AskResult
+		Text string `json:"text"`
+	}{result, result.Text()})
+}
 
-		// Cleanup
-		b.pendingMu.Lock()
-		delete(b.pendingRequests, requestID)
-		b.pendingMu.Unlock()
+// handlePermission takes request_permission calls from the MCP adapter
+func (b *BridgeService) handlePermission(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "POST required", 405)
+		return
+	}
+	if !b.askAuthorized(r) {
+		b.log(fmt.Sprintf("🚫 Rejected unauthenticated /permission from %s", b.clientIP(r)))
+		http.Error(w, "Unauthorized", 401)
+		return
+	}
 
-		b.log(fmt.Sprintf("✅ Response: %s", answer))
+	var req struct {
+		Action         string   `json:"action"`
+		Command        string   `json:"command"`
+		Paths          []string `json:"paths"`
+		RiskLevel      string   `json:"risk_level"`
+		Justification  string   `json:"justification"`
+		Category       string   `json:"category"`
+		Workspace      string   `json:"workspace"`
+		TimeoutSeconds int      `json:"timeout_seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", 400)
+		return
+	}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{"answer": answer})
+	b.log(fmt.Sprintf("🛡️ HTTP Permission request: %s", req.Action))
+
+	result, err := b.requestPermission(r.Context(), PermissionRequest{
+		Action:        req.Action,
+		Command:       req.Command,
+		Paths:         req.Paths,
+		Risk:          req.RiskLevel,
+		Justification: req.Justification,
+		Category:      req.Category,
+		Workspace:     req.Workspace,
+		Timeout:       time.Duration(req.TimeoutSeconds) * time.Second,
 	})
+	if err != nil {
+		if r.Context().Err() == nil {
+			http.Error(w, err.Error(), 400)
+		}
+		return
+	}
 
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
-	})
+	b.log(fmt.Sprintf("✅ Permission %s: approved=%t", result.RequestID, result.Approved))
 
-	b.log("🌐 HTTP Server listening on tunnel...")
-	http.Serve(tunnel, mux)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		PermissionResult
+		Text string `json:"text"`
+	}{result, result.Text()})
+}
+
+// handleRespond serves response links: the form, or the answer an option link or the form carries
+func (b *BridgeService) handleRespond(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("t")
+	claims, err := linkSigner().Verify(token, time.Now())
+	if err == links.ErrExpired {
+		b.pages().Expired(w, "link_expired")
+		return
+	}
+	if err != nil {
+		b.pages().Error(w, 403, "link_invalid")
+		return
+	}
+	requestID := claims.RequestID
+	answer := claims.Answer // Option links carry their answer; the form sends its own
+	if answer == "" {
+		answer = strings.TrimSpace(r.FormValue("answer"))
+	}
+	if answer == "" {
+		answer = strings.TrimSpace(r.FormValue("custom")) // Free text on the second-factor page
+	}
+
+	b.pendingMu.Lock()
+	_, exists := b.pendingRequests[requestID]
+	data := b.requestData[requestID]
+	b.pendingMu.Unlock()
+
+	if !exists {
+		if stored, ok := b.store.Get(requestID); ok {
+			switch stored.Status {
+			case "answered":
+				b.pages().Error(w, 409, "answered_via", stored.Via, stored.Answer)
+				return
+			case "expired", "pending":
+				b.pages().Expired(w, "expired", stored.Deadline.Local().Format("2006-01-02 15:04"))
+				return
+			}
+		}
+		b.pages().Error(w, 404, "not_found")
+		return
+	}
+	if len(data.Approvers) > 0 {
+		// Links can't tell approvers apart, so routed requests are answered in-channel
+		b.pages().Error(w, 403, "approvers_only")
+		return
+	}
+	if data.Schema.Structured() && claims.Answer == "" && r.Method == "POST" {
+		// Typed answers come from the schema's inputs; show the form again if they don't fit
+		var err error
+		if answer, err = data.Schema.FromForm(r.PostForm, "answer"); err != nil {
+			pages := b.pages()
+			if data.SecondFactor {
+				pages.SecondFactor(w, 400, token, data.page(), "", pages.InvalidAnswer(err))
+			} else {
+				pages.Question(w, 400, token, data.page(), pages.InvalidAnswer(err))
+			}
+			return
+		}
+	}
+
+	if claims.Answer != "" && r.Method != "POST" && !data.SecondFactor {
+		// Option links are opened by link previews and mail scanners too, so
+		// they only answer from the confirm page's POST
+		b.pages().Confirm(w, token, data.page(), answer)
+		return
+	}
+
+	vote := Vote{Answer: answer, Via: "web", ClientIP: b.clientIP(r)}
+	if data.SecondFactor && !b.checkSecondFactor(w, r, token, requestID, data, &vote) {
+		return
+	}
+
+	// If no answer provided, show the interactive form
+	if answer == "" {
+		b.pages().Question(w, 200, token, data.page(), "")
+		return
+	}
+
+	// Answer provided - process it
+	if answer != "" {
+		b.log(fmt.Sprintf("📥 Response received: %s -> %s", requestID, answer))
+		switch err := b.submitLinkVote(requestID, token, vote); err {
+		case nil:
+			b.pages().Confirmation(w, data.Schema.Display(answer))
+		case errLinkUsed:
+			b.pages().Error(w, 409, "link_used")
+		case errRequestGone:
+			b.pages().Error(w, 409, "answered")
+		default:
+			// The schema refused the answer: ask again
+			pages := b.pages()
+			if data.SecondFactor {
+				pages.SecondFactor(w, 400, token, data.page(), "", pages.InvalidAnswer(err))
+			} else {
+				pages.Question(w, 400, token, data.page(), pages.InvalidAnswer(err))
+			}
+		}
+	}
 }
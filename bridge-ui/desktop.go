@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	goruntime "runtime"
+	"sort"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// PendingRequest is an open question as shown on the desktop
+type PendingRequest struct {
+	ID       string    `json:"id"`
+	Question string    `json:"question"`
+	Options  []string  `json:"options"`
+	Deadline time.Time `json:"deadline"`
+}
+
+// pendingList returns the undecided requests this process holds, soonest deadline first
+func (b *BridgeService) pendingList() []PendingRequest {
+	b.pendingMu.Lock()
+	defer b.pendingMu.Unlock()
+
+	pending := []PendingRequest{}
+	for id := range b.pendingRequests {
+		data := b.requestData[id]
+		if data.Decided {
+			continue
+		}
+		pending = append(pending, PendingRequest{ID: id, Question: data.Question, Options: data.Options, Deadline: data.Deadline})
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Deadline.Before(pending[j].Deadline) })
+	return pending
+}
+
+// watchPendingRequests keeps the tray menu in step with open questions and
+// raises a native notification for each new one
+func (a *App) watchPendingRequests() {
+	runtime.EventsOn(a.ctx, "pendingRequest", func(data ...interface{}) {
+		if len(data) > 0 {
+			if p, ok := data[0].(PendingRequest); ok {
+				if err := showDesktopNotification("🤖 Input Needed", p.Question); err != nil {
+					fmt.Println("[BRIDGE] ⚠️ Desktop notification failed:", err)
+				}
+			}
+		}
+		refreshTrayQuestions(a.bridge.pendingList())
+	})
+	runtime.EventsOn(a.ctx, "requestUpdated", func(...interface{}) {
+		refreshTrayQuestions(a.bridge.pendingList())
+	})
+}
+
+// ShowRequest brings the window forward on a request so it can be answered
+func (a *App) ShowRequest(id string) {
+	a.ShowWindow()
+	runtime.EventsEmit(a.ctx, "showRequest", id)
+}
+
+// windowsToastScript shows a toast through the WinRT API. Title and body come in
+// through the environment so they never need quoting.
+const windowsToastScript = `
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $template.GetElementsByTagName('text')
+$text.Item(0).AppendChild($template.CreateTextNode($env:MOMENTUM_TOAST_TITLE)) > $null
+$text.Item(1).AppendChild($template.CreateTextNode($env:MOMENTUM_TOAST_BODY)) > $null
+$toast = [Windows.UI.Notifications.ToastNotification]::new($template)
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe').Show($toast)
+`
+
+// showDesktopNotification raises a native OS notification
+func showDesktopNotification(title, body string) error {
+	body = truncateText(body, 200)
+
+	var cmd *exec.Cmd
+	switch goruntime.GOOS {
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", windowsToastScript)
+		cmd.Env = append(os.Environ(), "MOMENTUM_TOAST_TITLE="+title, "MOMENTUM_TOAST_BODY="+body)
+	case "darwin":
+		cmd = exec.Command("osascript",
+			"-e", "on run argv",
+			"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+			"-e", "end run",
+			title, body)
+	default:
+		cmd = exec.Command("notify-send", "--app-name=Momentum", title, body)
+	}
+	return cmd.Run()
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"html"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSMTPHost = "smtp.gmail.com"
+	defaultSMTPPort = 587
+	smtpTimeout     = 15 * time.Second
+)
+
+// sendEmail sends notification via SMTP (Gmail app password by default)
+func (b *BridgeService) sendEmail(cfg BridgeConfig, question string, options []string, requestID string) error {
+	if cfg.Gmail.Email == "" || cfg.Gmail.AppPassword == "" {
+		return fmt.Errorf("Gmail not configured")
+	}
+
+	publicURL := b.getPublicURL()
+	if publicURL == "" {
+		return fmt.Errorf("Could not find Bridge Public URL. Is the UI running?")
+	}
+
+	b.log("📤 Sending email notification...")
+
+	to := cfg.Gmail.To
+	if to == "" {
+		to = cfg.Gmail.Email
+	}
+
+	msg, err := buildEmailMessage(cfg.Gmail.Email, to, question, options, publicURL, requestID, b.requestDeadline(requestID))
+	if err != nil {
+		return err
+	}
+
+	return sendSMTP(cfg.Gmail, to, msg)
+}
+
+// buildEmailMessage renders a multipart/alternative message with one response link per option;
+// the links stop working at expires
+func buildEmailMessage(from, to, question string, options []string, publicURL, requestID string, expires time.Time) ([]byte, error) {
+	formURL := buildResponseURL(publicURL, requestID, "", expires)
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "🤖 Input Needed\r\n\r\n%s\r\n\r\n", question)
+	for _, opt := range options {
+		fmt.Fprintf(&text, "➡️ %s: %s\r\n", opt, buildResponseURL(publicURL, requestID, opt, expires))
+	}
+	fmt.Fprintf(&text, "\r\nOr open the interface: %s\r\n", formURL)
+
+	var htmlBody strings.Builder
+	fmt.Fprintf(&htmlBody, `<div style="font-family:sans-serif;max-width:500px">`+
+		`<h2>🤖 Input Needed</h2><p style="background:#f8f9fa;padding:16px;border-radius:8px">%s</p>`,
+		strings.ReplaceAll(html.EscapeString(question), "\n", "<br>"))
+	for _, opt := range options {
+		fmt.Fprintf(&htmlBody, `<p><a href="%s" style="display:inline-block;background:#667eea;color:#fff;`+
+			`padding:12px 20px;border-radius:8px;text-decoration:none">%s</a></p>`,
+			html.EscapeString(buildResponseURL(publicURL, requestID, opt, expires)), html.EscapeString(opt))
+	}
+	fmt.Fprintf(&htmlBody, `<p><a href="%s">📲 Launch Interface</a></p></div>`, html.EscapeString(formURL))
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", text.String()},
+		{"text/html; charset=UTF-8", htmlBody.String()},
+	} {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		w, err := mw.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		qp.Close()
+	}
+	mw.Close()
+
+	subject := question
+	if runes := []rune(subject); len(runes) > 60 {
+		subject = string(runes[:57]) + "..."
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: Momentum <%s>\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mimeHeader("🤖 Agent needs input: "+subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+// mimeHeader encodes a header value so non-ASCII text (emoji) survives transport
+func mimeHeader(s string) string {
+	s = strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+	return mime.QEncoding.Encode("UTF-8", s)
+}
+
+// sendSMTP delivers a message, using implicit TLS on port 465 and STARTTLS otherwise
+func sendSMTP(cfg GmailConfig, to string, msg []byte) error {
+	host := cfg.SMTPHost
+	if host == "" {
+		host = defaultSMTPHost
+	}
+	port := cfg.SMTPPort
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+	addr := net.JoinHostPort(host, fmt.Sprintf("%d", port))
+	tlsConfig := &tls.Config{ServerName: host}
+
+	var conn net.Conn
+	var err error
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+	if port == 465 {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("connect %s: %w", addr, err)
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp handshake: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && port != 465 {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+
+	// Credentials are always configured, so a server that won't take them is
+	// refused rather than sent to unauthenticated
+	if ok, _ := client.Extension("AUTH"); !ok {
+		return fmt.Errorf("auth: %s does not offer AUTH", addr)
+	}
+	// App passwords are shown with spaces in Google's UI
+	password := strings.ReplaceAll(cfg.AppPassword, " ", "")
+	if err := client.Auth(smtp.PlainAuth("", cfg.Email, password, host)); err != nil {
+		return fmt.Errorf("auth: %w", err)
+	}
+
+	if err := client.Mail(cfg.Email); err != nil {
+		return fmt.Errorf("mail from: %w", err)
+	}
+	for _, rcpt := range strings.Split(to, ",") {
+		if rcpt = strings.TrimSpace(rcpt); rcpt == "" {
+			continue
+		}
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("rcpt %s: %w", rcpt, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("data: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("data close: %w", err)
+	}
+
+	return client.Quit()
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTP is a local SMTP stand-in that takes one message
+type fakeSMTP struct {
+	addr     string
+	auth     bool   // Advertise AUTH PLAIN
+	password string // Accepted password; anything else fails auth
+	commands []string
+	data     string
+	done     chan struct{}
+}
+
+func startFakeSMTP(t *testing.T, auth bool, password string) *fakeSMTP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	f := &fakeSMTP{addr: ln.Addr().String(), auth: auth, password: password, done: make(chan struct{})}
+	go func() {
+		defer close(f.done)
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		f.serve(conn)
+	}()
+	return f
+}
+
+func (f *fakeSMTP) serve(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+	reply("220 fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		f.commands = append(f.commands, line)
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			if f.auth {
+				reply("250-fake")
+				reply("250 AUTH PLAIN")
+			} else {
+				reply("250 fake")
+			}
+		case "AUTH":
+			fields := strings.Fields(line)
+			creds, _ := base64.StdEncoding.DecodeString(fields[len(fields)-1])
+			if parts := strings.Split(string(creds), "\x00"); len(parts) == 3 && parts[2] == f.password {
+				reply("235 ok")
+			} else {
+				reply("535 bad credentials")
+			}
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil || l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			f.data = data.String()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func (f *fakeSMTP) config(password string) GmailConfig {
+	host, port, _ := net.SplitHostPort(f.addr)
+	n, _ := strconv.Atoi(port)
+	return GmailConfig{Email: "bot@example.com", AppPassword: password, SMTPHost: host, SMTPPort: n}
+}
+
+func TestSendSMTP(t *testing.T) {
+	tests := []struct {
+		name     string
+		auth     bool
+		password string
+		wantErr  string
+	}{
+		{"delivers after auth", true, "abcd efgh", ""},
+		{"wrong password", true, "nope", "auth"},
+		{"server without AUTH", false, "abcd efgh", "does not offer AUTH"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := startFakeSMTP(t, tt.auth, "abcdefgh")
+			msg := []byte("Subject: hi\r\n\r\nbody\r\n")
+			err := sendSMTP(f.config(tt.password), "a@example.com, b@example.com", msg)
+			<-f.done
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("sendSMTP = %v, want an error containing %q", err, tt.wantErr)
+				}
+				if f.data != "" {
+					t.Errorf("a message was sent: %q", f.data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("sendSMTP: %v", err)
+			}
+			commands := strings.Join(f.commands, "\n")
+			for _, want := range []string{"AUTH PLAIN", "MAIL FROM:<bot@example.com>", "RCPT TO:<a@example.com>", "RCPT TO:<b@example.com>"} {
+				if !strings.Contains(commands, want) {
+					t.Errorf("missing %q in:\n%s", want, commands)
+				}
+			}
+			if !strings.Contains(f.data, "body") {
+				t.Errorf("data = %q", f.data)
+			}
+		})
+	}
+}
+
+func TestBuildEmailMessage(t *testing.T) {
+	question := `Deploy <script>alert(1)</script> to prod? ` + strings.Repeat("x", 80)
+	msg, err := buildEmailMessage("bot@example.com", "me@example.com", question, []string{"Yes", `"No"`}, "https://bridge.example", "req1", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := mail.ReadMessage(strings.NewReader(string(msg)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+	if err != nil || !strings.HasPrefix(subject, "🤖 Agent needs input: Deploy") || !strings.HasSuffix(subject, "...") {
+		t.Errorf("subject = %q, %v", subject, err)
+	}
+
+	_, params, _ := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	parts := map[string]string{}
+	mr := multipart.NewReader(m.Body, params["boundary"])
+	for {
+		p, err := mr.NextRawPart()
+		if err != nil {
+			break
+		}
+		body, _ := io.ReadAll(quotedprintable.NewReader(p))
+		parts[strings.Split(p.Header.Get("Content-Type"), ";")[0]] = string(body)
+	}
+	text, htmlBody := parts["text/plain"], parts["text/html"]
+	if strings.Count(text, "https://bridge.example/respond?t=") != 3 {
+		t.Errorf("plain text should have a link per option and the form:\n%s", text)
+	}
+	if strings.Contains(htmlBody, "<script>") || !strings.Contains(htmlBody, "&lt;script&gt;") || !strings.Contains(htmlBody, "&#34;No&#34;") {
+		t.Errorf("html part is not escaped:\n%s", htmlBody)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// startEscalation re-sends a request along cfg.Escalation until the returned stop func is called.
+// Every step reuses the same request ID, so an answer on any channel resolves it.
+func (b *BridgeService) startEscalation(requestID, question string, options []string) (stop func()) {
+	cfg := b.config()
+	steps := append([]EscalationStep(nil), cfg.Escalation...)
+	if len(steps) == 0 {
+		return func() {}
+	}
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].AfterMinutes < steps[j].AfterMinutes })
+
+	done := make(chan struct{})
+	go func() {
+		start := time.Now()
+		for i, step := range steps {
+			timer := time.NewTimer(time.Until(start.Add(time.Duration(step.AfterMinutes) * time.Minute)))
+			select {
+			case <-done:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			b.pendingMu.Lock()
+			_, pending := b.pendingRequests[requestID]
+			b.pendingMu.Unlock()
+			if !pending {
+				return
+			}
+
+			recipient := "usual recipient"
+			if step.Contact != nil && step.Contact.Name != "" {
+				recipient = step.Contact.Name
+			}
+			b.log(fmt.Sprintf("⏫ Escalating %s (step %d, %d min): %s → %s",
+				requestID, i+1, step.AfterMinutes, strings.Join(step.Channels, ", "), recipient))
+			b.fanOut(cfg.withContact(step.Contact), step.Channels, question, options, requestID)
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// withContact returns a copy of the config that delivers to contact instead of the usual recipients
+func (c BridgeConfig) withContact(contact *Contact) BridgeConfig {
+	if contact == nil {
+		return c
+	}
+	if contact.TelegramChatID != "" {
+		c.Telegram.ChatID = contact.TelegramChatID
+	}
+	if contact.Phone != "" {
+		c.SMS.To = contact.Phone
+	}
+	if contact.Email != "" {
+		c.Gmail.To = contact.Email
+	}
+	if contact.SlackChannel != "" {
+		c.Slack.Channel = contact.SlackChannel
+	}
+	return c
+}
+
+// contacts returns the approvers and the backup approvers named in the escalation policy
+func (c BridgeConfig) contacts() []Contact {
+	var contacts []Contact
+	for _, approver := range c.Approvers {
+		contacts = append(contacts, approver.Contact)
+	}
+	for _, step := range c.Escalation {
+		if step.Contact != nil {
+			contacts = append(contacts, *step.Contact)
+		}
+	}
+	return contacts
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui/requestlog"
+)
+
+// ChannelDelivery records whether a notification reached one channel
+type ChannelDelivery = requestlog.Delivery
+
+// channelRefs are the sent messages that can be updated once a request is answered
+type channelRefs struct {
+	telegram       []telegramMessageRef
+	slack          []slackMessageRef
+	matrixEventIDs []string // One per send, e.g. the first notification and each escalation
+}
+
+// Message keys name the notification an answer was given on, which the
+// channel's handler closes itself
+func (r telegramMessageRef) key() string { return fmt.Sprintf("telegram:%d:%d", r.ChatID, r.MessageID) }
+func (r slackMessageRef) key() string    { return "slack:" + r.Channel + ":" + r.TS }
+func matrixMessageKey(eventID string) string {
+	return "matrix:" + eventID
+}
+
+// sendNotification fans the question out to every enabled channel in parallel
+func (b *BridgeService) sendNotification(question string, options []string, requestID string) {
+	cfg := b.config()
+	channels := cfg.enabledChannels()
+	if len(channels) == 0 {
+		b.log("⚠️ No notification channel enabled")
+		return
+	}
+	b.fanOut(cfg, channels, question, options, requestID)
+}
+
+// fanOut sends to the given channels in parallel using cfg for credentials and recipients
+func (b *BridgeService) fanOut(cfg BridgeConfig, channels []string, question string, options []string, requestID string) {
+	var wg sync.WaitGroup
+	for _, channel := range channels {
+		wg.Add(1)
+		go func(channel string) {
+			defer wg.Done()
+			b.recordDelivery(requestID, channel, b.sendChannel(cfg, channel, question, options, requestID))
+		}(channel)
+	}
+	wg.Wait()
+}
+
+// sendChannel sends the question through a single channel
+func (b *BridgeService) sendChannel(cfg BridgeConfig, channel, question string, options []string, requestID string) error {
+	switch channel {
+	case "telegram":
+		return b.sendTelegram(cfg, question, options, requestID)
+	case "whatsapp":
+		return b.sendWhatsApp(cfg, question, options, requestID)
+	case "gmail":
+		return b.sendEmail(cfg, question, options, requestID)
+	case "sms":
+		return b.sendSMS(cfg, question, options, requestID)
+	case "slack":
+		return b.sendSlack(cfg, question, options, requestID)
+	case "webhook":
+		return b.sendWebhook(cfg, question, options, requestID)
+	case "matrix":
+		return b.sendMatrix(cfg, question, options, requestID)
+	default:
+		return fmt.Errorf("channel '%s' not implemented yet", channel)
+	}
+}
+
+// recordDelivery logs a channel's outcome and attaches it to the pending request
+func (b *BridgeService) recordDelivery(requestID, channel string, err error) {
+	delivery := ChannelDelivery{Channel: channel, OK: err == nil, At: time.Now()}
+	if err != nil {
+		delivery.Error = err.Error()
+		b.log(fmt.Sprintf("❌ %s delivery failed: %v", channel, err))
+	} else {
+		b.log(fmt.Sprintf("✅ %s notification sent!", channel))
+	}
+
+	b.pendingMu.Lock()
+	if data, exists := b.requestData[requestID]; exists {
+		data.Deliveries = append(data.Deliveries, delivery)
+		b.requestData[requestID] = data
+	}
+	b.pendingMu.Unlock()
+	b.store.Update(requestID, func(r *StoredRequest) { r.Deliveries = append(r.Deliveries, delivery) })
+
+	b.emit("delivery", map[string]interface{}{"requestId": requestID, "delivery": delivery})
+}
+
+// delivered reports whether the notification reached a channel
+func (d RequestData) delivered(channel string) bool {
+	for _, delivery := range d.Deliveries {
+		if delivery.Channel == channel && delivery.OK {
+			return true
+		}
+	}
+	return false
+}
+
+// takeChannelRefs removes and returns the messages sent for a request
+func (b *BridgeService) takeChannelRefs(requestID string) channelRefs {
+	b.pendingMu.Lock()
+	defer b.pendingMu.Unlock()
+
+	var refs channelRefs
+	refs.telegram = b.tgMessages[requestID]
+	delete(b.tgMessages, requestID)
+	refs.slack = b.slackMessages[requestID]
+	delete(b.slackMessages, requestID)
+	for eventID, id := range b.mxEvents {
+		if id == requestID {
+			refs.matrixEventIDs = append(refs.matrixEventIDs, eventID)
+			delete(b.mxEvents, eventID)
+		}
+	}
+	return refs
+}
+
+// closeOtherChannels marks a request as answered on every message sent for it
+// except answered, the one the deciding answer was given on. Email, SMS and
+// WhatsApp messages can't be edited, so they are left alone.
+func (b *BridgeService) closeOtherChannels(requestID string, data RequestData, answer, via string, refs channelRefs, answered string) {
+	b.log(fmt.Sprintf("🏁 %s answered via %s", requestID, via))
+	outcome := fmt.Sprintf("✅ %s (answered via %s at %s)", data.Schema.Display(answer), via, time.Now().Format("15:04"))
+	switch {
+	case via == "timeout" && answer == "":
+		outcome = fmt.Sprintf("⌛ Expired unanswered at %s", time.Now().Format("15:04"))
+	case via == "timeout":
+		outcome = fmt.Sprintf("⌛ No answer in time, used default: %s", answer)
+	case answer == "":
+		outcome = fmt.Sprintf("⚠️ No consensus among approvers (%s)", time.Now().Format("15:04"))
+	}
+
+	for _, ref := range refs.telegram {
+		if ref.key() != answered {
+			b.closeTelegramMessage(ref, data.Question, outcome)
+		}
+	}
+	for _, ref := range refs.slack {
+		if ref.key() != answered {
+			b.closeSlackMessage(ref, data.Question, outcome)
+		}
+	}
+	for _, eventID := range refs.matrixEventIDs {
+		if matrixMessageKey(eventID) != answered {
+			b.closeMatrixEvent(eventID, outcome)
+		}
+	}
+	if via != "webhook" && data.delivered("webhook") {
+		b.sendWebhookAnswered(requestID, data, answer, via)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// closeRecorder fakes the Matrix and Slack APIs and records which messages
+// were closed
+type closeRecorder struct {
+	mu     sync.Mutex
+	closed []string
+}
+
+func (c *closeRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&payload)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/_matrix/"):
+		relates, _ := payload["m.relates_to"].(map[string]interface{})
+		reply, _ := relates["m.in_reply_to"].(map[string]interface{})
+		c.closed = append(c.closed, "matrix "+reply["event_id"].(string))
+		w.Write([]byte(`{"event_id":"$notice"}`))
+	case r.URL.Path == "/api/chat.update":
+		c.closed = append(c.closed, "slack "+payload["ts"].(string))
+		w.Write([]byte(`{"ok":true}`))
+	}
+}
+
+func (c *closeRecorder) list() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sort.Strings(c.closed)
+	return strings.Join(c.closed, ",")
+}
+
+func newFanoutBridge(t *testing.T, recorder *closeRecorder) *BridgeService {
+	server := httptest.NewServer(recorder)
+	t.Cleanup(server.Close)
+
+	b := NewBridgeService()
+	b.cfg.Matrix = MatrixConfig{Homeserver: server.URL, AccessToken: "tok", RoomID: "!room:example.org"}
+	b.cfg.Slack = SlackConfig{BotToken: "xoxb", APIBaseURL: server.URL + "/api"}
+	b.pendingRequests["req1"] = make(chan string, 1)
+	b.requestData["req1"] = RequestData{Question: "Deploy?", Options: []string{"Yes", "No"}}
+	b.mxEvents["$first"] = "req1"
+	b.mxEvents["$escalated"] = "req1"
+	b.mxEvents["$other"] = "req2"
+	b.slackMessages["req1"] = []slackMessageRef{{Channel: "C1", TS: "1.1"}, {Channel: "C2", TS: "2.2"}}
+	return b
+}
+
+// TestCloseOtherChannelsEveryMessage checks that every message sent for a
+// request - the first notification and each escalation - is closed once it
+// is answered
+func TestCloseOtherChannelsEveryMessage(t *testing.T) {
+	recorder := &closeRecorder{}
+	b := newFanoutBridge(t, recorder)
+
+	refs := b.takeChannelRefs("req1")
+	if len(refs.matrixEventIDs) != 2 || len(refs.slack) != 2 {
+		t.Fatalf("refs = %+v", refs)
+	}
+	if _, kept := b.mxEvents["$other"]; !kept || len(b.mxEvents) != 1 {
+		t.Errorf("events left = %v", b.mxEvents)
+	}
+
+	b.closeOtherChannels("req1", b.requestData["req1"], "Yes", "desktop", refs, "")
+	want := "matrix $escalated,matrix $first,slack 1.1,slack 2.2"
+	if got := recorder.list(); got != want {
+		t.Errorf("closed = %v, want %v", got, want)
+	}
+}
+
+// TestCloseOtherChannelsSameChannel checks that only the message answered on
+// is skipped, not the rest of that channel's messages
+func TestCloseOtherChannelsSameChannel(t *testing.T) {
+	tests := []struct {
+		name     string
+		via      string
+		answered string
+		want     string
+	}{
+		{"slack escalation answered", "slack", slackMessageRef{Channel: "C2", TS: "2.2"}.key(), "matrix $escalated,matrix $first,slack 1.1"},
+		{"matrix first answered", "matrix", matrixMessageKey("$first"), "matrix $escalated,slack 1.1,slack 2.2"},
+	}
+
+	for _, tt := range tests {
+		recorder := &closeRecorder{}
+		b := newFanoutBridge(t, recorder)
+		b.closeOtherChannels("req1", b.requestData["req1"], "Yes", tt.via, b.takeChannelRefs("req1"), tt.answered)
+		if got := recorder.list(); got != tt.want {
+			t.Errorf("%s: closed = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestQuorumVoteKeepsMessage checks that a vote that doesn't decide the
+// request leaves every message open
+func TestQuorumVoteKeepsMessage(t *testing.T) {
+	recorder := &closeRecorder{}
+	b := newFanoutBridge(t, recorder)
+	data := b.requestData["req1"]
+	data.Approvers = []string{"alice", "bob"}
+	data.Needed = 2
+	b.requestData["req1"] = data
+
+	decided, err := b.submitMessageVote("req1", Vote{Answer: "Yes", Via: "slack", Approver: "alice"}, slackMessageRef{Channel: "C1", TS: "1.1"}.key())
+	if err != nil || decided {
+		t.Fatalf("first vote: decided = %v, err = %v", decided, err)
+	}
+	if len(b.slackMessages["req1"]) != 2 || len(b.mxEvents) != 3 {
+		t.Errorf("messages taken after a non-deciding vote: slack %v, matrix %v", b.slackMessages["req1"], b.mxEvents)
+	}
+
+	decided, err = b.submitMessageVote("req1", Vote{Answer: "Yes", Via: "slack", Approver: "bob"}, slackMessageRef{Channel: "C2", TS: "2.2"}.key())
+	if err != nil || !decided {
+		t.Fatalf("second vote: decided = %v, err = %v", decided, err)
+	}
+}
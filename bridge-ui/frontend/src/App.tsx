@@ -1,6 +1,7 @@
-import { useState } from 'react';
+import { useState, useEffect } from 'react';
 import { AnimatePresence } from 'framer-motion';
 import './App.css';
+import { EventsOn } from '../wailsjs/runtime';
 
 import WelcomeScreen from './components/WelcomeScreen';
 import SourceSelect from './components/SourceSelect';
@@ -8,9 +9,10 @@ import Settings from './components/Settings';
 import ChannelSelect from './components/ChannelSelect';
 import ConfigPage from './components/ConfigPage';
 import BridgeControl from './components/BridgeControl';
+import HistoryPage from './components/HistoryPage';
 
-type View = 'welcome' | 'source-select' | 'settings' | 'channel-select' | 'config' | 'bridge-control';
-type Channel = 'telegram' | 'whatsapp' | 'gmail' | 'sms';
+type View = 'welcome' | 'source-select' | 'settings' | 'channel-select' | 'config' | 'bridge-control' | 'history';
+type Channel = 'telegram' | 'whatsapp' | 'gmail' | 'sms' | 'slack' | 'webhook' | 'matrix';
 
 function App() {
     const [currentView, setCurrentView] = useState<View>('welcome');
@@ -18,6 +20,15 @@ function App() {
     const [selectedSource, setSelectedSource] = useState<'agent' | 'mcp'>('agent');
     const [selectedChannel, setSelectedChannel] = useState<Channel>('telegram');
     const [editingRecent, setEditingRecent] = useState(false);
+    const [focusRequest, setFocusRequest] = useState<string | undefined>();
+
+    useEffect(() => {
+        // Tray menu picked an open question: show it so it can be answered
+        return EventsOn("showRequest", (id: string) => {
+            setFocusRequest(id);
+            setCurrentView('history');
+        });
+    }, []);
 
     const handleStart = () => {
         setCurrentView('source-select');
@@ -71,6 +82,7 @@ function App() {
                         key="welcome"
                         onStart={handleStart} 
                         onSettings={handleSettings}
+                        onHistory={() => setCurrentView('history')}
                         onRecentSelect={handleRecentSelect}
                         onViewBridge={() => setCurrentView('bridge-control')}
                     />
@@ -88,6 +100,13 @@ function App() {
                         onBack={handleBack} 
                     />
                 )}
+                {currentView === 'history' && (
+                    <HistoryPage 
+                        key="history"
+                        focusId={focusRequest}
+                        onBack={handleBack} 
+                    />
+                )}
                 {currentView === 'channel-select' && (
                     <ChannelSelect
                         key="channel"
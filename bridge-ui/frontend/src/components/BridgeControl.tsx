@@ -1,7 +1,8 @@
 import { useState, useEffect, useRef } from 'react';
 import { motion } from 'framer-motion';
-import { Square, ExternalLink, Copy, Check, ArrowLeft } from 'lucide-react';
-import { StopBridge, StartBridge } from "../../wailsjs/go/main/App";
+import { Square, ExternalLink, Copy, Check, ArrowLeft, Download } from 'lucide-react';
+import { StopBridge, StartBridge, ExportAudit } from "../../wailsjs/go/main/App";
+import { main } from "../../wailsjs/go/models";
 import { EventsOn } from "../../wailsjs/runtime";
 
 interface BridgeControlProps {
@@ -69,6 +70,11 @@ export default function BridgeControl({ onStop, onBack }: BridgeControlProps) {
         }
     };
 
+    const exportAudit = async (format: string) => {
+        const result = await ExportAudit(format, new main.AuditFilter({}));
+        setLogs(prev => [...prev, `📋 ${result}`]);
+    };
+
     const copyURL = () => {
         if (publicURL) {
             navigator.clipboard.writeText(publicURL);
@@ -109,7 +115,15 @@ export default function BridgeControl({ onStop, onBack }: BridgeControlProps) {
             <div className="console">
                 <div className="console-header">
                     <span>Live Logs</span>
-                    <span className="log-count">{logs.length} entries</span>
+                    <div className="console-actions">
+                        <button className="console-btn" onClick={() => exportAudit('csv')} title="Export audit log as CSV">
+                            <Download size={12} /> CSV
+                        </button>
+                        <button className="console-btn" onClick={() => exportAudit('json')} title="Export audit log as JSON">
+                            <Download size={12} /> JSON
+                        </button>
+                        <span className="log-count">{logs.length} entries</span>
+                    </div>
                 </div>
                 <div className="console-body">
                     {logs.map((log, i) => (
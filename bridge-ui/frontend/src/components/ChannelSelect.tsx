@@ -1,7 +1,7 @@
 import { motion } from 'framer-motion';
-import { ArrowLeft, MessageSquare, Mail, Phone, Smartphone, ChevronRight } from 'lucide-react';
+import { ArrowLeft, MessageSquare, Mail, Phone, Smartphone, Hash, Webhook, MessagesSquare, ChevronRight } from 'lucide-react';
 
-type Channel = 'telegram' | 'whatsapp' | 'gmail' | 'sms';
+type Channel = 'telegram' | 'whatsapp' | 'gmail' | 'sms' | 'slack' | 'webhook' | 'matrix';
 
 interface ChannelSelectProps {
     source: 'agent' | 'mcp';
@@ -41,6 +41,30 @@ const channels = [
         gradient: 'linear-gradient(135deg, #52525b 0%, #3f3f46 100%)',
         description: 'Via Twilio',
         tag: null
+    },
+    {
+        id: 'slack' as const,
+        name: 'Slack',
+        icon: Hash,
+        gradient: 'linear-gradient(135deg, #4A154B 0%, #611f69 100%)',
+        description: 'Approve with buttons in a channel',
+        tag: null
+    },
+    {
+        id: 'webhook' as const,
+        name: 'Webhook',
+        icon: Webhook,
+        gradient: 'linear-gradient(135deg, #f97316 0%, #ea580c 100%)',
+        description: 'Signed JSON to your own tooling',
+        tag: null
+    },
+    {
+        id: 'matrix' as const,
+        name: 'Matrix',
+        icon: MessagesSquare,
+        gradient: 'linear-gradient(135deg, #0dbd8b 0%, #0a8f6a 100%)',
+        description: 'Self-hosted chat room',
+        tag: null
     }
 ];
 
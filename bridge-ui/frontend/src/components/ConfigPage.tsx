@@ -1,9 +1,9 @@
 import { useState, useEffect } from 'react';
 import { motion } from 'framer-motion';
-import { ArrowLeft, MessageSquare, Mail, Phone, Smartphone, Check } from 'lucide-react';
+import { ArrowLeft, MessageSquare, Mail, Phone, Smartphone, Hash, Webhook, MessagesSquare, Check } from 'lucide-react';
 import { SaveConfig, LoadConfig } from "../../wailsjs/go/main/App";
 
-type Channel = 'telegram' | 'whatsapp' | 'gmail' | 'sms';
+type Channel = 'telegram' | 'whatsapp' | 'gmail' | 'sms' | 'slack' | 'webhook' | 'matrix';
 
 interface ConfigPageProps {
     channel: Channel;
@@ -20,7 +20,10 @@ const channelInfo: Record<Channel, { name: string; icon: any; gradient: string }
     telegram: { name: 'Telegram', icon: MessageSquare, gradient: 'linear-gradient(135deg, #0088cc 0%, #00aaff 100%)' },
     whatsapp: { name: 'WhatsApp', icon: Phone, gradient: 'linear-gradient(135deg, #25D366 0%, #128C7E 100%)' },
     gmail: { name: 'Gmail', icon: Mail, gradient: 'linear-gradient(135deg, #EA4335 0%, #FBBC05 100%)' },
-    sms: { name: 'SMS', icon: Smartphone, gradient: 'linear-gradient(135deg, #52525b 0%, #3f3f46 100%)' }
+    sms: { name: 'SMS', icon: Smartphone, gradient: 'linear-gradient(135deg, #52525b 0%, #3f3f46 100%)' },
+    slack: { name: 'Slack', icon: Hash, gradient: 'linear-gradient(135deg, #4A154B 0%, #611f69 100%)' },
+    webhook: { name: 'Webhook', icon: Webhook, gradient: 'linear-gradient(135deg, #f97316 0%, #ea580c 100%)' },
+    matrix: { name: 'Matrix', icon: MessagesSquare, gradient: 'linear-gradient(135deg, #0dbd8b 0%, #0a8f6a 100%)' }
 };
 
 const fieldConfigs: Record<Channel, { label: string; key: string; type?: string; placeholder: string; hint?: string }[]> = {
@@ -41,11 +44,42 @@ const fieldConfigs: Record<Channel, { label: string; key: string; type?: string;
         { label: 'Auth Token', key: 'twilio_token', type: 'password', placeholder: '••••••••••••' },
         { label: 'Twilio Phone Number', key: 'from', placeholder: '+1234567890', hint: 'Your Twilio number' },
         { label: 'Your Phone Number', key: 'to', placeholder: '+1234567890', hint: 'Where to send SMS' }
+    ],
+    slack: [
+        { label: 'Bot Token', key: 'bot_token', type: 'password', placeholder: 'xoxb-...', hint: 'OAuth & Permissions • needs chat:write' },
+        { label: 'Channel ID', key: 'channel', placeholder: 'C0123456789', hint: 'Invite the bot to this channel' },
+        { label: 'Signing Secret', key: 'signing_secret', type: 'password', placeholder: '••••••••••••', hint: 'Interactivity URL: <tunnel>/slack/interactions' },
+        { label: 'Allowed Users', key: 'allowed_users', placeholder: 'U0123ABCD', hint: 'Comma-separated member IDs who may answer; empty lets only routed approvers answer' }
+    ],
+    webhook: [
+        { label: 'Webhook URL', key: 'url', placeholder: 'https://n8n.example.com/webhook/momentum', hint: 'Receives a JSON envelope for every question' },
+        { label: 'Signing Secret', key: 'secret', type: 'password', placeholder: '••••••••••••', hint: 'Sent as X-Momentum-Signature: sha256=<hmac>' }
+    ],
+    matrix: [
+        { label: 'Homeserver URL', key: 'homeserver', placeholder: 'https://matrix.example.org' },
+        { label: 'Access Token', key: 'access_token', type: 'password', placeholder: 'syt_...', hint: 'Token of the bot account' },
+        { label: 'Room ID', key: 'room_id', placeholder: '!abcdef:example.org', hint: 'The bot must have joined this room' },
+        { label: 'Allowed Users', key: 'allowed_users', placeholder: '@you:example.org', hint: 'Comma-separated users who may answer; empty lets only routed approvers answer' }
     ]
 };
 
+interface ChannelSetting {
+    name: string;
+    enabled: boolean;
+}
+
+// Channels that are notified in parallel; older configs only have `channel`
+const enabledChannels = (config: any): string[] => {
+    if (Array.isArray(config.channels) && config.channels.length > 0) {
+        return config.channels.filter((c: ChannelSetting) => c.enabled).map((c: ChannelSetting) => c.name);
+    }
+    return config.channel ? [config.channel] : [];
+};
+
 export default function ConfigPage({ channel, source, onBack, onComplete }: ConfigPageProps) {
     const [fields, setFields] = useState<FormFields>({});
+    const [savedConfig, setSavedConfig] = useState<any>({});
+    const [keepOthers, setKeepOthers] = useState(true);
     const [ngrokToken, setNgrokToken] = useState('');
     const [saving, setSaving] = useState(false);
     const [message, setMessage] = useState('');
@@ -58,6 +92,7 @@ export default function ConfigPage({ channel, source, onBack, onComplete }: Conf
         LoadConfig().then((jsonStr: string) => {
             try {
                 const config = JSON.parse(jsonStr);
+                setSavedConfig(config);
                 if (config[channel]) {
                     setFields(config[channel]);
                 }
@@ -68,12 +103,15 @@ export default function ConfigPage({ channel, source, onBack, onComplete }: Conf
         });
     }, [channel]);
 
+    const otherChannels = enabledChannels(savedConfig).filter(c => c !== channel && c in channelInfo);
+
     const handleFieldChange = (key: string, value: string) => {
         setFields(prev => ({ ...prev, [key]: value }));
     };
 
     const isFormValid = () => {
-        if (!ngrokToken) return false;
+        // Telegram answers in-chat, so the tunnel is optional there
+        if (!ngrokToken && channel !== 'telegram') return false;
         return currentFields.every(f => fields[f.key]?.trim());
     };
 
@@ -83,8 +121,16 @@ export default function ConfigPage({ channel, source, onBack, onComplete }: Conf
         setSaving(true);
         setMessage('');
 
+        // Keep the other channels' credentials and fan-out settings
+        const channels: ChannelSetting[] = [{ name: channel, enabled: true }];
+        if (keepOthers) {
+            otherChannels.forEach(name => channels.push({ name, enabled: true }));
+        }
+
         const config = {
+            ...savedConfig,
             channel,
+            channels,
             source,
             ngrokToken,
             [channel]: fields
@@ -155,7 +201,9 @@ export default function ConfigPage({ channel, source, onBack, onComplete }: Conf
                                 onChange={(e) => setNgrokToken(e.target.value)}
                                 placeholder="Your ngrok authtoken"
                             />
-                            <span className="form-hint">Required for remote access • Get from ngrok.com</span>
+                            <span className="form-hint">
+                                {channel === 'telegram' ? 'Optional for Telegram' : 'Required for remote access'} • Get from ngrok.com
+                            </span>
                         </div>
                     </div>
 
@@ -177,6 +225,16 @@ export default function ConfigPage({ channel, source, onBack, onComplete }: Conf
                                 {field.hint && <span className="form-hint">{field.hint}</span>}
                             </div>
                         ))}
+                        {otherChannels.length > 0 && (
+                            <label className="form-checkbox">
+                                <input
+                                    type="checkbox"
+                                    checked={keepOthers}
+                                    onChange={(e) => setKeepOthers(e.target.checked)}
+                                />
+                                Also notify {otherChannels.map(c => channelInfo[c as Channel].name).join(', ')} • first answer wins
+                            </label>
+                        )}
                     </div>
 
                     <button 
@@ -0,0 +1,182 @@
+import { useState, useEffect, useRef } from 'react';
+import { motion } from 'framer-motion';
+import { ArrowLeft, RefreshCw, Send } from 'lucide-react';
+import { ListRequests, AnswerRequest } from "../../wailsjs/go/main/App";
+import { EventsOn } from "../../wailsjs/runtime";
+import { main } from "../../wailsjs/go/models";
+
+interface HistoryPageProps {
+    focusId?: string;
+    onBack: () => void;
+}
+
+const STATUS_LABELS: Record<string, string> = {
+    pending: '⏳ Pending',
+    answered: '✅ Answered',
+    expired: '⌛ Timed out',
+    cancelled: '✖️ Cancelled',
+};
+
+const CHANNELS = ['telegram', 'whatsapp', 'gmail', 'sms', 'slack', 'webhook', 'matrix', 'web', 'desktop'];
+
+export default function HistoryPage({ focusId, onBack }: HistoryPageProps) {
+    const [requests, setRequests] = useState<main.StoredRequest[]>([]);
+    const [filter, setFilter] = useState({ status: '', channel: '', since: '', until: '', search: '' });
+    const [error, setError] = useState<string | null>(null);
+    const [customAnswers, setCustomAnswers] = useState<Record<string, string>>({});
+    const [sending, setSending] = useState<string | null>(null);
+    const focusRef = useRef<HTMLDivElement>(null);
+
+    const load = () => {
+        ListRequests(new main.RequestFilter(filter))
+            .then((list) => {
+                setRequests(list);
+                setError(null);
+            })
+            .catch((err) => setError(String(err)));
+    };
+
+    useEffect(() => {
+        load();
+        // Refresh whenever the bridge records a change
+        const unsub = EventsOn("requestUpdated", load);
+        return () => unsub();
+    }, [filter]);
+
+    useEffect(() => {
+        focusRef.current?.scrollIntoView({ behavior: "smooth", block: "center" });
+    }, [focusId, requests]);
+
+    const updateFilter = (key: string, value: string) => {
+        setFilter(prev => ({ ...prev, [key]: value }));
+    };
+
+    const answer = async (id: string, text: string) => {
+        if (!text.trim()) return;
+        setSending(id);
+        const result = await AnswerRequest(id, text);
+        setSending(null);
+        if (result.startsWith('Error')) {
+            setError(result);
+        } else {
+            setCustomAnswers(prev => ({ ...prev, [id]: '' }));
+        }
+        load();
+    };
+
+    const formatTime = (value: any) => {
+        const date = new Date(value);
+        return isNaN(date.getTime()) ? '' : date.toLocaleString();
+    };
+
+    return (
+        <motion.div
+            className="history-screen"
+            initial={{ opacity: 0, x: 50 }}
+            animate={{ opacity: 1, x: 0 }}
+            exit={{ opacity: 0, x: -50 }}
+            transition={{ duration: 0.3 }}
+        >
+            {/* Back Button */}
+            <button className="back-btn" onClick={onBack}>
+                <ArrowLeft size={20} />
+                <span>Back</span>
+            </button>
+
+            <div className="history-header">
+                <h2 className="settings-title">History</h2>
+                <button className="console-btn" onClick={load} title="Refresh">
+                    <RefreshCw size={12} /> Refresh
+                </button>
+            </div>
+
+            <div className="history-filters">
+                <select className="history-input" value={filter.status} onChange={(e) => updateFilter('status', e.target.value)}>
+                    <option value="">All statuses</option>
+                    {Object.entries(STATUS_LABELS).map(([value, label]) => (
+                        <option key={value} value={value}>{label}</option>
+                    ))}
+                </select>
+                <select className="history-input" value={filter.channel} onChange={(e) => updateFilter('channel', e.target.value)}>
+                    <option value="">All channels</option>
+                    {CHANNELS.map((channel) => (
+                        <option key={channel} value={channel}>{channel}</option>
+                    ))}
+                </select>
+                <input className="history-input" type="date" value={filter.since} onChange={(e) => updateFilter('since', e.target.value)} title="From" />
+                <input className="history-input" type="date" value={filter.until} onChange={(e) => updateFilter('until', e.target.value)} title="Until" />
+                <input className="history-input" type="text" placeholder="Search..." value={filter.search} onChange={(e) => updateFilter('search', e.target.value)} />
+            </div>
+
+            {error && <div className="history-error">{error}</div>}
+
+            <div className="history-list">
+                {requests.length === 0 && <p className="history-empty">No requests yet</p>}
+                {requests.map((req) => (
+                    <div
+                        key={req.id}
+                        ref={req.id === focusId ? focusRef : undefined}
+                        className={`history-card ${req.status} ${req.id === focusId ? 'focused' : ''}`}
+                    >
+                        <div className="history-card-header">
+                            <span className={`history-status ${req.status}`}>{STATUS_LABELS[req.status] || req.status}</span>
+                            {req.risk === 'high' && <span className="history-risk">🔐 High risk</span>}
+                            <span className="history-meta">#{req.id} · {formatTime(req.created_at)}</span>
+                        </div>
+                        <p className="history-question">{req.question}</p>
+                        {req.context?.command && <pre className="history-command">$ {req.context.command}</pre>}
+                        {req.context?.file_path && <p className="history-meta">📄 {req.context.file_path}</p>}
+
+                        {req.status !== 'pending' && (
+                            <p className="history-answer">
+                                {req.answer ? <>→ <strong>{req.answer}</strong></> : 'No answer'}
+                                {req.via && <span className="history-meta"> via {req.via} · {formatTime(req.updated_at)}</span>}
+                            </p>
+                        )}
+
+                        {req.votes && req.votes.length > 0 && (
+                            <ul className="history-votes">
+                                {req.votes.map((vote, i) => (
+                                    <li key={i}>{vote.approver || vote.user || vote.via}: {vote.answer}</li>
+                                ))}
+                            </ul>
+                        )}
+
+                        {req.status === 'pending' && (
+                            <div className="history-answer-form">
+                                {req.options?.map((opt) => (
+                                    <button
+                                        key={opt}
+                                        className="history-option"
+                                        disabled={sending === req.id}
+                                        onClick={() => answer(req.id, opt)}
+                                    >
+                                        {opt}
+                                    </button>
+                                ))}
+                                <div className="history-custom">
+                                    <input
+                                        className="history-input"
+                                        type="text"
+                                        placeholder={req.answer_schema?.type === 'form' ? 'JSON, e.g. {"field": "value"}' : 'Custom answer...'}
+                                        value={customAnswers[req.id] || ''}
+                                        onChange={(e) => setCustomAnswers(prev => ({ ...prev, [req.id]: e.target.value }))}
+                                        onKeyDown={(e) => e.key === 'Enter' && answer(req.id, customAnswers[req.id] || '')}
+                                    />
+                                    <button
+                                        className="console-btn"
+                                        disabled={sending === req.id}
+                                        onClick={() => answer(req.id, customAnswers[req.id] || '')}
+                                    >
+                                        <Send size={12} />
+                                    </button>
+                                </div>
+                                <span className="history-meta">Expires {formatTime(req.deadline)}</span>
+                            </div>
+                        )}
+                    </div>
+                ))}
+            </div>
+        </motion.div>
+    );
+}
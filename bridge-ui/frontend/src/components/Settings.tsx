@@ -1,13 +1,103 @@
+import { useState, useEffect } from 'react';
 import { motion } from 'framer-motion';
-import { ArrowLeft, Palette } from 'lucide-react';
+import { ArrowLeft, Palette, ShieldCheck } from 'lucide-react';
+import { QRCodeSVG } from 'qrcode.react';
+import { LoadConfig, SaveConfig, NewTOTPEnrollment, ConfirmTOTP, ResponseLanguages } from "../../wailsjs/go/main/App";
+import { main } from "../../wailsjs/go/models";
+
+// Names for the languages the response pages come in; unknown codes show as-is
+const languageNames: Record<string, string> = {
+    en: 'English',
+    es: 'Español',
+    fr: 'Français',
+    de: 'Deutsch',
+};
 
 interface SettingsProps {
     onBack: () => void;
 }
 
 export default function Settings({ onBack }: SettingsProps) {
+    const [config, setConfig] = useState<any>({});
+    const [pin, setPin] = useState('');
+    const [enrollment, setEnrollment] = useState<main.TOTPEnrollment | null>(null);
+    const [code, setCode] = useState('');
+    const [message, setMessage] = useState('');
+    const [responsePage, setResponsePage] = useState({ brand: '', language: '', theme: '' });
+    const [languages, setLanguages] = useState<string[]>(['en']);
+    const [appearanceMessage, setAppearanceMessage] = useState('');
+
+    useEffect(() => {
+        LoadConfig().then((jsonStr: string) => {
+            try {
+                const loaded = JSON.parse(jsonStr);
+                setConfig(loaded);
+                setResponsePage({ brand: '', language: '', theme: '', ...loaded.responsePage });
+            } catch (e) {}
+        });
+        ResponseLanguages().then(setLanguages);
+    }, []);
+
+    const totpEnrolled = !!config.secondFactor?.totpSecret;
+    // Only a hash of the PIN is saved, so it is never shown
+    const pinSet = !!config.secondFactor?.pin;
+
+    // Keeps the rest of the config as loaded and only replaces one section
+    const saveSection = async (section: string, value: any, done: string, report: (msg: string) => void) => {
+        const updated = { ...config, [section]: value };
+        const result = await SaveConfig(JSON.stringify(updated));
+        if (result.includes('Error')) {
+            report(result);
+            return false;
+        }
+        setConfig(updated);
+        report(done);
+        return true;
+    };
+
+    const saveSecondFactor = (secondFactor: any, done: string) => saveSection('secondFactor', secondFactor, done, setMessage);
+
+    const saveResponsePage = () => {
+        saveSection('responsePage', { ...responsePage, brand: responsePage.brand.trim() }, '✓ Response page saved', setAppearanceMessage);
+    };
+
+    const savePin = async () => {
+        if (!/^\d{4,12}$/.test(pin)) {
+            setMessage('Error: the PIN must be 4 to 12 digits');
+            return;
+        }
+        if (await saveSecondFactor({ ...config.secondFactor, pin }, '✓ PIN saved')) {
+            setPin('');
+        }
+    };
+
+    const removePin = () => {
+        saveSecondFactor({ ...config.secondFactor, pin: '' }, '✓ PIN removed');
+    };
+
+    const startEnrollment = async () => {
+        setMessage('');
+        setCode('');
+        setEnrollment(await NewTOTPEnrollment());
+    };
+
+    const confirmEnrollment = async () => {
+        if (!enrollment) return;
+        if (!(await ConfirmTOTP(enrollment.secret, code))) {
+            setMessage('Error: that code does not match - check the time on your phone and try again');
+            return;
+        }
+        if (await saveSecondFactor({ ...config.secondFactor, totpSecret: enrollment.secret }, '✓ Authenticator enrolled')) {
+            setEnrollment(null);
+        }
+    };
+
+    const removeTotp = () => {
+        saveSecondFactor({ ...config.secondFactor, totpSecret: '' }, '✓ Authenticator removed');
+    };
+
     return (
-        <motion.div 
+        <motion.div
             className="settings-screen"
             initial={{ opacity: 0, x: 50 }}
             animate={{ opacity: 1, x: 0 }}
@@ -21,7 +111,7 @@ export default function Settings({ onBack }: SettingsProps) {
             </button>
 
             <div className="settings-content">
-                <motion.h2 
+                <motion.h2
                     className="settings-title"
                     initial={{ y: -10, opacity: 0 }}
                     animate={{ y: 0, opacity: 1 }}
@@ -30,15 +120,134 @@ export default function Settings({ onBack }: SettingsProps) {
                     Settings
                 </motion.h2>
 
-                <motion.div 
-                    className="coming-soon-card"
+                <motion.div
+                    className="security-card"
+                    initial={{ y: 20, opacity: 0 }}
+                    animate={{ y: 0, opacity: 1 }}
+                    transition={{ delay: 0.15 }}
+                >
+                    <div className="form-section-title">
+                        <ShieldCheck size={18} />
+                        High-Risk Confirmation
+                    </div>
+                    <span className="form-hint">
+                        When an agent marks a question as high risk, the response page asks for your PIN or
+                        authenticator code. Takes effect the next time the bridge starts.
+                    </span>
+
+                    <div className="form-group">
+                        <label>PIN</label>
+                        <div className="security-inline">
+                            {pinSet && <span className="security-status">✓ Set</span>}
+                            <input
+                                type="password"
+                                inputMode="numeric"
+                                value={pin}
+                                onChange={(e) => setPin(e.target.value)}
+                                placeholder={pinSet ? 'New PIN, 4-12 digits' : '4-12 digits'}
+                            />
+                            <button className="console-btn" onClick={savePin}>Save</button>
+                            {pinSet && <button className="console-btn" onClick={removePin}>Remove</button>}
+                        </div>
+                    </div>
+
+                    <div className="form-group">
+                        <label>Authenticator App (TOTP)</label>
+                        {totpEnrolled && !enrollment && (
+                            <div className="security-inline">
+                                <span className="security-status">✓ Enrolled</span>
+                                <button className="console-btn" onClick={startEnrollment}>Replace</button>
+                                <button className="console-btn" onClick={removeTotp}>Remove</button>
+                            </div>
+                        )}
+                        {!totpEnrolled && !enrollment && (
+                            <button className="console-btn" onClick={startEnrollment}>Set up authenticator</button>
+                        )}
+                        {enrollment && (
+                            <div className="security-enroll">
+                                <div className="qr-box">
+                                    <QRCodeSVG value={enrollment.uri} size={160} />
+                                </div>
+                                <span className="form-hint">Scan with Google Authenticator, 1Password, Authy... or enter <code>{enrollment.secret}</code></span>
+                                <div className="security-inline">
+                                    <input
+                                        type="text"
+                                        inputMode="numeric"
+                                        value={code}
+                                        onChange={(e) => setCode(e.target.value)}
+                                        onKeyDown={(e) => e.key === 'Enter' && confirmEnrollment()}
+                                        placeholder="6-digit code"
+                                    />
+                                    <button className="console-btn" onClick={confirmEnrollment}>Verify</button>
+                                    <button className="console-btn" onClick={() => setEnrollment(null)}>Cancel</button>
+                                </div>
+                            </div>
+                        )}
+                    </div>
+
+                    {message && (
+                        <p className={`form-message ${message.includes('Error') ? 'error' : 'success'}`}>
+                            {message}
+                        </p>
+                    )}
+                </motion.div>
+
+                <motion.div
+                    className="appearance-card"
                     initial={{ y: 20, opacity: 0 }}
                     animate={{ y: 0, opacity: 1 }}
                     transition={{ delay: 0.2 }}
                 >
-                    <Palette size={48} strokeWidth={1} />
-                    <h3>Appearance Settings</h3>
-                    <p>Coming Soon</p>
+                    <div className="form-section-title">
+                        <Palette size={18} />
+                        Response Page
+                    </div>
+                    <span className="form-hint">
+                        How the page behind notification links looks on your phone. Takes effect the next
+                        time the bridge starts.
+                    </span>
+
+                    <div className="form-group">
+                        <label>Title</label>
+                        <input
+                            type="text"
+                            value={responsePage.brand}
+                            onChange={(e) => setResponsePage({ ...responsePage, brand: e.target.value })}
+                            placeholder="Remote Bridge"
+                        />
+                    </div>
+
+                    <div className="form-group">
+                        <label>Theme</label>
+                        <select
+                            value={responsePage.theme}
+                            onChange={(e) => setResponsePage({ ...responsePage, theme: e.target.value })}
+                        >
+                            <option value="">Follow the phone</option>
+                            <option value="light">Light</option>
+                            <option value="dark">Dark</option>
+                        </select>
+                    </div>
+
+                    <div className="form-group">
+                        <label>Language</label>
+                        <select
+                            value={responsePage.language || 'en'}
+                            onChange={(e) => setResponsePage({ ...responsePage, language: e.target.value })}
+                        >
+                            {languages.map((code) => (
+                                <option key={code} value={code}>{languageNames[code] || code}</option>
+                            ))}
+                        </select>
+                    </div>
+
+                    <button className="console-btn" onClick={saveResponsePage}>Save</button>
+
+                    {appearanceMessage && (
+                        <p className={`form-message ${appearanceMessage.includes('Error') ? 'error' : 'success'}`}>
+                            {appearanceMessage}
+                        </p>
+                    )}
                 </motion.div>
             </div>
         </motion.div>
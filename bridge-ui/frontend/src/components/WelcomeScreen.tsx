@@ -1,5 +1,5 @@
 import { motion } from 'framer-motion';
-import { Settings, Zap, Plus, Activity } from 'lucide-react';
+import { Settings, Zap, Plus, Activity, History } from 'lucide-react';
 import { useEffect, useState } from 'react';
 import { GetRecentChannels, IsBridgeRunning } from '../../wailsjs/go/main/App';
 
@@ -13,11 +13,12 @@ interface RecentChannel {
 interface WelcomeScreenProps {
     onStart: () => void;
     onSettings: () => void;
+    onHistory?: () => void;
     onRecentSelect?: (channel: RecentChannel) => void;
     onViewBridge?: () => void;
 }
 
-export default function WelcomeScreen({ onStart, onSettings, onRecentSelect, onViewBridge }: WelcomeScreenProps) {
+export default function WelcomeScreen({ onStart, onSettings, onHistory, onRecentSelect, onViewBridge }: WelcomeScreenProps) {
     const [recents, setRecents] = useState<RecentChannel[]>([]);
     const [bridgeRunning, setBridgeRunning] = useState(false);
 
@@ -56,6 +57,13 @@ export default function WelcomeScreen({ onStart, onSettings, onRecentSelect, onV
                 <Settings size={20} />
             </button>
 
+            {/* History Icon - Top Right */}
+            {onHistory && (
+                <button className="history-btn" onClick={onHistory} title="History">
+                    <History size={20} />
+                </button>
+            )}
+
             {/* Main Content */}
             <div className="welcome-content">
                 {/* Logo */}
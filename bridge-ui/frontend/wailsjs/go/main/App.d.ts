@@ -4,22 +4,40 @@ import {main} from '../models';
 
 export function AddRecentChannel(arg1:string,arg2:string,arg3:string):Promise<void>;
 
+export function AnswerRequest(arg1:string,arg2:string):Promise<string>;
+
+export function ConfirmTOTP(arg1:string,arg2:string):Promise<boolean>;
+
+export function ExportAudit(arg1:string,arg2:main.AuditFilter):Promise<string>;
+
 export function GetRecentChannels():Promise<Array<main.RecentChannel>>;
 
+export function GetRequest(arg1:string):Promise<main.StoredRequest>;
+
 export function HideWindow():Promise<void>;
 
 export function IsBridgeRunning():Promise<boolean>;
 
 export function KillExistingBridges():Promise<void>;
 
+export function ListRequests(arg1:main.RequestFilter):Promise<Array<main.StoredRequest>>;
+
 export function LoadConfig():Promise<string>;
 
+export function NewTOTPEnrollment():Promise<main.TOTPEnrollment>;
+
+export function QueryAudit(arg1:main.AuditFilter):Promise<Array<main.AuditEntry>>;
+
 export function QuitApp():Promise<void>;
 
 export function ReadLogs():Promise<Array<string>>;
 
+export function ResponseLanguages():Promise<Array<string>>;
+
 export function SaveConfig(arg1:string):Promise<string>;
 
+export function ShowRequest(arg1:string):Promise<void>;
+
 export function ShowWindow():Promise<void>;
 
 export function StartBridge():Promise<string>;
@@ -6,10 +6,26 @@ export function AddRecentChannel(arg1, arg2, arg3) {
   return window['go']['main']['App']['AddRecentChannel'](arg1, arg2, arg3);
 }
 
+export function AnswerRequest(arg1, arg2) {
+  return window['go']['main']['App']['AnswerRequest'](arg1, arg2);
+}
+
+export function ConfirmTOTP(arg1, arg2) {
+  return window['go']['main']['App']['ConfirmTOTP'](arg1, arg2);
+}
+
+export function ExportAudit(arg1, arg2) {
+  return window['go']['main']['App']['ExportAudit'](arg1, arg2);
+}
+
 export function GetRecentChannels() {
   return window['go']['main']['App']['GetRecentChannels']();
 }
 
+export function GetRequest(arg1) {
+  return window['go']['main']['App']['GetRequest'](arg1);
+}
+
 export function HideWindow() {
   return window['go']['main']['App']['HideWindow']();
 }
@@ -22,10 +38,22 @@ export function KillExistingBridges() {
   return window['go']['main']['App']['KillExistingBridges']();
 }
 
+export function ListRequests(arg1) {
+  return window['go']['main']['App']['ListRequests'](arg1);
+}
+
 export function LoadConfig() {
   return window['go']['main']['App']['LoadConfig']();
 }
 
+export function NewTOTPEnrollment() {
+  return window['go']['main']['App']['NewTOTPEnrollment']();
+}
+
+export function QueryAudit(arg1) {
+  return window['go']['main']['App']['QueryAudit'](arg1);
+}
+
 export function QuitApp() {
   return window['go']['main']['App']['QuitApp']();
 }
@@ -34,10 +62,18 @@ export function ReadLogs() {
   return window['go']['main']['App']['ReadLogs']();
 }
 
+export function ResponseLanguages() {
+  return window['go']['main']['App']['ResponseLanguages']();
+}
+
 export function SaveConfig(arg1) {
   return window['go']['main']['App']['SaveConfig'](arg1);
 }
 
+export function ShowRequest(arg1) {
+  return window['go']['main']['App']['ShowRequest'](arg1);
+}
+
 export function ShowWindow() {
   return window['go']['main']['App']['ShowWindow']();
 }
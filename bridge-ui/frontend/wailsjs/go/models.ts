@@ -1,5 +1,270 @@
 export namespace main {
 	
+	export class ChannelDelivery {
+	    channel: string;
+	    ok: boolean;
+	    error?: string;
+	    // Go type: time
+	    at: any;
+	    second_factor?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ChannelDelivery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.channel = source["channel"];
+	        this.ok = source["ok"];
+	        this.error = source["error"];
+	        this.at = this.convertValues(source["at"], null);
+	        this.second_factor = source["second_factor"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AuditEntry {
+	    // Go type: time
+	    time: any;
+	    event: string;
+	    request_id: string;
+	    question: string;
+	    options: string[];
+	    notified?: string[];
+	    deliveries?: ChannelDelivery[];
+	    approver?: string;
+	    user?: string;
+	    via?: string;
+	    client_ip?: string;
+	    answer: string;
+	    outcome?: string;
+	    latency_ms: number;
+	    second_factor?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = this.convertValues(source["time"], null);
+	        this.event = source["event"];
+	        this.request_id = source["request_id"];
+	        this.question = source["question"];
+	        this.options = source["options"];
+	        this.notified = source["notified"];
+	        this.deliveries = this.convertValues(source["deliveries"], ChannelDelivery);
+	        this.approver = source["approver"];
+	        this.user = source["user"];
+	        this.via = source["via"];
+	        this.client_ip = source["client_ip"];
+	        this.answer = source["answer"];
+	        this.outcome = source["outcome"];
+	        this.latency_ms = source["latency_ms"];
+	        this.second_factor = source["second_factor"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AuditFilter {
+	    request_id: string;
+	    event: string;
+	    since: string;
+	    until: string;
+	    search: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.request_id = source["request_id"];
+	        this.event = source["event"];
+	        this.since = source["since"];
+	        this.until = source["until"];
+	        this.search = source["search"];
+	    }
+	}
+	
+	export class RequestFilter {
+	    status: string;
+	    channel: string;
+	    since: string;
+	    until: string;
+	    search: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RequestFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.status = source["status"];
+	        this.channel = source["channel"];
+	        this.since = source["since"];
+	        this.until = source["until"];
+	        this.search = source["search"];
+	    }
+	}
+	export class TOTPEnrollment {
+	    secret: string;
+	    uri: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TOTPEnrollment(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.secret = source["secret"];
+	        this.uri = source["uri"];
+	    }
+	}
+	export class Vote {
+	    approver: string;
+	    answer: string;
+	    via: string;
+	    user?: string;
+	    client_ip?: string;
+	    // Go type: time
+	    at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Vote(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.approver = source["approver"];
+	        this.answer = source["answer"];
+	        this.via = source["via"];
+	        this.user = source["user"];
+	        this.client_ip = source["client_ip"];
+	        this.at = this.convertValues(source["at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class StoredRequest {
+	    id: string;
+	    question: string;
+	    options: string[];
+	    category?: string;
+	    workspace?: string;
+	    risk?: string;
+	    context?: responseui.Context;
+	    answer_schema?: responseui.Schema;
+	    status: string;
+	    answer?: string;
+	    via?: string;
+	    approvers?: string[];
+	    needed?: number;
+	    votes?: Vote[];
+	    deliveries?: ChannelDelivery[];
+	    delivered: boolean;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    deadline: any;
+	    // Go type: time
+	    updated_at: any;
+	    second_factor?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new StoredRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.question = source["question"];
+	        this.options = source["options"];
+	        this.category = source["category"];
+	        this.workspace = source["workspace"];
+	        this.risk = source["risk"];
+	        this.context = this.convertValues(source["context"], responseui.Context);
+	        this.answer_schema = this.convertValues(source["answer_schema"], responseui.Schema);
+	        this.status = source["status"];
+	        this.answer = source["answer"];
+	        this.via = source["via"];
+	        this.approvers = source["approvers"];
+	        this.needed = source["needed"];
+	        this.votes = this.convertValues(source["votes"], Vote);
+	        this.deliveries = this.convertValues(source["deliveries"], ChannelDelivery);
+	        this.delivered = source["delivered"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.deadline = this.convertValues(source["deadline"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.second_factor = source["second_factor"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class RecentChannel {
 	    name: string;
 	    icon: string;
@@ -21,3 +286,102 @@ export namespace main {
 
 }
 
+export namespace responseui {
+	
+	export class Context {
+	    details?: string;
+	    diff?: string;
+	    command?: string;
+	    file_path?: string;
+	    file_snippet?: string;
+	    links?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Context(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.details = source["details"];
+	        this.diff = source["diff"];
+	        this.command = source["command"];
+	        this.file_path = source["file_path"];
+	        this.file_snippet = source["file_snippet"];
+	        this.links = source["links"];
+	    }
+	}
+	export class Field {
+	    name?: string;
+	    label?: string;
+	    type?: string;
+	    options?: string[];
+	    min?: number;
+	    max?: number;
+	    pattern?: string;
+	    optional?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Field(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.label = source["label"];
+	        this.type = source["type"];
+	        this.options = source["options"];
+	        this.min = source["min"];
+	        this.max = source["max"];
+	        this.pattern = source["pattern"];
+	        this.optional = source["optional"];
+	    }
+	}
+	export class Schema {
+	    name?: string;
+	    label?: string;
+	    type?: string;
+	    options?: string[];
+	    min?: number;
+	    max?: number;
+	    pattern?: string;
+	    optional?: boolean;
+	    fields?: Field[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Schema(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.label = source["label"];
+	        this.type = source["type"];
+	        this.options = source["options"];
+	        this.min = source["min"];
+	        this.max = source["max"];
+	        this.pattern = source["pattern"];
+	        this.optional = source["optional"];
+	        this.fields = this.convertValues(source["fields"], Field);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
@@ -3,6 +3,7 @@ module bridge-ui
 go 1.23.0
 
 require (
+	github.com/HarshalPatel1972/remote-bridge/responseui v0.0.0
 	github.com/getlantern/systray v1.2.2
 	github.com/go-telegram-bot-api/telegram-bot-api/v5 v5.5.1
 	github.com/google/uuid v1.6.0
@@ -66,3 +67,5 @@ require (
 )
 
 // replace github.com/wailsapp/wails/v2 v2.11.0 => C:\Users\Harshal Patel\go\pkg\mod
+
+replace github.com/HarshalPatel1972/remote-bridge/responseui => ../responseui
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui/requestlog"
+)
+
+// RequestFilter narrows the request history; empty fields match everything
+type RequestFilter struct {
+	Status  string `json:"status"`  // "pending", "answered", "expired" or "cancelled"
+	Channel string `json:"channel"` // Notified through or answered via this channel
+	Since   string `json:"since"`   // RFC 3339 or YYYY-MM-DD
+	Until   string `json:"until"`   // RFC 3339 or YYYY-MM-DD (inclusive)
+	Search  string `json:"search"`  // Case-insensitive match on question or answer
+}
+
+// matches reports whether r passes the filter; since and until are the parsed dates
+func (f RequestFilter) matches(r StoredRequest, since, until time.Time) bool {
+	if f.Status != "" && r.Status != f.Status {
+		return false
+	}
+	if !since.IsZero() && r.CreatedAt.Before(since) {
+		return false
+	}
+	if !until.IsZero() && !r.CreatedAt.Before(until) {
+		return false
+	}
+	if f.Search != "" && !strings.Contains(strings.ToLower(r.Question+"\n"+r.Answer), strings.ToLower(f.Search)) {
+		return false
+	}
+	if f.Channel == "" || r.Via == f.Channel {
+		return true
+	}
+	for _, delivery := range r.Deliveries {
+		if delivery.Channel == f.Channel {
+			return true
+		}
+	}
+	return false
+}
+
+// ListRequests returns the request history matching filter, newest first.
+// It reads the log itself so requests from an --mcp bridge process show up too.
+func (a *App) ListRequests(filter RequestFilter) ([]StoredRequest, error) {
+	since, err := parseAuditTime(filter.Since, false)
+	if err != nil {
+		return nil, err
+	}
+	until, err := parseAuditTime(filter.Until, true)
+	if err != nil {
+		return nil, err
+	}
+
+	byID, err := requestlog.Read(requestStorePath())
+	if err != nil {
+		return nil, err
+	}
+	requests := []StoredRequest{}
+	for _, r := range requestlog.Sort(byID) {
+		r = withStaleExpired(r)
+		if filter.matches(r, since, until) {
+			requests = append(requests, r)
+		}
+	}
+	return requests, nil
+}
+
+// GetRequest returns the latest state of one request
+func (a *App) GetRequest(id string) (StoredRequest, error) {
+	byID, err := requestlog.Read(requestStorePath())
+	if err != nil {
+		return StoredRequest{}, err
+	}
+	r, ok := byID[id]
+	if !ok {
+		return StoredRequest{}, fmt.Errorf("request %s not found", id)
+	}
+	return withStaleExpired(r), nil
+}
+
+// withStaleExpired reports a pending request past its deadline as expired; the
+// bridge that held it stopped before it could record that
+func withStaleExpired(r StoredRequest) StoredRequest {
+	if r.Status == "pending" && !r.Deadline.IsZero() && time.Now().After(r.Deadline) {
+		r.Status = "expired"
+	}
+	return r
+}
+
+// AnswerRequest answers a pending request from the desktop UI
+func (a *App) AnswerRequest(id, answer string) string {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return "Error: answer is empty"
+	}
+
+	held, err := a.bridge.answerFromDesktop(id, answer, "desktop")
+	if held {
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		return "Answer sent: " + answer
+	}
+
+	// Not ours: an --mcp bridge process may be waiting on it, so hand the answer
+	// to the response server the store records for that process
+	r, err := a.GetRequest(id)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if r.Status != "pending" {
+		return fmt.Sprintf("Error: request is already %s", r.Status)
+	}
+	if r.URL == "" {
+		return "Error: the bridge holding this request can't be reached - answer it from its notification"
+	}
+	key, err := desktopKey()
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	result, err := forwardDesktopAnswer(r.URL, key, id, answer)
+	if err != nil {
+		return fmt.Sprintf("Error: the bridge holding this request can't be reached: %v", err)
+	}
+	switch result.Outcome {
+	case "answered":
+		return "Answer sent: " + answer
+	case "not_held":
+		return "Error: the bridge holding this request no longer has it"
+	}
+	return "Error: " + result.Error
+}
+
+// desktopAnswerResult is what /desktop/answer reports to the desktop app
+type desktopAnswerResult struct {
+	Outcome string `json:"outcome"` // "answered", "refused" or "not_held"
+	Error   string `json:"error,omitempty"`
+}
+
+// desktopKeyFileName holds the key the desktop app relays answers with, next to
+// bridge-config.json. It is not the /ask token, which the agent's MCP adapter holds.
+const desktopKeyFileName = "bridge-desktop.key"
+
+// desktopKey loads the desktop answer key, creating it on first use. Whichever
+// process creates it first wins; the others read that one.
+func desktopKey() (string, error) {
+	path := filepath.Join(filepath.Dir(getConfigPath()), desktopKeyFileName)
+	if data, err := os.ReadFile(path); err == nil {
+		if key := strings.TrimSpace(string(data)); len(key) >= 64 {
+			return key, nil
+		}
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	key := hex.EncodeToString(raw)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if os.IsExist(err) {
+		data, err := os.ReadFile(path)
+		return strings.TrimSpace(string(data)), err
+	}
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	_, err = f.WriteString(key)
+	return key, err
+}
+
+// serveDesktopAnswers listens on loopback for answers the desktop app relays for
+// requests this process holds. They never come through the tunnel.
+func (b *BridgeService) serveDesktopAnswers() (net.Listener, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/desktop/answer", b.handleDesktopAnswer)
+	go http.Serve(listener, mux)
+	return listener, nil
+}
+
+// forwardDesktopAnswer relays an answer to the bridge process whose loopback
+// desktop answer server is at url; key is the desktop key
+func forwardDesktopAnswer(url, key, requestID, answer string) (desktopAnswerResult, error) {
+	var result desktopAnswerResult
+	body, _ := json.Marshal(map[string]string{"request_id": requestID, "answer": answer})
+	req, err := http.NewRequest("POST", url+"/desktop/answer", bytes.NewReader(body))
+	if err != nil {
+		return result, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+key)
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return result, fmt.Errorf("it refused the answer (%s)", resp.Status)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	return result, err
+}
+
+// handleDesktopAnswer takes an answer the desktop app relays for a request this
+// process is waiting on. It only answers loopback callers holding the desktop key.
+func (b *BridgeService) handleDesktopAnswer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "POST required", 405)
+		return
+	}
+	if !desktopAuthorized(r) {
+		b.log(fmt.Sprintf("🚫 Rejected unauthenticated desktop answer from %s", r.RemoteAddr))
+		http.Error(w, "Unauthorized", 401)
+		return
+	}
+	var req struct {
+		RequestID string `json:"request_id"`
+		Answer    string `json:"answer"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", 400)
+		return
+	}
+
+	result := desktopAnswerResult{Outcome: "answered"}
+	// Whoever relays over HTTP hasn't shown a second factor, whatever they hold
+	held, err := b.answerFromDesktop(req.RequestID, strings.TrimSpace(req.Answer), "")
+	switch {
+	case !held:
+		result.Outcome = "not_held"
+	case err != nil:
+		result.Outcome, result.Error = "refused", err.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// desktopAuthorized checks that a relayed answer comes from this machine with the desktop key
+func desktopAuthorized(r *http.Request) bool {
+	host, _, _ := net.SplitHostPort(r.RemoteAddr)
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return false
+	}
+	want, err := desktopKey()
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	return err == nil && want != "" && subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1
+}
+
+// answerFromDesktop resolves a request this process is waiting on. secondFactor
+// is "desktop" only for the app's own window, which counts as the user at the
+// machine; relayed answers pass "", so high-risk requests refuse them.
+// held is false when the request isn't pending here.
+func (b *BridgeService) answerFromDesktop(requestID, answer, secondFactor string) (held bool, err error) {
+	b.pendingMu.Lock()
+	_, exists := b.pendingRequests[requestID]
+	data := b.requestData[requestID]
+	b.pendingMu.Unlock()
+
+	if !exists {
+		return false, nil
+	}
+	if answer == "" {
+		return true, fmt.Errorf("answer is empty")
+	}
+	if len(data.Approvers) > 0 {
+		return true, fmt.Errorf("this request needs named approvers - answer it from your notification")
+	}
+	if _, err := data.Schema.Normalize(answer); err != nil {
+		return true, err
+	}
+	switch err := b.submitVote(requestID, Vote{Answer: answer, Via: "desktop", User: "desktop", SecondFactor: secondFactor}); err {
+	case nil:
+	case errSecondFactorRequired:
+		return true, fmt.Errorf("this high-risk request can only be answered in the window of the app that holds it, or on the response page with your PIN or authenticator code")
+	default:
+		// Already answered, or an answer the schema refuses
+		return true, err
+	}
+	b.log(fmt.Sprintf("📥 Desktop response: %s -> %s", requestID, answer))
+	return true, nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui"
+)
+
+// TestForwardDesktopAnswer has the desktop app answer a request held by another
+// bridge process, which serves /desktop/answer on loopback
+func TestForwardDesktopAnswer(t *testing.T) {
+	key, err := desktopKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name         string
+		token        string
+		requestID    string
+		answer       string
+		approvers    []string
+		secondFactor bool
+		wantErr      bool
+		wantOutcome  string
+	}{
+		{"answered", key, "req1", "Yes", nil, false, false, "answered"},
+		{"wrong token", "guess", "req1", "Yes", nil, false, true, ""},
+		{"the agent's /ask token", "secret", "req1", "Yes", nil, false, true, ""},
+		{"not held there", key, "other", "Yes", nil, false, false, "not_held"},
+		{"empty answer", key, "req1", "", nil, false, false, "refused"},
+		{"answer the schema refuses", key, "req1", "Maybe", nil, false, false, "refused"},
+		{"routed to approvers", key, "req1", "Yes", []string{"alice"}, false, false, "refused"},
+		{"high risk", key, "req1", "Yes", nil, true, false, "refused"},
+	}
+	for _, tt := range tests {
+		owner := NewBridgeService()
+		owner.cfg.AskToken = "secret"
+		ch := make(chan string, 1)
+		owner.pendingRequests["req1"] = ch
+		owner.requestData["req1"] = RequestData{
+			Question:     "Deploy?",
+			Options:      []string{"Yes", "No"},
+			Schema:       responseui.Schema{Field: responseui.Field{Type: responseui.TypeChoice, Options: []string{"Yes", "No"}}},
+			Approvers:    tt.approvers,
+			SecondFactor: tt.secondFactor,
+		}
+		server := httptest.NewServer(http.HandlerFunc(owner.handleDesktopAnswer))
+
+		result, err := forwardDesktopAnswer(server.URL, tt.token, tt.requestID, tt.answer)
+		server.Close()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v", tt.name, err)
+		}
+		if result.Outcome != tt.wantOutcome {
+			t.Errorf("%s: outcome %q, want %q (%s)", tt.name, result.Outcome, tt.wantOutcome, result.Error)
+		}
+		if answered := len(ch) == 1; answered != (tt.wantOutcome == "answered") {
+			t.Errorf("%s: answered = %v", tt.name, answered)
+		}
+		if tt.wantOutcome == "refused" && result.Error == "" {
+			t.Errorf("%s: refused without a reason", tt.name)
+		}
+	}
+}
+
+// TestDesktopAnswerInWindow checks that the app's own window may answer a
+// high-risk request it holds
+func TestDesktopAnswerInWindow(t *testing.T) {
+	b := NewBridgeService()
+	ch := make(chan string, 1)
+	b.pendingRequests["req1"] = ch
+	b.requestData["req1"] = RequestData{Question: "Drop the table?", Options: []string{"Yes", "No"}, SecondFactor: true}
+
+	if held, err := b.answerFromDesktop("req1", "Yes", "desktop"); !held || err != nil {
+		t.Fatalf("held %v, err %v", held, err)
+	}
+	if answer := <-ch; answer != "Yes" {
+		t.Errorf("answer %q", answer)
+	}
+}
+
+func TestDesktopAuthorized(t *testing.T) {
+	key, err := desktopKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		remoteAddr string
+		token      string
+		want       bool
+	}{
+		{"loopback with the key", "127.0.0.1:50000", key, true},
+		{"IPv6 loopback with the key", "[::1]:50000", key, true},
+		{"remote with the key", "203.0.113.7:50000", key, false},
+		{"loopback without a token", "127.0.0.1:50000", "", false},
+		{"loopback with a wrong token", "127.0.0.1:50000", "guess", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/desktop/answer", nil)
+		r.RemoteAddr = tt.remoteAddr
+		if tt.token != "" {
+			r.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		if got := desktopAuthorized(r); got != tt.want {
+			t.Errorf("%s: desktopAuthorized = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
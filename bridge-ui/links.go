@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui/links"
+)
+
+var (
+	linkSignerOnce sync.Once
+	signer         *links.Signer
+)
+
+// linkSigner signs response links with the key next to bridge-config.json.
+// The UI and --mcp processes share it so either can verify the other's links.
+func linkSigner() *links.Signer {
+	linkSignerOnce.Do(func() {
+		signer = &links.Signer{Path: filepath.Join(filepath.Dir(getConfigPath()), links.KeyFileName)}
+		if _, err := signer.Key(); err != nil {
+			fmt.Fprintf(os.Stderr, "[BRIDGE] ⚠️ Could not save link key, links will stop working on restart: %v\n", err)
+		}
+	})
+	return signer
+}
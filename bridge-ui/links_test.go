@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui"
+)
+
+// TestRespondOptionLink checks that an option link answers only from the
+// confirm page, and only once
+func TestRespondOptionLink(t *testing.T) {
+	respond := func(b *BridgeService, method, link string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		b.handleRespond(w, httptest.NewRequest(method, link, nil))
+		return w
+	}
+	newBridge := func(usedLinks ...string) (*BridgeService, chan string) {
+		b := NewBridgeService()
+		ch := make(chan string, 1)
+		b.pendingRequests["req1"] = ch
+		b.requestData["req1"] = RequestData{Question: "Deploy?", Options: []string{"Yes", "No"}, UsedLinks: usedLinks}
+		return b, ch
+	}
+	link := func(answer string) string {
+		return buildResponseURL("", "req1", answer, time.Now().Add(time.Hour))
+	}
+
+	// A link preview fetching the link only gets the confirm page
+	b, ch := newBridge()
+	yes := link("Yes")
+	if w := respond(b, "GET", yes); w.Code != 200 || !strings.Contains(w.Body.String(), `method="POST"`) || len(ch) != 0 {
+		t.Fatalf("GET answered or showed no confirm page: %d %s", w.Code, w.Body.String())
+	}
+	if w := respond(b, "POST", yes); w.Code != 200 || len(ch) != 1 {
+		t.Fatalf("confirming did not answer: %d %s", w.Code, w.Body.String())
+	}
+	if used := b.requestData["req1"].UsedLinks; len(used) != 1 {
+		t.Errorf("the link was not recorded as used: %v", used)
+	}
+
+	// A link that answered before a restart stays spent on the restored request
+	b, ch = newBridge(b.requestData["req1"].UsedLinks...)
+	if w := respond(b, "POST", yes); w.Code != 409 || len(ch) != 0 {
+		t.Errorf("replayed link: %d, answered = %v", w.Code, len(ch) == 1)
+	}
+
+	// A refused answer leaves the link usable
+	b, ch = newBridge()
+	b.requestData["req1"] = RequestData{Question: "Replicas?", Schema: responseui.Schema{Field: responseui.Field{Type: responseui.TypeNumber}}}
+	bad := link("many")
+	if w := respond(b, "POST", bad); w.Code != 400 || len(ch) != 0 {
+		t.Errorf("invalid answer: %d, answered = %v", w.Code, len(ch) == 1)
+	}
+	if used := b.requestData["req1"].UsedLinks; len(used) != 0 {
+		t.Errorf("a refused answer spent the link: %v", used)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"embed"
 	"flag"
 	"fmt"
+	"os"
+	"strings"
+	"sync"
 
 	"github.com/getlantern/systray"
 	"github.com/wailsapp/wails/v2"
@@ -21,11 +24,31 @@ var icon []byte
 
 var app *App
 
+// maxTrayQuestions is how many open questions the tray menu lists
+const maxTrayQuestions = 5
+
+// Tray entries for open questions, and the request ID each one shows
+var (
+	trayQuestionsMenu *systray.MenuItem
+	trayQuestions     []*systray.MenuItem
+	trayQuestionIDs   [maxTrayQuestions]string
+	trayMu            sync.Mutex
+)
+
 func main() {
 	// Parse command-line flags
 	mcpMode := flag.Bool("mcp", false, "Run as MCP stdio server (no UI)")
 	flag.Parse()
 
+	// `audit` subcommand prints or exports the approval audit log
+	if flag.Arg(0) == "audit" {
+		if err := runAuditCommand(flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "audit:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// If --mcp flag is set, run MCP server instead of UI
 	if *mcpMode {
 		runMCPServer()
@@ -80,6 +103,7 @@ func onSystrayReady() {
 
 	// Menu Items
 	mShow := systray.AddMenuItem("Show Momentum", "Show the main window")
+	addTrayQuestions()
 	mCheckUpdate := systray.AddMenuItem("Check for Updates", "Check if a new version is available")
 	systray.AddSeparator()
 	mQuit := systray.AddMenuItem("Quit", "Quit the application")
@@ -138,6 +162,68 @@ func onSystrayReady() {
 	}()
 }
 
+// addTrayQuestions adds the "Open questions" submenu with hidden slots that
+// refreshTrayQuestions fills in
+func addTrayQuestions() {
+	trayMu.Lock()
+	defer trayMu.Unlock()
+
+	trayQuestionsMenu = systray.AddMenuItem("No open questions", "Questions waiting for your answer")
+	trayQuestionsMenu.Disable()
+	for i := 0; i < maxTrayQuestions; i++ {
+		item := trayQuestionsMenu.AddSubMenuItem("", "")
+		item.Hide()
+		trayQuestions = append(trayQuestions, item)
+
+		go func(i int, item *systray.MenuItem) {
+			for range item.ClickedCh {
+				trayMu.Lock()
+				id := trayQuestionIDs[i]
+				trayMu.Unlock()
+				if app != nil && id != "" {
+					app.ShowRequest(id)
+				}
+			}
+		}(i, item)
+	}
+}
+
+// refreshTrayQuestions lists the open questions in the tray menu
+func refreshTrayQuestions(pending []PendingRequest) {
+	trayMu.Lock()
+	defer trayMu.Unlock()
+	if trayQuestionsMenu == nil {
+		return // Tray not ready yet
+	}
+
+	title := "No open questions"
+	switch {
+	case len(pending) == 1:
+		title = "1 open question"
+	case len(pending) > 1:
+		title = fmt.Sprintf("%d open questions", len(pending))
+	}
+	trayQuestionsMenu.SetTitle(title)
+	if len(pending) > 0 {
+		trayQuestionsMenu.Enable()
+	} else {
+		trayQuestionsMenu.Disable()
+	}
+	systray.SetTooltip("Momentum - " + strings.ToLower(title))
+
+	for i, item := range trayQuestions {
+		if i >= len(pending) {
+			trayQuestionIDs[i] = ""
+			item.Hide()
+			continue
+		}
+		trayQuestionIDs[i] = pending[i].ID
+		item.SetTitle(truncateText(pending[i].Question, 60))
+		item.SetTooltip(pending[i].Question)
+		item.Show()
+	}
+}
+
 func onSystrayExit() {
 	// Cleanup
 }
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// matrixKeycaps are the reaction keys that select an option by number
+var matrixKeycaps = []string{"1️⃣", "2️⃣", "3️⃣", "4️⃣", "5️⃣", "6️⃣", "7️⃣", "8️⃣", "9️⃣", "🔟"}
+
+// matrixClient uses a timeout longer than the /sync long-poll
+var matrixClient = &http.Client{Timeout: 60 * time.Second}
+
+type matrixEvent struct {
+	Type    string          `json:"type"`
+	Sender  string          `json:"sender"`
+	EventID string          `json:"event_id"`
+	Content json.RawMessage `json:"content"`
+}
+
+type matrixSyncResponse struct {
+	NextBatch string `json:"next_batch"`
+	Rooms     struct {
+		Join map[string]struct {
+			Timeline struct {
+				Events []matrixEvent `json:"events"`
+			} `json:"timeline"`
+		} `json:"join"`
+	} `json:"rooms"`
+}
+
+type matrixRelation struct {
+	RelType   string `json:"rel_type"`
+	EventID   string `json:"event_id"`
+	Key       string `json:"key"`
+	InReplyTo *struct {
+		EventID string `json:"event_id"`
+	} `json:"m.in_reply_to"`
+}
+
+type matrixContent struct {
+	MsgType   string          `json:"msgtype"`
+	Body      string          `json:"body"`
+	RelatesTo *matrixRelation `json:"m.relates_to"`
+}
+
+// sendMatrix posts the question to the configured room
+func (b *BridgeService) sendMatrix(bridgeCfg BridgeConfig, question string, options []string, requestID string) error {
+	cfg := bridgeCfg.Matrix
+	if cfg.Homeserver == "" || cfg.AccessToken == "" || cfg.RoomID == "" {
+		return fmt.Errorf("Matrix not configured")
+	}
+
+	b.log("📤 Sending Matrix notification...")
+
+	var text, formatted strings.Builder
+	fmt.Fprintf(&text, "🤖 Input Needed\n\n%s\n\n", question)
+	fmt.Fprintf(&formatted, "<b>🤖 Input Needed</b><br><br>%s<br><br>", strings.ReplaceAll(html.EscapeString(question), "\n", "<br>"))
+	for i, opt := range options {
+		marker := fmt.Sprintf("%d.", i+1)
+		if i < len(matrixKeycaps) {
+			marker = matrixKeycaps[i]
+		}
+		fmt.Fprintf(&text, "%s %s\n", marker, opt)
+		fmt.Fprintf(&formatted, "%s %s<br>", marker, html.EscapeString(opt))
+	}
+	text.WriteString("\nReact with a number or reply to this message with your answer.")
+	formatted.WriteString("<br><i>React with a number or reply to this message with your answer.</i>")
+
+	eventID, err := b.matrixSend(map[string]interface{}{
+		"msgtype":        "m.text",
+		"body":           text.String(),
+		"format":         "org.matrix.custom.html",
+		"formatted_body": formatted.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	b.pendingMu.Lock()
+	for id, reqID := range b.mxEvents {
+		if _, pending := b.pendingRequests[reqID]; !pending {
+			delete(b.mxEvents, id)
+		}
+	}
+	b.mxEvents[eventID] = requestID
+	b.pendingMu.Unlock()
+	return nil
+}
+
+// matrixSend sends an m.room.message event and returns its event ID
+func (b *BridgeService) matrixSend(content map[string]interface{}) (string, error) {
+	cfg := b.config().Matrix
+	txnID := fmt.Sprintf("momentum-%d", time.Now().UnixNano())
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimRight(cfg.Homeserver, "/"), url.PathEscape(cfg.RoomID), txnID)
+
+	var result struct {
+		EventID string `json:"event_id"`
+	}
+	if err := b.matrixRequest("PUT", endpoint, content, &result); err != nil {
+		return "", err
+	}
+	return result.EventID, nil
+}
+
+// matrixRequest performs an authenticated client-server API call
+func (b *BridgeService) matrixRequest(method, endpoint string, payload, result interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+b.config().Matrix.AccessToken)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := matrixClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		var apiErr struct {
+			ErrCode string `json:"errcode"`
+			Error   string `json:"error"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&apiErr)
+		return fmt.Errorf("Matrix API returned status %d: %s %s", resp.StatusCode, apiErr.ErrCode, apiErr.Error)
+	}
+	if result != nil {
+		return json.NewDecoder(resp.Body).Decode(result)
+	}
+	return nil
+}
+
+// startMatrix long-polls /sync for replies and reactions until ctx is cancelled
+func (b *BridgeService) startMatrix(ctx context.Context) {
+	cfg := b.config().Matrix
+	if cfg.Homeserver == "" || cfg.AccessToken == "" || cfg.RoomID == "" {
+		return
+	}
+	homeserver := strings.TrimRight(cfg.Homeserver, "/")
+
+	var whoami struct {
+		UserID string `json:"user_id"`
+	}
+	if err := b.matrixRequest("GET", homeserver+"/_matrix/client/v3/account/whoami", nil, &whoami); err != nil {
+		b.log(fmt.Sprintf("❌ Matrix Error: %v", err))
+		return
+	}
+
+	filter, _ := json.Marshal(map[string]interface{}{
+		"room": map[string]interface{}{
+			"rooms":    []string{cfg.RoomID},
+			"timeline": map[string]interface{}{"types": []string{"m.room.message", "m.reaction"}},
+		},
+		"presence":     map[string]interface{}{"types": []string{}},
+		"account_data": map[string]interface{}{"types": []string{}},
+	})
+
+	b.log(fmt.Sprintf("🟩 Matrix listening as %s", whoami.UserID))
+	if strings.TrimSpace(cfg.AllowedUsers) == "" {
+		b.log("⚠️ Matrix allowed users not set - only routed approvers can answer from Matrix")
+	}
+
+	since := ""
+	for ctx.Err() == nil {
+		params := url.Values{}
+		params.Set("filter", string(filter))
+		if since == "" {
+			// First sync only establishes a position; older messages are ignored
+			params.Set("timeout", "0")
+		} else {
+			params.Set("since", since)
+			params.Set("timeout", "30000")
+		}
+
+		var sync matrixSyncResponse
+		if err := b.matrixRequest("GET", homeserver+"/_matrix/client/v3/sync?"+params.Encode(), nil, &sync); err != nil {
+			if ctx.Err() == nil {
+				b.log(fmt.Sprintf("⚠️ Matrix sync failed: %v", err))
+				time.Sleep(5 * time.Second)
+			}
+			continue
+		}
+
+		if since != "" {
+			for _, event := range sync.Rooms.Join[cfg.RoomID].Timeline.Events {
+				if event.Sender != whoami.UserID {
+					b.handleMatrixEvent(event)
+				}
+			}
+		}
+		since = sync.NextBatch
+	}
+}
+
+// handleMatrixEvent resolves a request from a reaction or a reply to its notification
+func (b *BridgeService) handleMatrixEvent(event matrixEvent) {
+	cfg := b.config()
+	approver := cfg.approverFor("matrix", event.Sender)
+	if !matrixUserAllowed(cfg.Matrix.AllowedUsers, event.Sender) && approver == "" {
+		b.log(fmt.Sprintf("🚫 Ignored Matrix event from %s: not in allowed users", event.Sender))
+		return
+	}
+
+	var content matrixContent
+	if err := json.Unmarshal(event.Content, &content); err != nil || content.RelatesTo == nil {
+		return
+	}
+
+	var targetID, answer string
+	switch {
+	case event.Type == "m.reaction" && content.RelatesTo.RelType == "m.annotation":
+		targetID = content.RelatesTo.EventID
+		answer = content.RelatesTo.Key
+	case event.Type == "m.room.message" && content.RelatesTo.InReplyTo != nil:
+		targetID = content.RelatesTo.InReplyTo.EventID
+		answer = stripMatrixReplyFallback(content.Body)
+	default:
+		return
+	}
+
+	b.pendingMu.Lock()
+	requestID, known := b.mxEvents[targetID]
+	data := b.requestData[requestID]
+	b.pendingMu.Unlock()
+	if !known || answer == "" {
+		return
+	}
+
+	// Keycap reactions and bare numbers select an option
+	for i, key := range matrixKeycaps {
+		if answer == key {
+			answer = strconv.Itoa(i + 1)
+		}
+	}
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(data.Options) {
+		answer = data.Options[n-1]
+	} else if event.Type == "m.reaction" {
+		return // Unrelated emoji
+	}
+
+	decided, err := b.submitMessageVote(requestID, Vote{Answer: answer, Via: "matrix", Approver: approver, User: event.Sender}, matrixMessageKey(targetID))
+	if err != nil {
+		b.log(fmt.Sprintf("🚫 Refused Matrix answer from %s to %s: %v", event.Sender, requestID, err))
+		b.replyMatrix(targetID, event.Sender+": "+refusal(err))
+		return
+	}
+
+	b.log(fmt.Sprintf("📥 Matrix response from %s: %s -> %s", event.Sender, requestID, answer))
+	confirmation := fmt.Sprintf("🗳️ Vote recorded: %s by %s. Waiting for the other approvers.", answer, event.Sender)
+	if decided {
+		b.pendingMu.Lock()
+		delete(b.mxEvents, targetID)
+		b.pendingMu.Unlock()
+		confirmation = fmt.Sprintf("✅ %s by %s at %s", answer, event.Sender, time.Now().Format("15:04"))
+	}
+	b.replyMatrix(targetID, confirmation)
+}
+
+// closeMatrixEvent replies to a notification after another channel answered it
+func (b *BridgeService) closeMatrixEvent(eventID, outcome string) {
+	b.replyMatrix(eventID, outcome)
+}
+
+// replyMatrix posts a notice in reply to a notification
+func (b *BridgeService) replyMatrix(eventID, text string) {
+	if _, err := b.matrixSend(map[string]interface{}{
+		"msgtype":      "m.notice",
+		"body":         text,
+		"m.relates_to": map[string]interface{}{"m.in_reply_to": map[string]string{"event_id": eventID}},
+	}); err != nil {
+		b.log(fmt.Sprintf("⚠️ Matrix confirmation failed: %v", err))
+	}
+}
+
+// matrixUserAllowed checks the sender against the comma-separated allow list.
+// An empty list lets nobody but routed approvers answer.
+func matrixUserAllowed(allowed, sender string) bool {
+	if sender == "" {
+		return false
+	}
+	for _, user := range strings.Split(allowed, ",") {
+		if strings.TrimSpace(user) == sender {
+			return true
+		}
+	}
+	return false
+}
+
+// stripMatrixReplyFallback removes the quoted "> <@user> ..." block clients prepend to replies
+func stripMatrixReplyFallback(body string) string {
+	lines := strings.Split(body, "\n")
+	i := 0
+	for i < len(lines) && strings.HasPrefix(lines[i], ">") {
+		i++
+	}
+	return strings.TrimSpace(strings.Join(lines[i:], "\n"))
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMatrixUserAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed string
+		sender  string
+		want    bool
+	}{
+		{"listed", "@me:example.org", "@me:example.org", true},
+		{"listed among others", "@a:example.org, @me:example.org", "@me:example.org", true},
+		{"not listed", "@a:example.org", "@me:example.org", false},
+		{"empty list", "", "@me:example.org", false},
+		{"blank list", " , ", "@me:example.org", false},
+		{"no sender", "@a:example.org,", "", false},
+	}
+	for _, tt := range tests {
+		if got := matrixUserAllowed(tt.allowed, tt.sender); got != tt.want {
+			t.Errorf("%s: matrixUserAllowed = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestMatrixEventFromStranger checks that an empty allow list still lets routed approvers answer
+func TestMatrixEventFromStranger(t *testing.T) {
+	b := NewBridgeService()
+	b.cfg.Approvers = []Approver{{Contact: Contact{Name: "ana", MatrixUser: "@ana:example.org"}, Channels: []string{"matrix"}}}
+	ch := make(chan string, 1)
+	b.pendingRequests["m1"] = ch
+	b.requestData["m1"] = RequestData{Question: "Deploy?", Options: []string{"Yes", "No"}}
+	b.mxEvents["$notice"] = "m1"
+
+	reaction := func(sender string) matrixEvent {
+		return matrixEvent{Type: "m.reaction", Sender: sender, Content: []byte(`{"m.relates_to":{"rel_type":"m.annotation","event_id":"$notice","key":"1️⃣"}}`)}
+	}
+	b.handleMatrixEvent(reaction("@stranger:example.org"))
+	if len(ch) != 0 {
+		t.Fatal("a sender outside the allow list answered")
+	}
+	b.handleMatrixEvent(reaction("@ana:example.org"))
+	if len(ch) != 1 || <-ch != "Yes" {
+		t.Error("the routed approver could not answer")
+	}
+}
+
+// TestMatrixEventRefused checks that a refused answer gets a reply saying why
+func TestMatrixEventRefused(t *testing.T) {
+	notices := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var notice struct {
+			Body string `json:"body"`
+		}
+		json.NewDecoder(r.Body).Decode(&notice)
+		notices <- notice.Body
+		w.Write([]byte(`{"event_id":"$reply"}`))
+	}))
+	defer server.Close()
+
+	b := NewBridgeService()
+	b.cfg.Matrix = MatrixConfig{Homeserver: server.URL, AccessToken: "tok", RoomID: "!room:example.org", AllowedUsers: "@me:example.org"}
+	ch := make(chan string, 1)
+	b.pendingRequests["m1"] = ch
+	b.requestData["m1"] = RequestData{Question: "Drop the table?", Options: []string{"Yes", "No"}, SecondFactor: true}
+	b.mxEvents["$notice"] = "m1"
+
+	b.handleMatrixEvent(matrixEvent{Type: "m.reaction", Sender: "@me:example.org", Content: []byte(`{"m.relates_to":{"rel_type":"m.annotation","event_id":"$notice","key":"1️⃣"}}`)})
+	if want := "@me:example.org: " + refusal(errSecondFactorRequired); <-notices != want || len(ch) != 0 {
+		t.Errorf("want a reply %q and no answer", want)
+	}
+	if _, kept := b.mxEvents["$notice"]; !kept {
+		t.Error("the notification stopped taking answers")
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -31,6 +30,12 @@ func runMCPServer() {
 		fmt.Fprintf(os.Stderr, "[BRIDGE] ❌ Config load error: %v\n", err)
 		os.Exit(1)
 	}
+	if err := ensureAskToken(configPath, &cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "[BRIDGE] ⚠️ Could not save /ask token: %v\n", err)
+	}
+	if err := hashStoredPIN(configPath, &cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "[BRIDGE] ⚠️ Could not save PIN: %v\n", err)
+	}
 
 	// Create bridge service
 	mcpBridge = NewBridgeService()
@@ -54,13 +59,10 @@ func runMCPServer() {
 func startMCPStdioServer() {
 	s := server.NewMCPServer("Remote Bridge", "2.1.0", server.WithToolCapabilities(true))
 
-	askTool := mcp.NewTool("ask_remote_human",
-		mcp.WithDescription("Ask the user a question via Telegram (interactive HTML form)"),
-		mcp.WithString("question", mcp.Required()),
-		mcp.WithArray("options", mcp.Required()),
-	)
+	askTool := newAskTool("Ask the user a question via Telegram (interactive HTML form)")
 
 	s.AddTool(askTool, handleAskHuman)
+	s.AddTool(newPermissionTool(), handleRequestPermission)
 
 	fmt.Fprintln(os.Stderr, "[BRIDGE] 📡 MCP Server listening on Stdio...")
 	if err := server.ServeStdio(s); err != nil {
@@ -71,22 +73,13 @@ func startMCPStdioServer() {
 
 // handleAskHuman implements the ask_remote_human tool
 func handleAskHuman(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	question, _ := request.RequireString("question")
-	args := request.GetArguments()
-	optionsSlice, _ := args["options"].([]interface{})
-
-	var options []string
-	for _, o := range optionsSlice {
-		if s, ok := o.(string); ok {
-			options = append(options, s)
-		}
+	req, err := askArguments(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	fmt.Fprintf(os.Stderr, "[BRIDGE] 🔔 Question: %s\n", question)
-	fmt.Fprintf(os.Stderr, "[BRIDGE] 📋 Options: %v\n", options)
-
-	// Generate request ID
-	requestID := uuid.New().String()[:8]
+	fmt.Fprintf(os.Stderr, "[BRIDGE] 🔔 Question: %s\n", req.Question)
+	fmt.Fprintf(os.Stderr, "[BRIDGE] 📋 Options: %v\n", req.Options)
 
 	// Use the bridge service to handle the request
 	mcpMutex.Lock()
@@ -97,46 +90,12 @@ func handleAskHuman(ctx context.Context, request mcp.CallToolRequest) (*mcp.Call
 	bridge := mcpBridge
 	mcpMutex.Unlock()
 
-	// Create response channel
-	responseChan := make(chan string, 1)
-	bridge.pendingMu.Lock()
-	bridge.pendingRequests[requestID] = responseChan
-	bridge.requestData[requestID] = RequestData{Question: question, Options: options}
-	bridge.pendingMu.Unlock()
-
-	// Send notifications
-	bridge.sendNotification(question, options, requestID)
-
 	// Wait for response (with timeout)
-	select {
-	case resp := <-responseChan:
-		// Cleanup
-		bridge.pendingMu.Lock()
-		delete(bridge.pendingRequests, requestID)
-		delete(bridge.requestData, requestID)
-		bridge.pendingMu.Unlock()
-
-		fmt.Fprintf(os.Stderr, "[BRIDGE] ✅ Response: %s\n", resp)
-		return mcp.NewToolResultText(resp), nil
-
-	case <-time.After(15 * time.Minute):
-		// Cleanup
-		bridge.pendingMu.Lock()
-		delete(bridge.pendingRequests, requestID)
-		delete(bridge.requestData, requestID)
-		bridge.pendingMu.Unlock()
-
-		return mcp.NewToolResultError("Request timed out after 15 minutes"), nil
-
-	case <-ctx.Done():
-		// Cleanup
-		bridge.pendingMu.Lock()
-		delete(bridge.pendingRequests, requestID)
-		delete(bridge.requestData, requestID)
-		bridge.pendingMu.Unlock()
-
-		return mcp.NewToolResultError("Request cancelled"), nil
+	result, err := bridge.ask(ctx, req)
+	if err == nil {
+		fmt.Fprintf(os.Stderr, "[BRIDGE] ✅ Response (%s): %s\n", result.Source, result.Answer)
 	}
+	return askToolResult(result, err), nil
 }
 
 // getConfigPath returns the path to bridge-config.json
@@ -164,3 +123,37 @@ func loadConfig(path string) (BridgeConfig, error) {
 
 	return cfg, nil
 }
+
+// saveConfig writes the bridge configuration readable by the user only, since it
+// holds the /ask token, second factor and channel credentials. Files saved by
+// older versions are tightened as well.
+func saveConfig(path string, cfg BridgeConfig) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0600)
+}
+
+// handleRequestPermission implements the request_permission tool
+func handleRequestPermission(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	req := permissionArguments(request)
+	fmt.Fprintf(os.Stderr, "[BRIDGE] 🛡️ Permission requested: %s\n", req.Action)
+
+	mcpMutex.Lock()
+	if mcpBridge == nil {
+		mcpMutex.Unlock()
+		return mcp.NewToolResultError("Bridge not initialized"), nil
+	}
+	bridge := mcpBridge
+	mcpMutex.Unlock()
+
+	result, err := bridge.requestPermission(ctx, req)
+	if err == nil {
+		fmt.Fprintf(os.Stderr, "[BRIDGE] ✅ Permission %s: approved=%t\n", result.RequestID, result.Approved)
+	}
+	return permissionToolResult(result, err), nil
+}
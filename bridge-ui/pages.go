@@ -0,0 +1,19 @@
+package main
+
+import "github.com/HarshalPatel1972/remote-bridge/responseui"
+
+// pages renders the pages behind response links, with the brand, language and
+// theme chosen in Settings
+func (b *BridgeService) pages() responseui.Pages {
+	return responseui.Pages{Config: b.cfg.ResponsePage, Action: "/respond", Field: "answer"}
+}
+
+// page is what the response page shows of a request
+func (d RequestData) page() responseui.Request {
+	return responseui.Request{Question: d.Question, Options: d.Options, Context: d.Context, Schema: d.Schema}
+}
+
+// ResponseLanguages lists the languages the response pages are translated into
+func (a *App) ResponseLanguages() []string {
+	return responseui.Languages()
+}
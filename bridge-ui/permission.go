@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// PermissionRequest is one request_permission call, whichever transport it arrived on
+type PermissionRequest struct {
+	Action        string   // What the agent wants to do, e.g. "Delete the build cache"
+	Command       string   // Command line it would run
+	Paths         []string // Files or directories the action touches
+	Risk          string   // "low", "medium" or "high", as for ask_remote_human
+	Justification string   // Why the action is needed; Markdown
+	Category      string
+	Workspace     string
+	Timeout       time.Duration
+}
+
+// PermissionResult is the typed decision returned to the agent
+type PermissionResult = responseui.PermissionResult
+
+// newPermissionTool declares request_permission for every MCP mode
+func newPermissionTool() mcp.Tool {
+	return responseui.NewPermissionTool(
+		mcp.WithString("category", mcp.Description("Optional category (e.g. \"deploy\") used to route the request to approvers")),
+	)
+}
+
+// permissionArguments reads the request_permission tool arguments
+func permissionArguments(request mcp.CallToolRequest) PermissionRequest {
+	var req PermissionRequest
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		req.Action, _ = args["action"].(string)
+		req.Command, _ = args["command"].(string)
+		req.Risk, _ = args["risk_level"].(string)
+		req.Justification, _ = args["justification"].(string)
+		req.Category, _ = args["category"].(string)
+		if seconds, ok := args["timeout_seconds"].(float64); ok {
+			req.Timeout = time.Duration(seconds * float64(time.Second))
+		}
+		if paths, ok := args["paths"].([]interface{}); ok {
+			for _, p := range paths {
+				if str, ok := p.(string); ok {
+					req.Paths = append(req.Paths, str)
+				}
+			}
+		}
+	}
+	return req
+}
+
+// askRequest turns the permission request into an approve/deny question, so
+// it is routed, escalated, stored and audited like any other
+func (p PermissionRequest) askRequest() AskRequest {
+	return AskRequest{
+		Question:  "Permission requested: " + strings.TrimSpace(p.Action),
+		Options:   append([]string(nil), responseui.PermissionOptions...),
+		Category:  p.Category,
+		Workspace: p.Workspace,
+		Timeout:   p.Timeout,
+		Risk:      p.Risk,
+		Context:   responseui.Context{Details: responseui.PermissionDetails(p.Justification, p.Paths), Command: p.Command},
+		Schema:    responseui.Schema{Field: responseui.Field{Type: responseui.TypePermission}},
+	}
+}
+
+// requestPermission asks for approval and waits for the decision. There is no
+// default option: a request nobody answers is denied.
+func (b *BridgeService) requestPermission(ctx context.Context, req PermissionRequest) (PermissionResult, error) {
+	if strings.TrimSpace(req.Action) == "" {
+		return PermissionResult{}, fmt.Errorf("action is required")
+	}
+	result, err := b.ask(ctx, req.askRequest())
+	if err != nil {
+		return PermissionResult{}, err
+	}
+	return responseui.NewPermissionResult(result.RequestID, result.Value, result.TimedOut, result.Votes), nil
+}
+
+// permissionToolResult converts the outcome of requestPermission into an MCP tool result
+func permissionToolResult(result PermissionResult, err error) *mcp.CallToolResult {
+	if err == context.Canceled {
+		return mcp.NewToolResultError("Request cancelled")
+	}
+	if err != nil {
+		return mcp.NewToolResultError(err.Error())
+	}
+	return mcp.NewToolResultStructured(result, result.Text())
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui/secondfactor"
+)
+
+// SecondFactorConfig guards answers to high-risk questions on the response page
+type SecondFactorConfig struct {
+	PIN        string `json:"pin,omitempty"`        // Saved as a salted hash, see secondfactor.HashPIN
+	TOTPSecret string `json:"totpSecret,omitempty"` // Base32, enrolled from Settings
+}
+
+// TOTPEnrollment is a new authenticator secret waiting to be confirmed
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"` // otpauth:// link shown as a QR code
+}
+
+// enabled reports whether a PIN or TOTP secret is set up
+func (c SecondFactorConfig) enabled() bool {
+	return c.PIN != "" || c.TOTPSecret != ""
+}
+
+// verify returns "pin" or "totp" for a code that matches, or "" if none does.
+// An authenticator code is spent in limiter, so it can't be given twice.
+func (c SecondFactorConfig) verify(code string, now time.Time, limiter *secondfactor.Limiter) string {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return ""
+	}
+	if c.PIN != "" && secondfactor.VerifyPIN(c.PIN, code) {
+		return "pin"
+	}
+	if c.TOTPSecret != "" && limiter.SpendTOTP(c.TOTPSecret, code, now) {
+		return "totp"
+	}
+	return ""
+}
+
+// hashPIN replaces a PIN typed in Settings, or saved in plain text by an older
+// version, with its hash; false if there was nothing to do
+func (c *SecondFactorConfig) hashPIN() bool {
+	if c.PIN == "" || secondfactor.IsPINHash(c.PIN) {
+		return false
+	}
+	c.PIN = secondfactor.HashPIN(c.PIN)
+	return true
+}
+
+// hashStoredPIN rewrites bridge-config.json if it still holds a plain PIN
+func hashStoredPIN(path string, cfg *BridgeConfig) error {
+	if !cfg.SecondFactor.hashPIN() {
+		return nil
+	}
+	return saveConfig(path, *cfg)
+}
+
+// upgradePIN replaces a PIN hash saved by an older version now that a correct
+// PIN shows what it hashes
+func (b *BridgeService) upgradePIN(old, pin string) {
+	path := getConfigPath()
+	cfg, err := loadConfig(path)
+	if err != nil || cfg.SecondFactor.PIN != old {
+		return
+	}
+	cfg.SecondFactor.PIN = secondfactor.HashPIN(pin)
+	if err := saveConfig(path, cfg); err != nil {
+		b.log(fmt.Sprintf("⚠️ Could not upgrade the saved PIN hash: %v", err))
+		return
+	}
+	b.mu.Lock()
+	if b.cfg.SecondFactor.PIN == old {
+		b.cfg.SecondFactor.PIN = cfg.SecondFactor.PIN
+	}
+	b.mu.Unlock()
+	b.log("🔐 Upgraded the saved PIN hash")
+}
+
+// factorLimiter counts wrong codes and used authenticator codes in a file next
+// to the config, shared with the other bridge processes
+func factorLimiter() *secondfactor.Limiter {
+	return &secondfactor.Limiter{Path: filepath.Join(filepath.Dir(getConfigPath()), secondfactor.FileName)}
+}
+
+// NewTOTPEnrollment creates an authenticator secret for Settings to show as a QR code.
+// Nothing is saved until the user confirms a code and saves the config.
+func (a *App) NewTOTPEnrollment() TOTPEnrollment {
+	key := make([]byte, 20)
+	rand.Read(key)
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(key)
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", "Momentum")
+	params.Set("digits", fmt.Sprint(secondfactor.Digits))
+	params.Set("period", fmt.Sprint(secondfactor.Period))
+	return TOTPEnrollment{Secret: secret, URI: "otpauth://totp/Momentum:Approvals?" + params.Encode()}
+}
+
+// ConfirmTOTP checks a code from the authenticator app against a new secret
+func (a *App) ConfirmTOTP(secret, code string) bool {
+	return secondfactor.ValidTOTP(secret, strings.TrimSpace(code), time.Now())
+}
+
+// checkSecondFactor gates a web answer to a high-risk request on a PIN or TOTP code.
+// Wrong codes count per request and client address. On success it records the
+// method on vote; otherwise it has written the page to show.
+func (b *BridgeService) checkSecondFactor(w http.ResponseWriter, r *http.Request, token, requestID string, data RequestData, vote *Vote) bool {
+	now := time.Now()
+	code := r.FormValue("code")
+	source := secondfactor.Source(requestID, vote.ClientIP)
+	if vote.Answer == "" {
+		b.pages().SecondFactor(w, 200, token, data.page(), "", "")
+		return false
+	}
+	if b.factors.Locked(source, now) {
+		b.log(fmt.Sprintf("🔐 Too many wrong codes recently, refusing answer to %s from %s", requestID, vote.ClientIP))
+		b.audit("rejected", requestID, data, *vote, "second_factor_locked")
+		b.pages().Error(w, 429, "too_many_attempts_desktop")
+		return false
+	}
+	if code == "" {
+		b.pages().SecondFactor(w, 401, token, data.page(), vote.Answer, "")
+		return false
+	}
+
+	saved := b.config().SecondFactor
+	method := saved.verify(code, now, b.factors)
+	if method == "" {
+		left := b.factors.Fail(source, now)
+		b.log(fmt.Sprintf("🔐 Wrong code for %s from %s (%d tries left)", requestID, vote.ClientIP, left))
+		b.audit("rejected", requestID, data, *vote, "second_factor_failed")
+		pages := b.pages()
+		pages.SecondFactor(w, 401, token, data.page(), vote.Answer, pages.T("secondfactor.wrong_code", left))
+		return false
+	}
+	if method == "pin" && secondfactor.NeedsRehash(saved.PIN) {
+		b.upgradePIN(saved.PIN, strings.TrimSpace(code))
+	}
+	vote.SecondFactor = method
+	return true
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui/secondfactor"
+)
+
+// RFC 6238 appendix B, SHA-1 secret "12345678901234567890"
+const rfcTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestSecondFactorVerify(t *testing.T) {
+	at := time.Unix(1111111109, 0)
+	both := SecondFactorConfig{PIN: secondfactor.HashPIN("4821"), TOTPSecret: rfcTOTPSecret}
+	tests := []struct {
+		name string
+		cfg  SecondFactorConfig
+		code string
+		want string
+	}{
+		{"pin", both, "4821", "pin"},
+		{"pin with spaces", both, " 4821 ", "pin"},
+		{"totp", both, "081804", "totp"},
+		{"wrong code", both, "123456", ""},
+		{"the saved hash", both, both.PIN, ""},
+		{"empty", both, "", ""},
+		{"totp without a secret", SecondFactorConfig{PIN: both.PIN}, "081804", ""},
+		{"nothing set up", SecondFactorConfig{}, "4821", ""},
+	}
+	for _, tt := range tests {
+		if got := tt.cfg.verify(tt.code, at, &secondfactor.Limiter{}); got != tt.want {
+			t.Errorf("%s: verify = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHashPIN(t *testing.T) {
+	c := SecondFactorConfig{PIN: "4821"}
+	if !c.hashPIN() || !secondfactor.IsPINHash(c.PIN) {
+		t.Fatalf("plain PIN not hashed: %q", c.PIN)
+	}
+	hashed := c.PIN
+	if c.hashPIN() || c.PIN != hashed {
+		t.Error("hashed an already hashed PIN again")
+	}
+	if c.verify("4821", time.Now(), &secondfactor.Limiter{}) != "pin" {
+		t.Error("the hashed PIN no longer verifies")
+	}
+}
+
+func TestSecondFactorTOTPReplay(t *testing.T) {
+	at := time.Unix(1111111109, 0)
+	c := SecondFactorConfig{TOTPSecret: rfcTOTPSecret}
+	limiter := &secondfactor.Limiter{}
+	if got := c.verify("081804", at, limiter); got != "totp" {
+		t.Fatalf("first use: %q", got)
+	}
+	if got := c.verify("081804", at.Add(10*time.Second), limiter); got != "" {
+		t.Errorf("replayed code: %q", got)
+	}
+}
+
+// TestCheckSecondFactor checks that wrong codes lock out one request from one
+// address, and leave other requests and addresses alone
+func TestCheckSecondFactor(t *testing.T) {
+	b := NewBridgeService()
+	b.cfg.SecondFactor = SecondFactorConfig{PIN: secondfactor.HashPIN("4821")}
+	b.factors = &secondfactor.Limiter{}
+	data := RequestData{Question: "Drop the table?", Options: []string{"Yes", "No"}, SecondFactor: true}
+
+	check := func(requestID, client, code string) (int, string) {
+		form := url.Values{"answer": {"Yes"}, "code": {code}}
+		r := httptest.NewRequest("POST", "/respond", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		vote := Vote{Answer: "Yes", Via: "web", ClientIP: client}
+		b.checkSecondFactor(w, r, "token", requestID, data, &vote)
+		return w.Code, vote.SecondFactor
+	}
+
+	if code, method := check("req1", "203.0.113.7", "4821"); code != 200 || method != "pin" {
+		t.Fatalf("right PIN: %d %q", code, method)
+	}
+	for i := 0; i < secondfactor.MaxFailures; i++ {
+		if code, _ := check("req1", "203.0.113.7", "0000"); code != 401 {
+			t.Fatalf("wrong PIN %d: %d", i+1, code)
+		}
+	}
+	tests := []struct {
+		requestID, client string
+		want              int
+	}{
+		{"req1", "203.0.113.7", 429},
+		{"req1", "198.51.100.2", 200},
+		{"req2", "203.0.113.7", 200},
+	}
+	for _, tt := range tests {
+		if code, _ := check(tt.requestID, tt.client, "4821"); code != tt.want {
+			t.Errorf("%s from %s after the lockout: %d, want %d", tt.requestID, tt.client, code, tt.want)
+		}
+	}
+}
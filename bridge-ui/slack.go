@@ -0,0 +1,362 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultSlackAPIBase = "https://slack.com/api"
+
+// slackClient is shared by chat.postMessage and response_url updates
+var slackClient = &http.Client{Timeout: 15 * time.Second}
+
+// slackBlock is a minimal Block Kit block (section, actions, context)
+type slackBlock struct {
+	Type     string        `json:"type"`
+	BlockID  string        `json:"block_id,omitempty"`
+	Text     *slackText    `json:"text,omitempty"`
+	Elements []interface{} `json:"elements,omitempty"` // slackButton or slackText
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackButton is an interactive element in an actions block
+type slackButton struct {
+	Type     string     `json:"type"`
+	Text     *slackText `json:"text,omitempty"`
+	ActionID string     `json:"action_id,omitempty"`
+	Value    string     `json:"value,omitempty"`
+	URL      string     `json:"url,omitempty"`
+}
+
+// slackMessageRef identifies a posted message for chat.update
+type slackMessageRef struct {
+	Channel string `json:"channel"` // Channel ID as returned by chat.postMessage
+	TS      string `json:"ts"`
+}
+
+type slackInteraction struct {
+	Type string `json:"type"`
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+	Container struct {
+		ChannelID string `json:"channel_id"`
+		MessageTS string `json:"message_ts"`
+	} `json:"container"`
+	ResponseURL string `json:"response_url"`
+}
+
+// sendSlack posts a Block Kit message with one button per option
+func (b *BridgeService) sendSlack(bridgeCfg BridgeConfig, question string, options []string, requestID string) error {
+	cfg := bridgeCfg.Slack
+	if cfg.BotToken == "" || cfg.Channel == "" {
+		return fmt.Errorf("Slack not configured")
+	}
+
+	b.log("📤 Sending Slack notification...")
+
+	var buttons []interface{}
+	for i, opt := range options {
+		buttons = append(buttons, slackButton{
+			Type:     "button",
+			Text:     &slackText{Type: "plain_text", Text: truncateText(opt, 75)},
+			ActionID: fmt.Sprintf("momentum_option_%d", i),
+			Value:    fmt.Sprintf("%s:%d", requestID, i),
+		})
+	}
+	if publicURL := b.getPublicURL(); strings.HasPrefix(publicURL, "https://") {
+		buttons = append(buttons, slackButton{
+			Type:     "button",
+			Text:     &slackText{Type: "plain_text", Text: "✏️ Custom answer"},
+			ActionID: "momentum_open",
+			URL:      buildResponseURL(publicURL, requestID, "", b.requestDeadline(requestID)),
+		})
+	}
+
+	blocks := slackQuestionBlocks(question)
+	// Slack allows at most 25 elements per actions block
+	for i := 0; i < len(buttons); i += 25 {
+		end := i + 25
+		if end > len(buttons) {
+			end = len(buttons)
+		}
+		blocks = append(blocks, slackBlock{Type: "actions", BlockID: fmt.Sprintf("momentum_%s_%d", requestID, i), Elements: buttons[i:end]})
+	}
+
+	payload := map[string]interface{}{
+		"channel": cfg.Channel,
+		"text":    "🤖 Input Needed: " + question, // Notification fallback
+		"blocks":  blocks,
+	}
+
+	var posted slackMessageRef
+	if err := slackAPI(cfg, "chat.postMessage", payload, &posted); err != nil {
+		return err
+	}
+
+	b.pendingMu.Lock()
+	for id := range b.slackMessages {
+		if _, pending := b.pendingRequests[id]; !pending {
+			delete(b.slackMessages, id)
+		}
+	}
+	b.slackMessages[requestID] = append(b.slackMessages[requestID], posted)
+	b.pendingMu.Unlock()
+	return nil
+}
+
+// slackQuestionBlocks renders the header and question shared by new and answered messages
+func slackQuestionBlocks(question string) []slackBlock {
+	return []slackBlock{
+		{Type: "section", Text: &slackText{Type: "mrkdwn", Text: "*🤖 Input Needed*"}},
+		{Type: "section", Text: &slackText{Type: "mrkdwn", Text: truncateText(question, 3000)}},
+	}
+}
+
+// slackAPI calls a Slack Web API method and checks the "ok" envelope.
+// The reply is decoded into result when it is non-nil.
+func slackAPI(cfg SlackConfig, method string, payload, result interface{}) error {
+	base := strings.TrimRight(cfg.APIBaseURL, "/")
+	if base == "" {
+		base = defaultSlackAPIBase
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", base+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+cfg.BotToken)
+
+	resp, err := slackClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	var envelope struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fmt.Errorf("Slack API returned status %d", resp.StatusCode)
+	}
+	if !envelope.OK {
+		return fmt.Errorf("Slack API error: %s", envelope.Error)
+	}
+	if result != nil {
+		return json.Unmarshal(data, result)
+	}
+	return nil
+}
+
+// handleSlackInteraction is the Slack app's Interactivity Request URL
+func (b *BridgeService) handleSlackInteraction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "POST required", 405)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "Bad request", 400)
+		return
+	}
+
+	cfg := b.config()
+	if !validSlackSignature(cfg.Slack.SigningSecret, r.Header.Get("X-Slack-Request-Timestamp"), r.Header.Get("X-Slack-Signature"), body, time.Now()) {
+		b.log("🚫 Rejected Slack interaction with invalid signature")
+		http.Error(w, "Invalid signature", 401)
+		return
+	}
+
+	// Restore the body so ParseForm can read it
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", 400)
+		return
+	}
+
+	var interaction slackInteraction
+	if err := json.Unmarshal([]byte(r.PostForm.Get("payload")), &interaction); err != nil {
+		http.Error(w, "Invalid payload", 400)
+		return
+	}
+	// Acknowledge within Slack's 3 second window; the message is updated via response_url
+	w.WriteHeader(200)
+
+	if interaction.Type != "block_actions" || len(interaction.Actions) == 0 {
+		return
+	}
+	action := interaction.Actions[0]
+	if !strings.HasPrefix(action.ActionID, "momentum_option_") {
+		return // Link buttons also report a click
+	}
+
+	parts := strings.SplitN(action.Value, ":", 2)
+	if len(parts) != 2 {
+		return
+	}
+	requestID := parts[0]
+	index, _ := strconv.Atoi(parts[1])
+
+	b.pendingMu.Lock()
+	data, exists := b.requestData[requestID]
+	b.pendingMu.Unlock()
+
+	// Only listed members and routed approvers may answer; anyone else in the
+	// channel is told so privately and the buttons stay in place
+	approver := cfg.approverFor("slack", interaction.User.ID)
+	if !slackUserAllowed(cfg.Slack.AllowedUsers, interaction.User.ID) && approver == "" {
+		b.log(fmt.Sprintf("🚫 Ignored Slack button from %s (%s): not in allowed users", interaction.User.Username, interaction.User.ID))
+		go b.replySlackEphemeral(interaction.ResponseURL, "⛔ You are not allowed to answer this request.")
+		return
+	}
+
+	if !exists || index < 0 || index >= len(data.Options) {
+		go b.replySlackEphemeral(interaction.ResponseURL, refusal(errRequestGone))
+		return
+	}
+	answer := data.Options[index]
+	message := slackMessageRef{Channel: interaction.Container.ChannelID, TS: interaction.Container.MessageTS}.key()
+	decided, err := b.submitMessageVote(requestID, Vote{Answer: answer, Via: "slack", Approver: approver, User: interaction.User.Username}, message)
+	if err != nil {
+		// Refusals go to the person who pressed; the message keeps its buttons
+		go b.replySlackEphemeral(interaction.ResponseURL, refusal(err))
+		return
+	}
+	b.log(fmt.Sprintf("📥 Slack response from %s: %s -> %s", interaction.User.Username, requestID, answer))
+	if !decided {
+		// Other approvers still need the buttons
+		go b.replySlackEphemeral(interaction.ResponseURL, "🗳️ Vote recorded: "+answer+". Waiting for the other approvers.")
+		return
+	}
+	outcome := fmt.Sprintf("✅ *%s* by <@%s> at %s", answer, interaction.User.ID, time.Now().Format("15:04"))
+	go b.updateSlackMessage(interaction.ResponseURL, data.Question, outcome)
+}
+
+// slackUserAllowed checks a member ID against the comma-separated allow list.
+// An empty list lets nobody but routed approvers answer.
+func slackUserAllowed(allowed, userID string) bool {
+	if userID == "" {
+		return false
+	}
+	for _, user := range strings.Split(allowed, ",") {
+		if strings.TrimSpace(user) == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// replySlackEphemeral shows text only to the person who pressed the button
+func (b *BridgeService) replySlackEphemeral(responseURL, text string) {
+	if responseURL == "" {
+		return
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"response_type":    "ephemeral",
+		"replace_original": false,
+		"text":             text,
+	})
+	resp, err := slackClient.Post(responseURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		b.log(fmt.Sprintf("⚠️ Slack reply failed: %v", err))
+		return
+	}
+	resp.Body.Close()
+}
+
+// updateSlackMessage replaces the buttons with the outcome via the interaction's response_url
+func (b *BridgeService) updateSlackMessage(responseURL, question, outcome string) {
+	if responseURL == "" {
+		return
+	}
+
+	blocks := slackQuestionBlocks(question)
+	if question == "" {
+		blocks = nil
+	}
+	blocks = append(blocks, slackBlock{Type: "context", Elements: []interface{}{slackText{Type: "mrkdwn", Text: outcome}}})
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"replace_original": true,
+		"text":             outcome,
+		"blocks":           blocks,
+	})
+	resp, err := slackClient.Post(responseURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		b.log(fmt.Sprintf("⚠️ Slack update failed: %v", err))
+		return
+	}
+	resp.Body.Close()
+}
+
+// closeSlackMessage replaces the buttons via chat.update after another channel answered
+func (b *BridgeService) closeSlackMessage(ref slackMessageRef, question, outcome string) {
+	blocks := append(slackQuestionBlocks(question), slackBlock{Type: "context", Elements: []interface{}{slackText{Type: "mrkdwn", Text: outcome}}})
+	payload := map[string]interface{}{
+		"channel": ref.Channel,
+		"ts":      ref.TS,
+		"text":    outcome,
+		"blocks":  blocks,
+	}
+	if err := slackAPI(b.config().Slack, "chat.update", payload, nil); err != nil {
+		b.log(fmt.Sprintf("⚠️ Slack update failed: %v", err))
+	}
+}
+
+// validSlackSignature checks X-Slack-Signature: v0=hex(HMAC-SHA256(secret, "v0:<ts>:<body>"))
+func validSlackSignature(signingSecret, timestamp, signature string, body []byte, now time.Time) bool {
+	if signingSecret == "" || timestamp == "" || signature == "" {
+		return false
+	}
+
+	// Reject stale requests to prevent replay
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || math.Abs(float64(now.Unix()-ts)) > 5*60 {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	fmt.Fprintf(mac, "v0:%s:", timestamp)
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// truncateText shortens s to at most n runes, marking the cut with an ellipsis
+func truncateText(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
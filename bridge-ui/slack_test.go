@@ -0,0 +1,206 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidSlackSignature(t *testing.T) {
+	// The example from Slack's "Verifying requests from Slack" guide
+	const (
+		secret    = "8f742231b10e8888abcd99yyyzzz85a5"
+		timestamp = "1531420618"
+		signature = "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503"
+		body      = "token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c"
+	)
+	sent := time.Unix(1531420618, 0)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		body      string
+		now       time.Time
+		want      bool
+	}{
+		{"valid", secret, timestamp, signature, body, sent, true},
+		{"valid within five minutes", secret, timestamp, signature, body, sent.Add(4 * time.Minute), true},
+		{"stale", secret, timestamp, signature, body, sent.Add(6 * time.Minute), false},
+		{"from the future", secret, timestamp, signature, body, sent.Add(-6 * time.Minute), false},
+		{"tampered body", secret, timestamp, signature, body + "&x=1", sent, false},
+		{"other timestamp", secret, "1531420619", signature, body, sent, false},
+		{"wrong secret", "other", timestamp, signature, body, sent, false},
+		{"no secret", "", timestamp, signature, body, sent, false},
+		{"no signature", secret, timestamp, "", body, sent, false},
+		{"missing version", secret, timestamp, strings.TrimPrefix(signature, "v0="), body, sent, false},
+		{"bad timestamp", secret, "soon", signature, body, sent, false},
+	}
+	for _, tt := range tests {
+		if got := validSlackSignature(tt.secret, tt.timestamp, tt.signature, []byte(tt.body), tt.now); got != tt.want {
+			t.Errorf("%s: validSlackSignature = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// fakeSlack stands in for the Web API and for interaction response_urls
+type fakeSlack struct {
+	*httptest.Server
+	calls   chan slackCall
+	failing string // Method that answers {"ok": false}
+}
+
+type slackCall struct {
+	Path          string
+	Authorization string
+	Payload       map[string]interface{}
+}
+
+func startFakeSlack(t *testing.T) *fakeSlack {
+	t.Helper()
+	f := &fakeSlack{calls: make(chan slackCall, 10)}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := slackCall{Path: r.URL.Path, Authorization: r.Header.Get("Authorization")}
+		json.NewDecoder(r.Body).Decode(&call.Payload)
+		f.calls <- call
+		if f.failing != "" && r.URL.Path == "/api/"+f.failing {
+			io.WriteString(w, `{"ok":false,"error":"channel_not_found"}`)
+			return
+		}
+		io.WriteString(w, `{"ok":true,"channel":"C123","ts":"1700000000.000100"}`)
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeSlack) next(t *testing.T) slackCall {
+	t.Helper()
+	select {
+	case call := <-f.calls:
+		return call
+	case <-time.After(2 * time.Second):
+		t.Fatal("no call reached the Slack stand-in")
+		return slackCall{}
+	}
+}
+
+func TestSendSlack(t *testing.T) {
+	f := startFakeSlack(t)
+	b := NewBridgeService()
+	b.cfg.Slack = SlackConfig{BotToken: "xoxb-test", Channel: "C123", APIBaseURL: f.URL + "/api/"}
+	b.publicURL = "https://bridge.example"
+	b.pendingRequests["req1"] = make(chan string, 1)
+
+	if err := b.sendSlack(b.cfg, "Deploy?", []string{"Yes", "No"}, "req1"); err != nil {
+		t.Fatalf("sendSlack: %v", err)
+	}
+	call := f.next(t)
+	if call.Path != "/api/chat.postMessage" || call.Authorization != "Bearer xoxb-test" || call.Payload["channel"] != "C123" {
+		t.Errorf("call = %s, %q, channel %v", call.Path, call.Authorization, call.Payload["channel"])
+	}
+	blocks, _ := call.Payload["blocks"].([]interface{})
+	actions, _ := blocks[len(blocks)-1].(map[string]interface{})
+	buttons, _ := actions["elements"].([]interface{})
+	if len(buttons) != 3 {
+		t.Fatalf("want two option buttons and a link, got %v", buttons)
+	}
+	if first := buttons[0].(map[string]interface{}); first["value"] != "req1:0" || first["action_id"] != "momentum_option_0" {
+		t.Errorf("first button = %v", first)
+	}
+	if link := buttons[2].(map[string]interface{}); !strings.HasPrefix(fmt.Sprint(link["url"]), "https://bridge.example/respond?t=") {
+		t.Errorf("link button = %v", link)
+	}
+	if refs := b.slackMessages["req1"]; len(refs) != 1 || refs[0] != (slackMessageRef{Channel: "C123", TS: "1700000000.000100"}) {
+		t.Errorf("message refs = %v", refs)
+	}
+
+	f.failing = "chat.postMessage"
+	if err := b.sendSlack(b.cfg, "Deploy?", []string{"Yes"}, "req1"); err == nil || !strings.Contains(err.Error(), "channel_not_found") {
+		t.Errorf("API error = %v", err)
+	}
+}
+
+// slackClick posts a signed button click from user to the interaction handler
+func slackClick(b *BridgeService, responseURL, userID, value string) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"type":         "block_actions",
+		"user":         map[string]string{"id": userID, "username": "user-" + userID},
+		"actions":      []map[string]string{{"action_id": "momentum_option_0", "value": value}},
+		"response_url": responseURL,
+	})
+	body := url.Values{"payload": {string(payload)}}.Encode()
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(b.cfg.Slack.SigningSecret))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+
+	r := httptest.NewRequest("POST", "/slack/interactions", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	r.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	w := httptest.NewRecorder()
+	b.handleSlackInteraction(w, r)
+	return w
+}
+
+func TestHandleSlackInteraction(t *testing.T) {
+	tests := []struct {
+		name         string
+		user         string
+		secondFactor bool
+		gone         bool
+		wantText     string
+		wantAnswered bool
+	}{
+		{"allowed member", "U1", false, false, "✅ *Yes* by <@U1>", true},
+		{"not on the list", "U9", false, false, "⛔ You are not allowed", false},
+		{"needs a second factor", "U1", true, false, "🔐 This request needs your PIN", false},
+		{"already answered", "U1", false, true, "⌛ This request has expired", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := startFakeSlack(t)
+			b := NewBridgeService()
+			b.cfg.Slack = SlackConfig{SigningSecret: "shh", AllowedUsers: "U0, U1", APIBaseURL: f.URL + "/api/"}
+			ch := make(chan string, 1)
+			if !tt.gone {
+				b.pendingRequests["req1"] = ch
+				b.requestData["req1"] = RequestData{Question: "Deploy?", Options: []string{"Yes", "No"}, SecondFactor: tt.secondFactor}
+			}
+
+			if w := slackClick(b, f.URL+"/respond", tt.user, "req1:0"); w.Code != 200 {
+				t.Fatalf("status %d", w.Code)
+			}
+			call := f.next(t)
+			if text := fmt.Sprint(call.Payload["text"]); call.Path != "/respond" || !strings.HasPrefix(text, tt.wantText) {
+				t.Errorf("reply = %s %q, want %q", call.Path, text, tt.wantText)
+			}
+			if ephemeral := call.Payload["response_type"] == "ephemeral"; ephemeral == tt.wantAnswered {
+				t.Errorf("ephemeral = %v for %q", ephemeral, call.Payload["text"])
+			}
+			if answered := len(ch) == 1; answered != tt.wantAnswered {
+				t.Errorf("answered = %v", answered)
+			}
+		})
+	}
+
+	b := NewBridgeService()
+	b.cfg.Slack = SlackConfig{SigningSecret: "shh"}
+	r := httptest.NewRequest("POST", "/slack/interactions", strings.NewReader("payload=%7B%7D"))
+	r.Header.Set("X-Slack-Request-Timestamp", strconv.FormatInt(time.Now().Unix(), 10))
+	r.Header.Set("X-Slack-Signature", "v0=00")
+	w := httptest.NewRecorder()
+	if b.handleSlackInteraction(w, r); w.Code != 401 {
+		t.Errorf("unsigned click: status %d", w.Code)
+	}
+}
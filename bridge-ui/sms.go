@@ -0,0 +1,215 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultTwilioBaseURL = "https://api.twilio.com"
+
+// sendSMS sends notification via Twilio with a numbered option list
+func (b *BridgeService) sendSMS(bridgeCfg BridgeConfig, question string, options []string, requestID string) error {
+	cfg := bridgeCfg.SMS
+	if cfg.TwilioSID == "" || cfg.TwilioToken == "" || cfg.From == "" || cfg.To == "" {
+		return fmt.Errorf("SMS not configured")
+	}
+
+	b.log("📤 Sending SMS notification...")
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "🤖 Agent question [%s]\n\n%s\n\n", requestID, question)
+	for i, opt := range options {
+		fmt.Fprintf(&body, "%d) %s\n", i+1, opt)
+	}
+	body.WriteString("\nReply with a number or type your answer.")
+	if publicURL := b.getPublicURL(); publicURL != "" {
+		fmt.Fprintf(&body, "\n%s", buildResponseURL(publicURL, requestID, "", b.requestDeadline(requestID)))
+	}
+
+	// Register before sending so a fast reply can't miss its request
+	b.pendingMu.Lock()
+	b.smsRequests = append(b.smsRequests, requestID)
+	b.pendingMu.Unlock()
+
+	return sendTwilioSMS(cfg, body.String())
+}
+
+// sendTwilioSMS posts a message to the Twilio Messages API
+func sendTwilioSMS(cfg SMSConfig, body string) error {
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultTwilioBaseURL
+	}
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", baseURL, url.PathEscape(cfg.TwilioSID))
+
+	form := url.Values{}
+	form.Set("From", cfg.From)
+	form.Set("To", cfg.To)
+	form.Set("Body", body)
+
+	req, err := http.NewRequest("POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(cfg.TwilioSID, cfg.TwilioToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		json.Unmarshal(data, &apiErr)
+		return fmt.Errorf("Twilio API returned status %d: %s", resp.StatusCode, apiErr.Message)
+	}
+	return nil
+}
+
+// handleSMSInbound receives Twilio's form-encoded reply webhook and resolves the matching request
+func (b *BridgeService) handleSMSInbound(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "POST required", 405)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", 400)
+		return
+	}
+
+	cfg := b.config()
+	webhookURL := b.getPublicURL() + r.URL.RequestURI()
+	if !validTwilioSignature(cfg.SMS.TwilioToken, webhookURL, r.PostForm, r.Header.Get("X-Twilio-Signature")) {
+		b.log("🚫 Rejected SMS webhook with invalid X-Twilio-Signature")
+		http.Error(w, "Invalid signature", 403)
+		return
+	}
+
+	from := r.PostForm.Get("From")
+	text := strings.TrimSpace(r.PostForm.Get("Body"))
+	if !cfg.smsSenderAllowed(from) {
+		b.log(fmt.Sprintf("🚫 Ignored SMS from unknown number %s", from))
+		writeTwiML(w, "")
+		return
+	}
+
+	requestID, answer, ok := b.matchSMSReply(text)
+	if !ok {
+		writeTwiML(w, "No pending question found for your reply.")
+		return
+	}
+
+	b.log(fmt.Sprintf("📥 SMS response received: %s -> %s", requestID, answer))
+	if err := b.submitVote(requestID, Vote{Answer: answer, Via: "sms", Approver: cfg.approverFor("sms", from), User: from}); err != nil {
+		writeTwiML(w, refusal(err))
+		return
+	}
+	writeTwiML(w, "✅ Got it: "+answer)
+}
+
+// smsSenderAllowed reports whether a reply comes from the configured number or a backup approver's
+func (c BridgeConfig) smsSenderAllowed(from string) bool {
+	if c.SMS.To == "" || from == c.SMS.To {
+		return true
+	}
+	for _, contact := range c.contacts() {
+		if contact.Phone != "" && from == contact.Phone {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSMSReply maps reply text to a pending request. Replies may be prefixed
+// with a request ID ("1a2b3c4d 2"); otherwise the newest SMS request wins.
+// A bare number selects the matching option, anything else is a custom answer.
+func (b *BridgeService) matchSMSReply(text string) (requestID, answer string, ok bool) {
+	if text == "" {
+		return "", "", false
+	}
+
+	b.pendingMu.Lock()
+	defer b.pendingMu.Unlock()
+
+	// Drop requests that are no longer pending
+	live := b.smsRequests[:0]
+	for _, id := range b.smsRequests {
+		if _, exists := b.pendingRequests[id]; exists {
+			live = append(live, id)
+		}
+	}
+	b.smsRequests = live
+	if len(live) == 0 {
+		return "", "", false
+	}
+
+	requestID = live[len(live)-1]
+	answer = text
+	if fields := strings.SplitN(strings.TrimPrefix(text, "#"), " ", 2); len(fields) == 2 {
+		if _, exists := b.pendingRequests[fields[0]]; exists {
+			requestID = fields[0]
+			answer = strings.TrimSpace(fields[1])
+		}
+	}
+
+	options := b.requestData[requestID].Options
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+		answer = options[n-1]
+	}
+	return requestID, answer, true
+}
+
+// validTwilioSignature checks X-Twilio-Signature: base64(HMAC-SHA1(token, url + sorted key/value pairs))
+func validTwilioSignature(authToken, webhookURL string, params url.Values, signature string) bool {
+	if authToken == "" || signature == "" {
+		return false
+	}
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var payload strings.Builder
+	payload.WriteString(webhookURL)
+	for _, k := range keys {
+		for _, v := range params[k] {
+			payload.WriteString(k)
+			payload.WriteString(v)
+		}
+	}
+
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(payload.String()))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// writeTwiML answers the webhook with an optional reply message
+func writeTwiML(w http.ResponseWriter, message string) {
+	type twimlResponse struct {
+		XMLName xml.Name `xml:"Response"`
+		Message string   `xml:"Message,omitempty"`
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	io.WriteString(w, xml.Header)
+	xml.NewEncoder(w).Encode(twimlResponse{Message: message})
+}
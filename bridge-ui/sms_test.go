@@ -0,0 +1,173 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// inboundSMS is a Twilio reply webhook and its signature, computed independently
+// for token "secret-token" and URL https://bridge.example/sms/inbound
+var (
+	inboundSMS = url.Values{
+		"From":       {"+15550001111"},
+		"To":         {"+15559990000"},
+		"Body":       {"2"},
+		"MessageSid": {"SM123"},
+	}
+	inboundSMSSignature = "WgcwlX4wG9ytmrlutgUmY5wKVDo="
+)
+
+func TestValidTwilioSignature(t *testing.T) {
+	const webhookURL = "https://bridge.example/sms/inbound"
+	tampered := url.Values{}
+	for k, v := range inboundSMS {
+		tampered[k] = v
+	}
+	tampered.Set("Body", "1")
+
+	tests := []struct {
+		name      string
+		token     string
+		url       string
+		params    url.Values
+		signature string
+		want      bool
+	}{
+		{"valid", "secret-token", webhookURL, inboundSMS, inboundSMSSignature, true},
+		{"tampered body", "secret-token", webhookURL, tampered, inboundSMSSignature, false},
+		{"other url", "secret-token", webhookURL + "?x=1", inboundSMS, inboundSMSSignature, false},
+		{"wrong token", "other-token", webhookURL, inboundSMS, inboundSMSSignature, false},
+		{"no token", "", webhookURL, inboundSMS, inboundSMSSignature, false},
+		{"no signature", "secret-token", webhookURL, inboundSMS, "", false},
+	}
+	for _, tt := range tests {
+		if got := validTwilioSignature(tt.token, tt.url, tt.params, tt.signature); got != tt.want {
+			t.Errorf("%s: validTwilioSignature = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// smsBridge has two pending SMS requests, "aaaa1111" then the newer "bbbb2222"
+func smsBridge() *BridgeService {
+	b := NewBridgeService()
+	b.cfg.SMS = SMSConfig{TwilioToken: "secret-token", To: "+15550001111"}
+	b.publicURL = "https://bridge.example"
+	for _, id := range []string{"aaaa1111", "bbbb2222"} {
+		b.pendingRequests[id] = make(chan string, 1)
+		b.requestData[id] = RequestData{Question: "Deploy?", Options: []string{"Yes", "No"}}
+		b.smsRequests = append(b.smsRequests, id)
+	}
+	return b
+}
+
+func TestMatchSMSReply(t *testing.T) {
+	tests := []struct {
+		text       string
+		wantID     string
+		wantAnswer string
+		wantOK     bool
+	}{
+		{"1", "bbbb2222", "Yes", true},
+		{"2", "bbbb2222", "No", true},
+		{"3", "bbbb2222", "3", true},
+		{"ship it", "bbbb2222", "ship it", true},
+		{"aaaa1111 2", "aaaa1111", "No", true},
+		{"#aaaa1111 wait an hour", "aaaa1111", "wait an hour", true},
+		{"cccc3333 2", "bbbb2222", "cccc3333 2", true},
+		{"", "", "", false},
+	}
+	for _, tt := range tests {
+		id, answer, ok := smsBridge().matchSMSReply(tt.text)
+		if id != tt.wantID || answer != tt.wantAnswer || ok != tt.wantOK {
+			t.Errorf("matchSMSReply(%q) = %q, %q, %v; want %q, %q, %v", tt.text, id, answer, ok, tt.wantID, tt.wantAnswer, tt.wantOK)
+		}
+	}
+
+	b := smsBridge()
+	delete(b.pendingRequests, "bbbb2222")
+	if id, _, _ := b.matchSMSReply("1"); id != "aaaa1111" || len(b.smsRequests) != 1 {
+		t.Errorf("an answered request should be skipped: got %q, %v", id, b.smsRequests)
+	}
+}
+
+func TestHandleSMSInbound(t *testing.T) {
+	post := func(b *BridgeService, form url.Values, signature string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/sms/inbound", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.Header.Set("X-Twilio-Signature", signature)
+		w := httptest.NewRecorder()
+		b.handleSMSInbound(w, r)
+		return w
+	}
+
+	b := smsBridge()
+	if w := post(b, inboundSMS, "bad"); w.Code != 403 {
+		t.Errorf("bad signature: status %d", w.Code)
+	}
+	w := post(b, inboundSMS, inboundSMSSignature)
+	if w.Code != 200 || !strings.Contains(w.Body.String(), "<Message>✅ Got it: No</Message>") {
+		t.Errorf("reply: %d %s", w.Code, w.Body.String())
+	}
+	select {
+	case answer := <-b.pendingRequests["bbbb2222"]:
+		if answer != "No" {
+			t.Errorf("answer = %q", answer)
+		}
+	default:
+		t.Error("the newest request was not answered")
+	}
+
+	b = smsBridge()
+	b.cfg.SMS.To = "+15552223333"
+	if w := post(b, inboundSMS, inboundSMSSignature); w.Code != 200 || strings.Contains(w.Body.String(), "<Message>") || len(b.pendingRequests["bbbb2222"]) != 0 {
+		t.Errorf("a reply from an unknown number was taken: %s", w.Body.String())
+	}
+
+	// A refused answer is told why, not that it came too late
+	b = smsBridge()
+	data := b.requestData["bbbb2222"]
+	data.SecondFactor = true
+	b.requestData["bbbb2222"] = data
+	if w := post(b, inboundSMS, inboundSMSSignature); !strings.Contains(w.Body.String(), "<Message>"+refusal(errSecondFactorRequired)+"</Message>") || len(b.pendingRequests["bbbb2222"]) != 0 {
+		t.Errorf("high-risk reply: %s", w.Body.String())
+	}
+}
+
+func TestSendTwilioSMS(t *testing.T) {
+	var got *http.Request
+	var form url.Values
+	twilio := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		r.ParseForm()
+		form = r.PostForm
+		if r.PostForm.Get("To") == "+10000000000" {
+			w.WriteHeader(400)
+			io.WriteString(w, `{"code":21211,"message":"The 'To' number is not valid."}`)
+			return
+		}
+		w.WriteHeader(201)
+		io.WriteString(w, `{"sid":"SM1"}`)
+	}))
+	defer twilio.Close()
+
+	cfg := SMSConfig{TwilioSID: "AC123", TwilioToken: "tok", From: "+15559990000", To: "+15550001111", BaseURL: twilio.URL + "/"}
+	if err := sendTwilioSMS(cfg, "Deploy?\n1) Yes"); err != nil {
+		t.Fatalf("sendTwilioSMS: %v", err)
+	}
+	user, pass, _ := got.BasicAuth()
+	if got.URL.Path != "/2010-04-01/Accounts/AC123/Messages.json" || user != "AC123" || pass != "tok" {
+		t.Errorf("request = %s, auth %s:%s", got.URL.Path, user, pass)
+	}
+	if form.Get("From") != cfg.From || form.Get("To") != cfg.To || form.Get("Body") != "Deploy?\n1) Yes" {
+		t.Errorf("form = %v", form)
+	}
+
+	cfg.To = "+10000000000"
+	if err := sendTwilioSMS(cfg, "x"); err == nil || !strings.Contains(err.Error(), "status 400: The 'To' number is not valid.") {
+		t.Errorf("API error = %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui/requestlog"
+)
+
+// StoredRequest is the persisted state of a request, shared with the root bridge
+type StoredRequest = requestlog.Request
+
+// requestStorePath returns the log path next to the config file
+func requestStorePath() string {
+	return filepath.Join(filepath.Dir(getConfigPath()), requestlog.FileName)
+}
+
+// openStore opens the request log on first use; nil if it can't be opened
+func (b *BridgeService) openStore() *requestlog.Log {
+	b.storeOnce.Do(func() {
+		store, err := requestlog.Open(requestStorePath())
+		if err != nil {
+			b.log(fmt.Sprintf("⚠️ Request store unavailable: %v", err))
+			return
+		}
+		store.OnChange = func(r StoredRequest) { b.emit("requestUpdated", r) }
+		b.store = store
+	})
+	return b.store
+}
+
+// storedRequestData rebuilds the in-memory state of a stored request
+func storedRequestData(r StoredRequest) RequestData {
+	return RequestData{
+		Question:     r.Question,
+		Options:      r.Options,
+		Context:      r.QuestionContext(),
+		Schema:       r.AnswerSchema(),
+		Deliveries:   r.Deliveries,
+		Approvers:    r.Approvers,
+		Needed:       r.Needed,
+		Votes:        r.Votes,
+		UsedLinks:    r.UsedLinks,
+		CreatedAt:    r.CreatedAt,
+		Deadline:     r.Deadline,
+		SecondFactor: r.SecondFactor,
+	}
+}
+
+// restoreRequests takes over requests left pending by bridge processes that have
+// since exited, so their links and buttons keep working. Requests of a bridge
+// that is still running - the app and an --mcp bridge share the log - stay its own.
+func (b *BridgeService) restoreRequests() {
+	if b.restored {
+		return
+	}
+	b.restored = true
+	store := b.openStore()
+	if store == nil {
+		return
+	}
+
+	claimed, expired, err := store.ClaimOrphans(time.Now())
+	if err != nil {
+		b.log(fmt.Sprintf("⚠️ Could not restore pending requests: %v", err))
+	}
+	for _, r := range expired {
+		b.audit("decision", r.ID, storedRequestData(r), Vote{Via: "timeout"}, "expired")
+	}
+	for _, r := range claimed {
+		ch := make(chan string, 1)
+		data := storedRequestData(r)
+		if r.Undelivered() {
+			// Answered before its bridge died: the answer waits for the agent
+			ch <- r.Answer
+			data.Decided = true
+		}
+		b.pendingMu.Lock()
+		b.pendingRequests[r.ID] = ch
+		b.requestData[r.ID] = data
+		b.orphans[r.ID] = true
+		b.pendingMu.Unlock()
+
+		go b.expireOrphan(r.ID, r.Deadline)
+	}
+
+	if len(claimed) > 0 || len(expired) > 0 {
+		b.log(fmt.Sprintf("♻️ Restored %d pending request(s); %d expired while the bridge was down", len(claimed), len(expired)))
+	}
+}
+
+// adoptOrphan hands a restored request to a new ask call, so an answer given while
+// the agent was disconnected is not lost. The agent names the request by the
+// request_id it was given; without one, a request with the same question and
+// options is taken, which is best effort.
+func (b *BridgeService) adoptOrphan(req AskRequest) (string, chan string, bool, error) {
+	b.pendingMu.Lock()
+	defer b.pendingMu.Unlock()
+
+	if req.RequestID != "" {
+		if !b.orphans[req.RequestID] {
+			if r, ok := b.store.Get(req.RequestID); ok {
+				return "", nil, false, fmt.Errorf("request %s is %s and can't be resumed", req.RequestID, r.Status)
+			}
+			return "", nil, false, fmt.Errorf("unknown request %s", req.RequestID)
+		}
+		delete(b.orphans, req.RequestID)
+		return req.RequestID, b.pendingRequests[req.RequestID], true, nil
+	}
+	for id := range b.orphans {
+		data := b.requestData[id]
+		if data.Question == req.Question && equalStrings(data.Options, req.Options) {
+			delete(b.orphans, id)
+			return id, b.pendingRequests[id], true, nil
+		}
+	}
+	return "", nil, false, nil
+}
+
+// expireOrphan drops a restored request nobody reattached to by its deadline.
+// An answer it got meanwhile never reached an agent, so it is expired too.
+func (b *BridgeService) expireOrphan(requestID string, deadline time.Time) {
+	time.Sleep(time.Until(deadline))
+
+	b.pendingMu.Lock()
+	if !b.orphans[requestID] {
+		b.pendingMu.Unlock()
+		return
+	}
+	data := b.requestData[requestID]
+	delete(b.orphans, requestID)
+	delete(b.pendingRequests, requestID)
+	delete(b.requestData, requestID)
+	b.pendingMu.Unlock()
+
+	b.store.Update(requestID, func(r *StoredRequest) { r.Status = "expired" })
+	outcome := "expired"
+	if data.Decided {
+		outcome = "undelivered"
+	}
+	b.audit("decision", requestID, data, Vote{Via: "timeout"}, outcome)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// telegramBot returns the shared bot client, connecting on first use
+func (b *BridgeService) telegramBot() (*tgbotapi.BotAPI, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tgBot != nil {
+		return b.tgBot, nil
+	}
+	bot, err := tgbotapi.NewBotAPI(b.cfg.Telegram.BotToken)
+	if err != nil {
+		return nil, err
+	}
+	b.tgBot = bot
+	return bot, nil
+}
+
+// telegramMessageRef identifies a sent notification; message IDs are only unique per chat
+type telegramMessageRef struct {
+	ChatID    int64
+	MessageID int
+}
+
+// telegramOptionButtons renders one callback button per option ("<requestID>:<index>")
+func telegramOptionButtons(options []string, requestID string) [][]tgbotapi.InlineKeyboardButton {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, opt := range options {
+		data := fmt.Sprintf("%s:%d", requestID, i)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(opt, data)))
+	}
+	return rows
+}
+
+// parseTelegramCallback reads the "<requestID>:<index>" data of an option button
+func parseTelegramCallback(data string) (string, int, bool) {
+	requestID, number, ok := strings.Cut(data, ":")
+	index, err := strconv.Atoi(number)
+	if !ok || requestID == "" || err != nil {
+		return "", 0, false
+	}
+	return requestID, index, true
+}
+
+// startTelegram long-polls for Telegram updates until ctx is cancelled
+func (b *BridgeService) startTelegram(ctx context.Context) {
+	if b.cfg.Telegram.BotToken == "" || b.cfg.Telegram.ChatID == "" {
+		return
+	}
+
+	bot, err := b.telegramBot()
+	if err != nil {
+		b.log(fmt.Sprintf("❌ Telegram Error: %v", err))
+		return
+	}
+
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = 30
+	u.AllowedUpdates = []string{"message", "callback_query"}
+	updates := bot.GetUpdatesChan(u)
+
+	go func() {
+		<-ctx.Done()
+		bot.StopReceivingUpdates()
+		b.mu.Lock()
+		b.tgBot = nil
+		b.mu.Unlock()
+	}()
+
+	b.log(fmt.Sprintf("🤖 Telegram listening as @%s", bot.Self.UserName))
+	for update := range updates {
+		if update.CallbackQuery != nil {
+			b.handleTelegramCallback(bot, update.CallbackQuery)
+		} else if update.Message != nil && update.Message.ReplyToMessage != nil {
+			b.handleTelegramReply(bot, update.Message)
+		}
+	}
+}
+
+// handleTelegramCallback resolves a pending request from an inline button press
+func (b *BridgeService) handleTelegramCallback(bot *tgbotapi.BotAPI, cb *tgbotapi.CallbackQuery) {
+	// Only the configured chats may answer
+	if cb.Message == nil || !b.telegramChatAllowed(cb.Message.Chat.ID) {
+		bot.Request(tgbotapi.NewCallback(cb.ID, "⛔ Not allowed"))
+		return
+	}
+
+	requestID, index, ok := parseTelegramCallback(cb.Data)
+	if !ok {
+		bot.Request(tgbotapi.NewCallback(cb.ID, ""))
+		return
+	}
+
+	b.pendingMu.Lock()
+	data, exists := b.requestData[requestID]
+	b.pendingMu.Unlock()
+
+	if !exists || index < 0 || index >= len(data.Options) {
+		bot.Request(tgbotapi.NewCallback(cb.ID, "⌛ Expired or already answered"))
+		return
+	}
+
+	answer := data.Options[index]
+	user := telegramUserName(cb.From)
+	message := telegramMessageRef{ChatID: cb.Message.Chat.ID, MessageID: cb.Message.MessageID}.key()
+	decided, err := b.submitMessageVote(requestID, Vote{Answer: answer, Via: "telegram", Approver: b.cfg.approverFor("telegram", strconv.FormatInt(cb.From.ID, 10)), User: user}, message)
+	if err != nil {
+		bot.Request(tgbotapi.NewCallback(cb.ID, refusal(err)))
+		return
+	}
+
+	b.log(fmt.Sprintf("📥 Telegram response from %s: %s -> %s", user, requestID, answer))
+	if !decided {
+		// Other approvers still need the buttons
+		bot.Request(tgbotapi.NewCallback(cb.ID, "🗳️ Vote recorded: "+answer))
+		return
+	}
+	bot.Request(tgbotapi.NewCallback(cb.ID, "✅ Sent: "+answer))
+	b.markTelegramAnswered(bot, cb.Message, answer, user)
+}
+
+// handleTelegramReply treats a reply to a notification as a custom answer,
+// like the "Send Custom Answer" box on the response page
+func (b *BridgeService) handleTelegramReply(bot *tgbotapi.BotAPI, msg *tgbotapi.Message) {
+	answer := strings.TrimSpace(msg.Text)
+	if !b.telegramChatAllowed(msg.Chat.ID) || answer == "" {
+		return
+	}
+
+	original := msg.ReplyToMessage
+	requestID, ok := b.telegramRequestFor(msg.Chat.ID, original.MessageID)
+	if !ok {
+		return
+	}
+
+	reply := tgbotapi.NewMessage(msg.Chat.ID, "")
+	reply.ReplyToMessageID = msg.MessageID
+	user := telegramUserName(msg.From)
+	message := telegramMessageRef{ChatID: msg.Chat.ID, MessageID: original.MessageID}.key()
+	decided, err := b.submitMessageVote(requestID, Vote{Answer: answer, Via: "telegram", Approver: b.cfg.approverFor("telegram", strconv.FormatInt(msg.From.ID, 10)), User: user}, message)
+	if err != nil {
+		reply.Text = refusal(err)
+		bot.Send(reply)
+		return
+	}
+
+	b.log(fmt.Sprintf("📥 Telegram reply from %s: %s -> %s", user, requestID, answer))
+	if !decided {
+		reply.Text = "🗳️ Vote recorded. Waiting for the other approvers."
+		bot.Send(reply)
+		return
+	}
+	reply.Text = "✅ Sent to your agent."
+	bot.Send(reply)
+
+	b.markTelegramAnswered(bot, original, answer, user)
+}
+
+// telegramRequestFor finds the pending request whose notification has the given message ID
+func (b *BridgeService) telegramRequestFor(chatID int64, messageID int) (string, bool) {
+	b.pendingMu.Lock()
+	defer b.pendingMu.Unlock()
+
+	found := ""
+	for requestID, refs := range b.tgMessages {
+		if _, pending := b.pendingRequests[requestID]; !pending {
+			delete(b.tgMessages, requestID)
+			continue
+		}
+		for _, ref := range refs {
+			if ref.ChatID == chatID && ref.MessageID == messageID {
+				found = requestID
+			}
+		}
+	}
+	return found, found != ""
+}
+
+// markTelegramAnswered replaces a notification's buttons with the outcome
+func (b *BridgeService) markTelegramAnswered(bot *tgbotapi.BotAPI, original *tgbotapi.Message, answer string, user string) {
+	text := fmt.Sprintf("%s\n\n✅ %s by %s at %s", original.Text, answer, user, time.Now().Format("15:04"))
+	if _, err := bot.Send(tgbotapi.NewEditMessageText(original.Chat.ID, original.MessageID, text)); err != nil {
+		b.log(fmt.Sprintf("⚠️ Telegram edit failed: %v", err))
+	}
+}
+
+// closeTelegramMessage replaces a notification's buttons after another channel answered it
+func (b *BridgeService) closeTelegramMessage(ref telegramMessageRef, question, outcome string) {
+	bot, err := b.telegramBot()
+	if err != nil {
+		return
+	}
+
+	text := fmt.Sprintf("🤖 Input Needed\n\n%s\n\n%s", question, outcome)
+	if _, err := bot.Send(tgbotapi.NewEditMessageText(ref.ChatID, ref.MessageID, text)); err != nil {
+		b.log(fmt.Sprintf("⚠️ Telegram edit failed: %v", err))
+	}
+}
+
+// telegramChatAllowed reports whether a chat is the configured one or a backup approver's
+func (b *BridgeService) telegramChatAllowed(chatID int64) bool {
+	id := strconv.FormatInt(chatID, 10)
+	if strings.TrimSpace(b.cfg.Telegram.ChatID) == id {
+		return true
+	}
+	for _, contact := range b.cfg.contacts() {
+		if strings.TrimSpace(contact.TelegramChatID) == id {
+			return true
+		}
+	}
+	return false
+}
+
+// telegramUserName returns a display name for the user who answered
+func telegramUserName(u *tgbotapi.User) string {
+	if u == nil {
+		return "unknown"
+	}
+	if u.UserName != "" {
+		return "@" + u.UserName
+	}
+	return u.FirstName
+}
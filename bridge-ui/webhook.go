@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// webhookSignatureHeader carries "sha256=<hex HMAC of the raw body>" in both directions
+const webhookSignatureHeader = "X-Momentum-Signature"
+
+// WebhookEnvelope is the JSON document POSTed to the configured webhook URL
+type WebhookEnvelope struct {
+	Type        string            `json:"type"`
+	RequestID   string            `json:"request_id"`
+	Question    string            `json:"question"`
+	Options     []string          `json:"options"`
+	CallbackURL string            `json:"callback_url"`
+	ResponseURL string            `json:"response_url"`
+	CreatedAt   time.Time         `json:"created_at"`
+	ExpiresAt   time.Time         `json:"expires_at"`
+	Metadata    map[string]string `json:"metadata"`
+	Answer      string            `json:"answer,omitempty"`       // Set on "answered" envelopes
+	AnsweredVia string            `json:"answered_via,omitempty"` // Channel that answered first
+}
+
+// sendWebhook POSTs a signed JSON envelope to the configured URL
+func (b *BridgeService) sendWebhook(bridgeCfg BridgeConfig, question string, options []string, requestID string) error {
+	cfg := bridgeCfg.Webhook
+	if cfg.URL == "" {
+		return fmt.Errorf("Webhook not configured")
+	}
+
+	b.log("📤 Sending webhook notification...")
+
+	publicURL := b.getPublicURL()
+	now := time.Now().UTC()
+	envelope := WebhookEnvelope{
+		Type:        "question",
+		RequestID:   requestID,
+		Question:    question,
+		Options:     options,
+		CallbackURL: publicURL + "/webhook/answer",
+		ResponseURL: buildResponseURL(publicURL, requestID, "", b.requestDeadline(requestID)),
+		CreatedAt:   now,
+		ExpiresAt:   b.requestDeadline(requestID),
+		Metadata:    b.requestMetadata(),
+	}
+	if options == nil {
+		envelope.Options = []string{}
+	}
+	return postWebhook(cfg, envelope)
+}
+
+// sendWebhookAnswered tells the receiver a request was answered on another channel
+func (b *BridgeService) sendWebhookAnswered(requestID string, data RequestData, answer, via string) {
+	now := time.Now().UTC()
+	envelope := WebhookEnvelope{
+		Type:        "answered",
+		RequestID:   requestID,
+		Question:    data.Question,
+		Options:     data.Options,
+		CreatedAt:   now,
+		ExpiresAt:   now,
+		Metadata:    b.requestMetadata(),
+		Answer:      answer,
+		AnsweredVia: via,
+	}
+	if envelope.Options == nil {
+		envelope.Options = []string{}
+	}
+	if err := postWebhook(b.config().Webhook, envelope); err != nil {
+		b.log(fmt.Sprintf("⚠️ Webhook update failed: %v", err))
+	}
+}
+
+// postWebhook signs and delivers an envelope
+func postWebhook(cfg WebhookConfig, envelope WebhookEnvelope) error {
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Momentum-Webhook/"+CurrentVersion)
+	if cfg.Secret != "" {
+		req.Header.Set(webhookSignatureHeader, signWebhook(cfg.Secret, body))
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// requestMetadata describes where the question came from
+func (b *BridgeService) requestMetadata() map[string]string {
+	meta := map[string]string{"source": b.config().Source}
+	if host, err := os.Hostname(); err == nil {
+		meta["host"] = host
+	}
+	// In MCP mode the editor launches us inside the workspace
+	if wd, err := os.Getwd(); err == nil {
+		meta["workspace"] = wd
+	}
+	return meta
+}
+
+// handleWebhookAnswer lets the webhook receiver answer with a signed {"request_id","answer"} POST
+func (b *BridgeService) handleWebhookAnswer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "POST required", 405)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "Bad request", 400)
+		return
+	}
+
+	cfg := b.config()
+	secret := cfg.Webhook.Secret
+	if secret == "" || !hmac.Equal([]byte(signWebhook(secret, body)), []byte(r.Header.Get(webhookSignatureHeader))) {
+		b.log("🚫 Rejected webhook answer with invalid signature")
+		http.Error(w, "Invalid signature", 401)
+		return
+	}
+
+	var req struct {
+		RequestID string `json:"request_id"`
+		Answer    string `json:"answer"`
+		Approver  string `json:"approver"` // Optional; must be the approver bound to the webhook
+	}
+	if err := json.Unmarshal(body, &req); err != nil || req.RequestID == "" || req.Answer == "" {
+		http.Error(w, "Invalid JSON", 400)
+		return
+	}
+
+	// Anyone holding the secret can post, so the body can't pick who voted:
+	// answers count for the one approver bound to the webhook, if any
+	approver := cfg.webhookApprover()
+	if req.Approver != "" && req.Approver != approver {
+		b.log(fmt.Sprintf("🚫 Rejected webhook answer to %s claiming approver %q", req.RequestID, req.Approver))
+		http.Error(w, "The webhook can only answer for the approver bound to it", 403)
+		return
+	}
+
+	b.pendingMu.Lock()
+	_, exists := b.pendingRequests[req.RequestID]
+	b.pendingMu.Unlock()
+	if !exists {
+		http.Error(w, "Request not found or expired", 404)
+		return
+	}
+
+	switch err := b.submitVote(req.RequestID, Vote{Answer: req.Answer, Via: "webhook", Approver: approver, ClientIP: b.clientIP(r)}); err {
+	case nil:
+	case errRequestGone:
+		http.Error(w, "Request already answered", 409)
+		return
+	case errNotApprover, errSecondFactorRequired:
+		http.Error(w, err.Error(), 403)
+		return
+	default:
+		http.Error(w, "Invalid answer: "+err.Error(), 400)
+		return
+	}
+
+	b.log(fmt.Sprintf("📥 Webhook response received: %s -> %s", req.RequestID, req.Answer))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// signWebhook returns the signature header value for a body
+func signWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
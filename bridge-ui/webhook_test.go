@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSignWebhook(t *testing.T) {
+	body := []byte(`{"request_id":"req1","answer":"Yes"}`)
+	// Computed independently with Python's hmac module
+	const want = "sha256=21fea05ea97cf621dae45e6102fa68ca3301a31f402a46d3d9e0f8dc357df1b6"
+	if got := signWebhook("shh", body); got != want {
+		t.Errorf("signWebhook = %s, want %s", got, want)
+	}
+	if signWebhook("other", body) == want {
+		t.Error("the signature does not depend on the secret")
+	}
+}
+
+func TestPostWebhook(t *testing.T) {
+	var body []byte
+	var signature string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(webhookSignatureHeader)
+		if strings.Contains(string(body), `"request_id":"broken"`) {
+			w.WriteHeader(500)
+		}
+	}))
+	defer receiver.Close()
+
+	tests := []struct {
+		name          string
+		secret        string
+		requestID     string
+		wantErr       bool
+		wantSignature bool
+	}{
+		{"signed", "shh", "req1", false, true},
+		{"unsigned without a secret", "", "req1", false, false},
+		{"receiver error", "shh", "broken", true, true},
+	}
+	for _, tt := range tests {
+		err := postWebhook(WebhookConfig{URL: receiver.URL, Secret: tt.secret}, WebhookEnvelope{Type: "question", RequestID: tt.requestID, Options: []string{}})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: postWebhook = %v", tt.name, err)
+		}
+		if tt.wantSignature && signature != signWebhook(tt.secret, body) {
+			t.Errorf("%s: signature %q does not match the body", tt.name, signature)
+		}
+		if !tt.wantSignature && signature != "" {
+			t.Errorf("%s: unexpected signature %q", tt.name, signature)
+		}
+		var envelope WebhookEnvelope
+		if err := json.Unmarshal(body, &envelope); err != nil || envelope.RequestID != tt.requestID {
+			t.Errorf("%s: envelope = %s", tt.name, body)
+		}
+	}
+}
+
+func TestHandleWebhookAnswer(t *testing.T) {
+	post := func(b *BridgeService, body, signature string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/webhook/answer", strings.NewReader(body))
+		if signature != "" {
+			r.Header.Set(webhookSignatureHeader, signature)
+		}
+		w := httptest.NewRecorder()
+		b.handleWebhookAnswer(w, r)
+		return w
+	}
+	answer := `{"request_id":"req1","answer":"Yes"}`
+
+	tests := []struct {
+		name       string
+		secret     string
+		body       string
+		signature  string
+		wantStatus int
+	}{
+		{"signed answer", "shh", answer, signWebhook("shh", []byte(answer)), 200},
+		{"no signature", "shh", answer, "", 401},
+		{"signed with another secret", "shh", answer, signWebhook("other", []byte(answer)), 401},
+		{"tampered body", "shh", strings.Replace(answer, "Yes", "No", 1), signWebhook("shh", []byte(answer)), 401},
+		{"no secret configured", "", answer, signWebhook("", []byte(answer)), 401},
+		{"unknown request", "shh", `{"request_id":"nope","answer":"Yes"}`, signWebhook("shh", []byte(`{"request_id":"nope","answer":"Yes"}`)), 404},
+		{"no answer", "shh", `{"request_id":"req1"}`, signWebhook("shh", []byte(`{"request_id":"req1"}`)), 400},
+	}
+	for _, tt := range tests {
+		b := NewBridgeService()
+		b.cfg.Webhook = WebhookConfig{Secret: tt.secret}
+		ch := make(chan string, 1)
+		b.pendingRequests["req1"] = ch
+		b.requestData["req1"] = RequestData{Question: "Deploy?", Options: []string{"Yes", "No"}}
+
+		w := post(b, tt.body, tt.signature)
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, w.Code, tt.wantStatus, w.Body.String())
+		}
+		if answered := len(ch) == 1; answered != (tt.wantStatus == 200) {
+			t.Errorf("%s: answered = %v", tt.name, answered)
+		}
+	}
+}
+
+func TestWebhookAnswerQuorum(t *testing.T) {
+	tests := []struct {
+		name       string
+		bound      bool // Whether bob is bound to the webhook
+		claimed    string
+		wantStatus int
+		wantVoter  string
+	}{
+		{"bound approver", true, "", 200, "bob"},
+		{"claims the bound approver", true, "bob", 200, "bob"},
+		{"claims another approver", true, "alice", 403, ""},
+		{"no approver bound", false, "", 403, ""},
+		{"claims an approver while none is bound", false, "alice", 403, ""},
+	}
+	for _, tt := range tests {
+		b := NewBridgeService()
+		b.cfg.Webhook = WebhookConfig{Secret: "shh"}
+		b.cfg.Approvers = []Approver{{Contact: Contact{Name: "alice"}}, {Contact: Contact{Name: "bob", Webhook: tt.bound}}}
+		b.pendingRequests["req1"] = make(chan string, 1)
+		b.requestData["req1"] = RequestData{Question: "Deploy?", Options: []string{"Yes", "No"}, Approvers: []string{"alice", "bob"}, Needed: 2}
+
+		body := `{"request_id":"req1","answer":"Yes","approver":"` + tt.claimed + `"}`
+		r := httptest.NewRequest("POST", "/webhook/answer", strings.NewReader(body))
+		r.Header.Set(webhookSignatureHeader, signWebhook("shh", []byte(body)))
+		w := httptest.NewRecorder()
+		b.handleWebhookAnswer(w, r)
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, w.Code, tt.wantStatus, w.Body.String())
+		}
+		votes := b.requestData["req1"].Votes
+		if tt.wantVoter == "" && len(votes) != 0 || tt.wantVoter != "" && (len(votes) != 1 || votes[0].Approver != tt.wantVoter) {
+			t.Errorf("%s: votes = %+v", tt.name, votes)
+		}
+		if b.requestData["req1"].Decided {
+			t.Errorf("%s: one webhook vote met a quorum of two", tt.name)
+		}
+	}
+}
@@ -0,0 +1,394 @@
+// Package main - discord.go
+// Discord delivery for Remote Bridge
+// Supports: incoming webhook (embed + link) and bot mode (buttons answered in place)
+
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui"
+)
+
+// discordAPIBase is the Discord REST endpoint (overridable for local testing)
+var discordAPIBase = "https://discord.com/api/v10"
+
+// discordClient is shared by webhook and bot mode
+var discordClient = &http.Client{Timeout: 15 * time.Second}
+
+// Discord interaction and component constants
+const (
+	discordInteractionPing      = 1
+	discordInteractionComponent = 3
+
+	discordResponsePong          = 1
+	discordResponseChannelMsg    = 4
+	discordResponseUpdateMessage = 7
+
+	discordComponentActionRow = 1
+	discordComponentButton    = 2
+
+	discordButtonPrimary = 1
+	discordButtonLink    = 5
+
+	discordFlagEphemeral = 64
+
+	discordMaxButtons = 25 // 5 rows of 5
+
+	discordCustomIDPrefix = "momentum"
+)
+
+type discordEmbed struct {
+	Title       string              `json:"title,omitempty"`
+	Description string              `json:"description,omitempty"`
+	URL         string              `json:"url,omitempty"`
+	Color       int                 `json:"color,omitempty"`
+	Fields      []discordEmbedField `json:"fields,omitempty"`
+	Footer      *discordEmbedFooter `json:"footer,omitempty"`
+}
+
+type discordEmbedField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type discordEmbedFooter struct {
+	Text string `json:"text"`
+}
+
+type discordComponent struct {
+	Type       int                `json:"type"`
+	Style      int                `json:"style,omitempty"`
+	Label      string             `json:"label,omitempty"`
+	CustomID   string             `json:"custom_id,omitempty"`
+	URL        string             `json:"url,omitempty"`
+	Components []discordComponent `json:"components,omitempty"`
+}
+
+type discordMessage struct {
+	Content    string             `json:"content,omitempty"`
+	Embeds     []discordEmbed     `json:"embeds,omitempty"`
+	Components []discordComponent `json:"components"`
+	Flags      int                `json:"flags,omitempty"`
+}
+
+type discordUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+type discordInteraction struct {
+	Type int `json:"type"`
+	Data struct {
+		CustomID string `json:"custom_id"`
+	} `json:"data"`
+	Member *struct {
+		User  discordUser `json:"user"`
+		Roles []string    `json:"roles"`
+	} `json:"member"`
+	User    *discordUser `json:"user"`
+	Message struct {
+		Embeds []discordEmbed `json:"embeds"`
+	} `json:"message"`
+}
+
+// buildDiscordEmbed renders the question card shared by both modes
+func buildDiscordEmbed(question string, options []string, remoteURL string, requestID string) discordEmbed {
+	embed := discordEmbed{
+		Title:       "🤖 Agent Paused",
+		Description: truncateRunes(question, 4000),
+		URL:         remoteURL,
+		Color:       0x667eea,
+		Footer:      &discordEmbedFooter{Text: "Request " + requestID},
+	}
+	if len(options) > 0 {
+		lines := make([]string, len(options))
+		for i, opt := range options {
+			lines[i] = fmt.Sprintf("%d. %s", i+1, opt)
+		}
+		embed.Fields = []discordEmbedField{{Name: "Options", Value: truncateRunes(strings.Join(lines, "\n"), 1024)}}
+	}
+	return embed
+}
+
+// sendDiscordWebhook posts an embed with a link to the response page
+func sendDiscordWebhook(question string, options []string, remoteURL string, requestID string) error {
+	msg := discordMessage{
+		Content:    "👉 [Tap to Decide](" + remoteURL + ")",
+		Embeds:     []discordEmbed{buildDiscordEmbed(question, options, remoteURL, requestID)},
+		Components: []discordComponent{},
+	}
+	return discordPost(notifyConfig.DiscordWebhookURL, "", msg)
+}
+
+// sendDiscordBot posts a message with one button per option; clicks arrive at /discord/interactions
+func sendDiscordBot(question string, options []string, remoteURL string, requestID string) error {
+	// The custom answer link always keeps its slot; options that don't fit are
+	// left to the response page
+	hasLink := strings.HasPrefix(remoteURL, "https://")
+	limit := discordMaxButtons
+	if hasLink {
+		limit--
+	}
+	shown := options
+	if len(shown) > limit {
+		shown = shown[:limit]
+	}
+
+	var buttons []discordComponent
+	for i, opt := range shown {
+		buttons = append(buttons, discordComponent{
+			Type:     discordComponentButton,
+			Style:    discordButtonPrimary,
+			Label:    truncateRunes(opt, 80),
+			CustomID: fmt.Sprintf("%s:%s:%d", discordCustomIDPrefix, requestID, i),
+		})
+	}
+	if hasLink {
+		buttons = append(buttons, discordComponent{
+			Type:  discordComponentButton,
+			Style: discordButtonLink,
+			Label: "Custom answer",
+			URL:   remoteURL,
+		})
+	}
+
+	// Discord allows 5 buttons per row and 5 rows per message
+	var rows []discordComponent
+	for i := 0; i < len(buttons); i += 5 {
+		end := i + 5
+		if end > len(buttons) {
+			end = len(buttons)
+		}
+		rows = append(rows, discordComponent{Type: discordComponentActionRow, Components: buttons[i:end]})
+	}
+
+	embed := buildDiscordEmbed(question, options, remoteURL, requestID)
+	if len(shown) < len(options) {
+		note := fmt.Sprintf("Only the first %d of %d options have buttons.", len(shown), len(options))
+		if hasLink {
+			note += " Pick any of them on the response page via **Custom answer**."
+		}
+		embed.Fields = append(embed.Fields, discordEmbedField{Name: "More options", Value: note})
+	}
+
+	msg := discordMessage{
+		Embeds:     []discordEmbed{embed},
+		Components: rows,
+	}
+	endpoint := fmt.Sprintf("%s/channels/%s/messages", discordAPIBase, notifyConfig.DiscordChannelID)
+	return discordPost(endpoint, "Bot "+notifyConfig.DiscordBotToken, msg)
+}
+
+// discordPost sends a JSON payload to the Discord API
+func discordPost(endpoint string, authorization string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := discordClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("Discord API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// handleDiscordInteraction is the Interactions Endpoint URL for bot mode button clicks
+func handleDiscordInteraction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "POST required", 405)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "Bad request", 400)
+		return
+	}
+
+	// Discord requires Ed25519 verification and probes the endpoint with bad signatures
+	if !verifyDiscordSignature(notifyConfig.DiscordPublicKey, r.Header.Get("X-Signature-Ed25519"), r.Header.Get("X-Signature-Timestamp"), body) {
+		logInfo("🚫 Rejected Discord interaction with invalid signature")
+		http.Error(w, "invalid request signature", 401)
+		return
+	}
+
+	var interaction discordInteraction
+	if err := json.Unmarshal(body, &interaction); err != nil {
+		http.Error(w, "Invalid JSON", 400)
+		return
+	}
+
+	switch interaction.Type {
+	case discordInteractionPing:
+		writeDiscordResponse(w, discordResponsePong, nil)
+		return
+	case discordInteractionComponent:
+	default:
+		http.Error(w, "Unsupported interaction", 400)
+		return
+	}
+
+	if !discordUserAllowed(interaction) {
+		logInfo(fmt.Sprintf("🚫 Ignored Discord button from %s (%s): not in DISCORD_ALLOWED_USERS/ROLES", discordUserName(interaction), discordUserID(interaction)))
+		writeDiscordResponse(w, discordResponseChannelMsg, &discordMessage{
+			Content: "⛔ You are not allowed to answer this request.",
+			Flags:   discordFlagEphemeral,
+		})
+		return
+	}
+
+	parts := strings.Split(interaction.Data.CustomID, ":")
+	if len(parts) != 3 || parts[0] != discordCustomIDPrefix {
+		http.Error(w, "Unknown component", 400)
+		return
+	}
+	reqID := parts[1]
+	index, _ := strconv.Atoi(parts[2])
+
+	val, ok := requestDetails.Load(reqID)
+	details, _ := val.(RequestDetails)
+	if !ok || index < 0 || index >= len(details.Options) {
+		writeDiscordResponse(w, discordResponseChannelMsg, &discordMessage{
+			Content: "⌛ This request has expired or was already answered.",
+			Flags:   discordFlagEphemeral,
+		})
+		return
+	}
+
+	if details.SecondFactor {
+		logInfo(fmt.Sprintf("🔐 Ignored Discord button for high-risk %s", reqID))
+		writeDiscordResponse(w, discordResponseChannelMsg, &discordMessage{
+			Content: secondFactorHint,
+			Flags:   discordFlagEphemeral,
+		})
+		return
+	}
+
+	answer := details.Options[index]
+	user := discordUserName(interaction)
+	if !resolveRequest(reqID, responseui.Vote{Answer: answer, Via: "discord", User: user}) {
+		writeDiscordResponse(w, discordResponseChannelMsg, &discordMessage{
+			Content: "⌛ This request was already answered.",
+			Flags:   discordFlagEphemeral,
+		})
+		return
+	}
+
+	logInfo(fmt.Sprintf("📥 Discord response from %s: %s -> %s", user, reqID, answer))
+
+	// Replace the buttons with the outcome so nobody answers twice
+	writeDiscordResponse(w, discordResponseUpdateMessage, &discordMessage{
+		Content:    fmt.Sprintf("✅ **%s** — answered by %s at %s", answer, user, time.Now().Format("15:04")),
+		Embeds:     interaction.Message.Embeds,
+		Components: []discordComponent{},
+	})
+}
+
+// discordUserAllowed reports whether the person who pressed a button is listed
+// in DISCORD_ALLOWED_USERS or holds a role in DISCORD_ALLOWED_ROLES. With
+// neither set nobody may answer from Discord.
+func discordUserAllowed(interaction discordInteraction) bool {
+	if id := discordUserID(interaction); listContains(notifyConfig.DiscordAllowedUsers, id) {
+		return true
+	}
+	if interaction.Member != nil {
+		for _, role := range interaction.Member.Roles {
+			if listContains(notifyConfig.DiscordAllowedRoles, role) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// discordUserID is the ID of whoever pressed the button, in a guild or a DM
+func discordUserID(interaction discordInteraction) string {
+	if interaction.Member != nil {
+		return interaction.Member.User.ID
+	}
+	if interaction.User != nil {
+		return interaction.User.ID
+	}
+	return ""
+}
+
+// discordUserName is the username of whoever pressed the button
+func discordUserName(interaction discordInteraction) string {
+	if interaction.Member != nil {
+		return interaction.Member.User.Username
+	}
+	if interaction.User != nil {
+		return interaction.User.Username
+	}
+	return "unknown"
+}
+
+// listContains reports whether a comma-separated list holds value
+func listContains(list, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, item := range strings.Split(list, ",") {
+		if strings.TrimSpace(item) == value {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyDiscordSignature checks the Ed25519 signature over timestamp + body
+func verifyDiscordSignature(publicKeyHex, signatureHex, timestamp string, body []byte) bool {
+	publicKey, err := hex.DecodeString(publicKeyHex)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return false
+	}
+	signature, err := hex.DecodeString(signatureHex)
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return false
+	}
+	return ed25519.Verify(ed25519.PublicKey(publicKey), append([]byte(timestamp), body...), signature)
+}
+
+// writeDiscordResponse replies to an interaction callback
+func writeDiscordResponse(w http.ResponseWriter, responseType int, data *discordMessage) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Type int             `json:"type"`
+		Data *discordMessage `json:"data,omitempty"`
+	}{responseType, data})
+}
+
+// truncateRunes shortens s to at most n runes, marking the cut with an ellipsis
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
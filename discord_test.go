@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVerifyDiscordSignature(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	otherPublic, _, _ := ed25519.GenerateKey(rand.Reader)
+	const timestamp = "1700000000"
+	body := []byte(`{"type":1}`)
+	signature := hex.EncodeToString(ed25519.Sign(private, append([]byte(timestamp), body...)))
+
+	tests := []struct {
+		name      string
+		publicKey string
+		signature string
+		timestamp string
+		body      string
+		want      bool
+	}{
+		{"valid", hex.EncodeToString(public), signature, timestamp, string(body), true},
+		{"tampered body", hex.EncodeToString(public), signature, timestamp, `{"type":3}`, false},
+		{"other timestamp", hex.EncodeToString(public), signature, "1700000001", string(body), false},
+		{"other key", hex.EncodeToString(otherPublic), signature, timestamp, string(body), false},
+		{"no key", "", signature, timestamp, string(body), false},
+		{"key not hex", "zz", signature, timestamp, string(body), false},
+		{"short key", hex.EncodeToString(public[:16]), signature, timestamp, string(body), false},
+		{"no signature", hex.EncodeToString(public), "", timestamp, string(body), false},
+		{"short signature", hex.EncodeToString(public), signature[:64], timestamp, string(body), false},
+	}
+	for _, tt := range tests {
+		if got := verifyDiscordSignature(tt.publicKey, tt.signature, tt.timestamp, []byte(tt.body)); got != tt.want {
+			t.Errorf("%s: verifyDiscordSignature = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiscordUserAllowed(t *testing.T) {
+	member := func(id string, roles ...string) discordInteraction {
+		var i discordInteraction
+		json.Unmarshal([]byte(`{"member":{"user":{"id":"`+id+`","username":"u"},"roles":[]}}`), &i)
+		i.Member.Roles = roles
+		return i
+	}
+	dm := discordInteraction{User: &discordUser{ID: "42", Username: "ana"}}
+
+	tests := []struct {
+		name        string
+		users       string
+		roles       string
+		interaction discordInteraction
+		want        bool
+	}{
+		{"listed user", "7, 42", "", dm, true},
+		{"listed member", "42", "", member("42"), true},
+		{"member with a listed role", "", "ops,admins", member("9", "admins"), true},
+		{"member without a listed role", "", "ops", member("9", "dev"), false},
+		{"unlisted user", "7", "ops", dm, false},
+		{"nothing configured", "", "", dm, false},
+		{"nobody", "7", "ops", discordInteraction{}, false},
+	}
+	for _, tt := range tests {
+		notifyConfig.DiscordAllowedUsers, notifyConfig.DiscordAllowedRoles = tt.users, tt.roles
+		if got := discordUserAllowed(tt.interaction); got != tt.want {
+			t.Errorf("%s: discordUserAllowed = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestHandleDiscordInteraction posts signed interactions the way Discord does
+func TestHandleDiscordInteraction(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	notifyConfig.DiscordPublicKey = hex.EncodeToString(public)
+	notifyConfig.DiscordAllowedUsers, notifyConfig.DiscordAllowedRoles = "42", ""
+
+	ch := make(chan string, 1)
+	pendingRequests.Store("d1", ch)
+	requestDetails.Store("d1", RequestDetails{Question: "Deploy?", Options: []string{"Yes", "No"}})
+	defer pendingRequests.Delete("d1")
+	defer requestDetails.Delete("d1")
+
+	post := func(body string, sign bool) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/discord/interactions", strings.NewReader(body))
+		r.Header.Set("X-Signature-Timestamp", "1700000000")
+		if sign {
+			r.Header.Set("X-Signature-Ed25519", hex.EncodeToString(ed25519.Sign(private, []byte("1700000000"+body))))
+		}
+		w := httptest.NewRecorder()
+		handleDiscordInteraction(w, r)
+		return w
+	}
+	press := func(userID, customID string) string {
+		return `{"type":3,"data":{"custom_id":"` + customID + `"},"user":{"id":"` + userID + `","username":"ana"}}`
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		sign     bool
+		wantCode int
+		wantBody string
+		answered bool
+	}{
+		{"unsigned", `{"type":1}`, false, 401, "", false},
+		{"ping", `{"type":1}`, true, 200, `"type":1`, false},
+		{"not allowed", press("7", "momentum:d1:0"), true, 200, "not allowed", false},
+		{"option out of range", press("42", "momentum:d1:5"), true, 200, "expired or was already answered", false},
+		{"unknown request", press("42", "momentum:zz:0"), true, 200, "expired or was already answered", false},
+		{"other component", press("42", "other:d1:0"), true, 400, "", false},
+		{"answer", press("42", "momentum:d1:0"), true, 200, "answered by ana", true},
+	}
+	for _, tt := range tests {
+		w := post(tt.body, tt.sign)
+		if w.Code != tt.wantCode || !bytes.Contains(w.Body.Bytes(), []byte(tt.wantBody)) {
+			t.Errorf("%s: %d %s", tt.name, w.Code, w.Body.String())
+		}
+		if answered := len(ch) == 1; answered != tt.answered {
+			t.Errorf("%s: answered = %v", tt.name, answered)
+		}
+	}
+	if answer := <-ch; answer != "Yes" {
+		t.Errorf("answer = %q", answer)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui/links"
+	"github.com/HarshalPatel1972/remote-bridge/responseui/requestlog"
+)
+
+var (
+	linkSignerOnce sync.Once
+	signer         *links.Signer
+
+	// linkMu is held from checking a link to spending it, so it answers once
+	linkMu    sync.Mutex
+	usedLinks sync.Map // requestlog.LinkID -> when the link expires anyway
+)
+
+// linkSigner signs response links with the key next to bridge-config.json,
+// which the desktop app shares
+func linkSigner() *links.Signer {
+	linkSignerOnce.Do(func() {
+		signer = &links.Signer{Path: filepath.Join(filepath.Dir(configPath), links.KeyFileName)}
+		if _, err := signer.Key(); err != nil {
+			logInfo(fmt.Sprintf("⚠️ Could not save link key, links will stop working on restart: %v", err))
+		}
+	})
+	return signer
+}
+
+// responseLink builds a signed link to path for a request. With an answer the
+// link submits it; without one it opens the response form.
+func responseLink(baseURL, path, requestID, answer string, expires time.Time) string {
+	token := linkSigner().Sign(links.Claims{RequestID: requestID, Answer: answer, Expires: expires.Unix()})
+	return baseURL + path + "?" + url.Values{"t": {token}}.Encode()
+}
+
+// linkExpiry is when a request's links stop working
+func linkExpiry(requestID string) time.Time {
+	if r, ok := storedRequest(requestID); ok && !r.Deadline.IsZero() {
+		return r.Deadline
+	}
+	return time.Now().Add(requestTimeout(nil))
+}
+
+// linkUsed reports whether a token was spent on a request, by this process or,
+// as the request log records, before a restart
+func linkUsed(requestID, token string) bool {
+	id := requestlog.LinkID(token)
+	if _, used := usedLinks.Load(id); used {
+		return true
+	}
+	r, _ := storedRequest(requestID)
+	return containsOption(r.UsedLinks, id)
+}
+
+// spendLink marks a token as used, in memory and in the request log
+func spendLink(requestID, token string, expires time.Time) {
+	now := time.Now()
+	usedLinks.Range(func(id, exp interface{}) bool {
+		if now.After(exp.(time.Time)) {
+			usedLinks.Delete(id)
+		}
+		return true
+	})
+	id := requestlog.LinkID(token)
+	usedLinks.Store(id, expires)
+	updateRequest(requestID, func(r *StoredRequest) { r.UsedLinks = append(r.UsedLinks, id) })
+}
+
+// checkLink verifies the t parameter, writing an error page if it doesn't hold up
+func checkLink(w http.ResponseWriter, r *http.Request) (links.Claims, string, bool) {
+	token := r.URL.Query().Get("t")
+	claims, err := linkSigner().Verify(token, time.Now())
+	switch err {
+	case nil:
+		return claims, token, true
+	case links.ErrExpired:
+		responsePages().Expired(w, "link_expired")
+	default:
+		responsePages().Error(w, 403, "link_invalid")
+	}
+	return claims, token, false
+}
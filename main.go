@@ -4,7 +4,7 @@
 //
 // Phase 2 Features:
 // - Ngrok tunnel for public HTTPS access
-// - Telegram notifications when agent pauses
+// - Telegram notifications when agent pauses (answerable in-chat)
 // - Request ID tracking for concurrent requests
 package main
 
@@ -18,9 +18,12 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/HarshalPatel1972/remote-bridge/responseui"
+	"github.com/HarshalPatel1972/remote-bridge/responseui/requestlog"
 	"github.com/fsnotify/fsnotify"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/google/uuid"
@@ -54,9 +57,23 @@ type BridgeUIConfig struct {
 	TelegramToken   string `json:"telegramToken"`
 	TelegramChatID  string `json:"telegramChatId"`
 	DiscordWebhook  string `json:"discordWebhook"`
+	DiscordBotToken string `json:"discordBotToken"`
+	DiscordChannel  string `json:"discordChannelId"`
+	DiscordPubKey   string `json:"discordPublicKey"`
+	DiscordUsers    string `json:"discordAllowedUsers"` // Comma-separated user IDs allowed to press buttons
+	DiscordRoles    string `json:"discordAllowedRoles"` // Comma-separated role IDs allowed to press buttons
+	NtfyServer      string `json:"ntfyServer"`
+	NtfyTopic       string `json:"ntfyTopic"`
+	NtfyToken       string `json:"ntfyToken"`
 	WhatsappEnabled bool   `json:"whatsappEnabled"`
 	WhatsappKey     string `json:"whatsappKey"`
 	UserPhone       string `json:"userPhone"`
+	RequestTimeout  int    `json:"requestTimeoutSeconds"`
+	SecondFactor    struct {
+		PIN        string `json:"pin"`
+		TOTPSecret string `json:"totpSecret"`
+	} `json:"secondFactor"`
+	ResponsePage responseui.Config `json:"responsePage"` // Brand, language and theme of the response pages
 }
 
 func main() {
@@ -73,6 +90,10 @@ func main() {
 	// Initial Config Load
 	loadAndApplyConfig()
 
+	// Reload requests that were pending when the bridge last stopped
+	openRequestStore()
+	openFactorLimiter()
+
 	// Start Config Watcher (Hot Reload)
 	go watchConfig()
 	
@@ -158,13 +179,67 @@ func loadAndApplyConfig() {
 	}
 
 	// update env vars
-	if cfg.NgrokToken != "" { os.Setenv("NGROK_AUTHTOKEN", cfg.NgrokToken) }
-	if cfg.TelegramToken != "" { os.Setenv("TELEGRAM_BOT_TOKEN", cfg.TelegramToken) }
-	if cfg.TelegramChatID != "" { os.Setenv("TELEGRAM_CHAT_ID", cfg.TelegramChatID) }
-	if cfg.DiscordWebhook != "" { os.Setenv("DISCORD_WEBHOOK_URL", cfg.DiscordWebhook) }
-	if cfg.WhatsappKey != "" { os.Setenv("WHATSAPP_API_KEY", cfg.WhatsappKey) }
-	if cfg.UserPhone != "" { os.Setenv("USER_PHONE", cfg.UserPhone) }
-	
+	if cfg.NgrokToken != "" {
+		os.Setenv("NGROK_AUTHTOKEN", cfg.NgrokToken)
+	}
+	if cfg.TelegramToken != "" {
+		os.Setenv("TELEGRAM_BOT_TOKEN", cfg.TelegramToken)
+	}
+	if cfg.TelegramChatID != "" {
+		os.Setenv("TELEGRAM_CHAT_ID", cfg.TelegramChatID)
+	}
+	if cfg.DiscordWebhook != "" {
+		os.Setenv("DISCORD_WEBHOOK_URL", cfg.DiscordWebhook)
+	}
+	if cfg.DiscordBotToken != "" {
+		os.Setenv("DISCORD_BOT_TOKEN", cfg.DiscordBotToken)
+	}
+	if cfg.DiscordChannel != "" {
+		os.Setenv("DISCORD_CHANNEL_ID", cfg.DiscordChannel)
+	}
+	if cfg.DiscordPubKey != "" {
+		os.Setenv("DISCORD_PUBLIC_KEY", cfg.DiscordPubKey)
+	}
+	if cfg.DiscordUsers != "" {
+		os.Setenv("DISCORD_ALLOWED_USERS", cfg.DiscordUsers)
+	}
+	if cfg.DiscordRoles != "" {
+		os.Setenv("DISCORD_ALLOWED_ROLES", cfg.DiscordRoles)
+	}
+	if cfg.NtfyServer != "" {
+		os.Setenv("NTFY_SERVER", cfg.NtfyServer)
+	}
+	if cfg.NtfyTopic != "" {
+		os.Setenv("NTFY_TOPIC", cfg.NtfyTopic)
+	}
+	if cfg.NtfyToken != "" {
+		os.Setenv("NTFY_TOKEN", cfg.NtfyToken)
+	}
+	if cfg.WhatsappKey != "" {
+		os.Setenv("WHATSAPP_API_KEY", cfg.WhatsappKey)
+	}
+	if cfg.UserPhone != "" {
+		os.Setenv("USER_PHONE", cfg.UserPhone)
+	}
+	if cfg.RequestTimeout > 0 {
+		os.Setenv("REQUEST_TIMEOUT_SECONDS", strconv.Itoa(cfg.RequestTimeout))
+	}
+	if cfg.SecondFactor.PIN != "" {
+		os.Setenv("APPROVAL_PIN", cfg.SecondFactor.PIN)
+	}
+	if cfg.SecondFactor.TOTPSecret != "" {
+		os.Setenv("TOTP_SECRET", cfg.SecondFactor.TOTPSecret)
+	}
+	if cfg.ResponsePage.Brand != "" {
+		os.Setenv("RESPONSE_BRAND", cfg.ResponsePage.Brand)
+	}
+	if cfg.ResponsePage.Language != "" {
+		os.Setenv("RESPONSE_LANGUAGE", cfg.ResponsePage.Language)
+	}
+	if cfg.ResponsePage.Theme != "" {
+		os.Setenv("RESPONSE_THEME", cfg.ResponsePage.Theme)
+	}
+
 	initNotifications() // Reload notification settings
 	logInfo("✅ Configuration Applied")
 }
@@ -256,6 +331,7 @@ func startHTTPServer() {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", handleHTTPRequest)
 	mux.HandleFunc("/submit", handleHTTPSubmit)
+	mux.HandleFunc("/discord/interactions", handleDiscordInteraction)
 
 	httpServer = &http.Server{Addr: fmt.Sprintf("127.0.0.1:%d", httpPort), Handler: mux}
 	
@@ -271,48 +347,48 @@ func startMCPServer() {
 	askTool := mcp.NewTool("ask_remote_human",
 		mcp.WithDescription("Ask the user a question via configured channels (Telegram/Discord/WhatsApp)"),
 		mcp.WithString("question", mcp.Required()),
-		mcp.WithArray("options", mcp.Required()),
+		mcp.WithArray("options", mcp.Description("Available response options (the choices for answer_schema types choice and multi)")),
+		mcp.WithNumber("timeout_seconds", mcp.Description("How long to wait for an answer (default 15 minutes)")),
+		mcp.WithString("default_option", mcp.Description("Option to use if nobody answers in time")),
+		mcp.WithString("risk_level", mcp.Enum("low", "medium", "high"), mcp.Description("How risky the action is; \"high\" (e.g. destructive operations) requires the user's PIN or authenticator code")),
+		mcp.WithString("details", mcp.Description("Markdown with more background, shown under the question on the response page")),
+		mcp.WithString("diff", mcp.Description("Unified diff of the proposed change, shown in a diff viewer (max 256 KB)")),
+		mcp.WithString("command", mcp.Description("Command line the agent wants to run")),
+		mcp.WithString("file_path", mcp.Description("Path of the file the question is about")),
+		mcp.WithString("file_snippet", mcp.Description("Excerpt of file_path to show, highlighted by its extension")),
+		mcp.WithArray("links", mcp.Description("Related http(s) URLs, such as a pull request or CI run")),
+		mcp.WithObject("answer_schema", mcp.Description(`Shape of the answer: {"type":"choice"}, {"type":"multi","min":1,"max":2}, {"type":"yes_no"}, {"type":"number","min":1,"max":10}, {"type":"text","pattern":"..."} or {"type":"form","fields":[{"name":"replicas","type":"number"}]}. The answer comes back as JSON with a typed "value".`)),
+		mcp.WithString("request_id", mcp.Description("ID of a request asked before the bridge restarted, to wait for its answer instead of asking again")),
 	)
 
 	s.AddTool(askTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		question, _ := request.RequireString("question")
-		args := request.GetArguments()
-		optionsSlice, _ := args["options"].([]interface{})
-		
-		var options []string
-		for _, o := range optionsSlice {
-			if s, ok := o.(string); ok { options = append(options, s) }
+		result, text, err := askHuman(ctx, request.GetArguments())
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return mcp.NewToolResultError("Cancelled"), nil
+		case err != nil:
+			return mcp.NewToolResultError(err.Error()), nil
 		}
+		return askToolResult(result, text), nil
+	})
 
-		reqID := uuid.New().String()[:8]
-		logInfo(fmt.Sprintf("🔔 Question: %s", question))
-		
-		// Wait for URL if Ngrok is restarting
-		if publicURL == "" {
-			time.Sleep(2 * time.Second)
+	s.AddTool(responseui.NewPermissionTool(), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+		action, _ := args["action"].(string)
+		if strings.TrimSpace(action) == "" {
+			return mcp.NewToolResultError("action is required"), nil
 		}
-		remoteURL := fmt.Sprintf("%s/?id=%s", publicURL, reqID)
-		
-		broadcastNotification(question, options, remoteURL, reqID)
+		logInfo("🛡️ Permission requested: " + action)
 
-		// Create response channel
-		respChan := make(chan string, 1)
-		pendingRequests.Store(reqID, respChan)
-		defer pendingRequests.Delete(reqID)
-		
-		// Details for HTTP handler
-		requestDetails.Store(reqID, RequestDetails{Question: question, Options: options})
-		defer requestDetails.Delete(reqID)
-
-		select {
-		case resp := <-respChan:
-			logInfo("✅ Response: " + resp)
-			return mcp.NewToolResultText("User Response: " + resp), nil
-		case <-time.After(15 * time.Minute):
-			return mcp.NewToolResultError("Timeout"), nil
-		case <-ctx.Done():
+		result, _, err := askHuman(ctx, permissionQuestion(args))
+		switch {
+		case err != nil && ctx.Err() != nil:
 			return mcp.NewToolResultError("Cancelled"), nil
+		case err != nil:
+			return mcp.NewToolResultError(err.Error()), nil
 		}
+		permission := newPermissionResult(result)
+		return mcp.NewToolResultStructured(permission, permission.Text()), nil
 	})
 
 	logInfo("📡 MCP Server listening on Stdio")
@@ -322,6 +398,152 @@ func startMCPServer() {
 	}
 }
 
+// askHuman asks one question with the ask_remote_human arguments and waits
+// for the answer, the timeout or ctx. text is the plain answer for the agent.
+func askHuman(ctx context.Context, args map[string]interface{}) (AskResult, string, error) {
+	question, _ := args["question"].(string)
+	optionsSlice, _ := args["options"].([]interface{})
+	defaultOption, _ := args["default_option"].(string)
+	riskLevel, _ := args["risk_level"].(string)
+	requestID, _ := args["request_id"].(string)
+	questionContext := responseui.ContextArguments(args)
+
+	var options []string
+	for _, o := range optionsSlice {
+		if s, ok := o.(string); ok {
+			options = append(options, s)
+		}
+	}
+	answerSchema, err := responseui.SchemaArguments(args, options)
+	if err == nil {
+		err = answerSchema.Validate()
+	}
+	if err != nil {
+		return AskResult{}, "", err
+	}
+	if answerSchema.Structured() {
+		if defaultOption != "" {
+			if defaultOption, err = answerSchema.Normalize(defaultOption); err != nil {
+				return AskResult{}, "", fmt.Errorf("default_option: %v", err)
+			}
+		}
+		if answerSchema.Type == responseui.TypeYesNo && len(options) == 0 {
+			options = []string{"Yes", "No"} // Buttons for the chat channels
+		}
+	} else if defaultOption != "" && len(options) > 0 && !containsOption(options, defaultOption) {
+		return AskResult{}, "", fmt.Errorf("default_option must be one of the options")
+	}
+	if riskLevel != "" && riskLevel != "low" && riskLevel != "medium" && riskLevel != "high" {
+		return AskResult{}, "", fmt.Errorf("risk_level must be low, medium or high")
+	}
+	if err := questionContext.Validate(); err != nil {
+		return AskResult{}, "", err
+	}
+	timeout := requestTimeout(args)
+
+	logInfo(fmt.Sprintf("🔔 Question: %s", question))
+
+	// A request restored from before a restart: wait for that one
+	// (it may already be answered) instead of notifying again
+	reqID, respChan, adopted, err := adoptOrphan(requestID, question, options)
+	if err != nil {
+		return AskResult{}, "", err
+	}
+	if adopted {
+		logInfo("♻️ Reattached to request " + reqID)
+		if r, ok := storedRequest(reqID); ok {
+			timeout = time.Until(r.Deadline)
+		}
+	} else {
+		reqID = uuid.New().String()[:8]
+		respChan = make(chan string, 1)
+	}
+	defer pendingRequests.Delete(reqID)
+	defer requestDetails.Delete(reqID)
+	defer telegramMessages.Delete(reqID)
+
+	if !adopted {
+		// Wait for URL if Ngrok is restarting
+		if publicURL == "" {
+			time.Sleep(2 * time.Second)
+		}
+		remoteURL := responseLink(publicURL, "/", reqID, "", time.Now().Add(timeout))
+
+		// Create response channel (before notifying, so in-chat buttons can resolve it)
+		pendingRequests.Store(reqID, respChan)
+
+		// High-risk answers need a PIN or TOTP code, which only the response page can take
+		secondFactor := riskLevel == "high" && secondFactorEnabled()
+		if riskLevel == "high" && !secondFactor {
+			logInfo("⚠️ High-risk question but no PIN or TOTP secret is configured")
+		}
+
+		// Details for HTTP handler
+		requestDetails.Store(reqID, RequestDetails{Question: question, Options: options, Context: questionContext, Schema: answerSchema, SecondFactor: secondFactor})
+		saveRequest(StoredRequest{
+			ID:           reqID,
+			Question:     question,
+			Options:      options,
+			Context:      requestlog.OptionalContext(questionContext),
+			Schema:       requestlog.OptionalSchema(answerSchema),
+			Status:       "pending",
+			CreatedAt:    time.Now(),
+			Deadline:     time.Now().Add(timeout),
+			SecondFactor: secondFactor,
+		})
+
+		// Phones get a summary of the context; the response page shows all of it
+		notice := answerSchema.WithHint(questionContext.WithSummary(question))
+		if secondFactor {
+			notice = secondFactorHint + "\n\n" + notice
+		}
+		broadcastNotification(notice, answerSchema.Buttons(options), remoteURL, reqID)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case resp := <-respChan:
+		logInfo("✅ Response: " + resp)
+		updateRequest(reqID, func(r *StoredRequest) { r.Delivered = true })
+		result := AskResult{RequestID: reqID, Answer: resp, Source: "human", Value: answerSchema.Value(resp)}
+		return result, "User Response: " + resp, nil
+	case <-timer.C:
+		logInfo(fmt.Sprintf("⌛ Request %s timed out after %s", reqID, timeout))
+		// A human answer may still win the race against the timeout. Whoever
+		// fills the one-answer channel first decides; later answers are refused.
+		result := AskResult{RequestID: reqID, Source: "human"}
+		if defaultOption == "" {
+			select {
+			case respChan <- "":
+				updateRequest(reqID, func(r *StoredRequest) { r.Status = "expired" })
+				result.Source, result.TimedOut = "timeout", true
+				return result, "No human answered before the timeout.", nil
+			default:
+			}
+		} else if resolveRequest(reqID, responseui.Vote{Answer: defaultOption, Via: "timeout"}) {
+			result.Source, result.TimedOut = "timeout", true
+			updateRequest(reqID, func(r *StoredRequest) { r.Status = "expired" })
+		}
+		result.Answer = <-respChan
+		updateRequest(reqID, func(r *StoredRequest) { r.Delivered = true })
+		result.Value = answerSchema.Value(result.Answer)
+		text := "User Response: " + result.Answer
+		if result.TimedOut {
+			text = fmt.Sprintf("Default Option: %s (no human answered before the timeout)", result.Answer)
+		}
+		return result, text, nil
+	case <-ctx.Done():
+		updateRequest(reqID, func(r *StoredRequest) {
+			if r.Status == "pending" {
+				r.Status = "cancelled"
+			}
+		})
+		return AskResult{}, "", ctx.Err()
+	}
+}
+
 // ----- Helper Functions (Telegram, etc. reuse previous logic) -----
 // (Assuming initTelegram, initNotifications, broadcastNotification, handleHTTPRequest 
 //  are mostly unchanged but using logInfo instead of fmt.Fprintf)
@@ -338,189 +560,187 @@ func initTelegram() error {
 	telegramBot, err = tgbotapi.NewBotAPI(token)
 	if err != nil { return err }
 	logInfo("🤖 Telegram Active: " + telegramBot.Self.UserName)
+	startTelegramUpdates(telegramBot)
 	return nil
 }
 
 // ... (Rest of HTTP handlers would be here, effectively same as before but cleaner)
 // For brevity in this tool call, I will include the HTTP handlers to ensure compilation.
 
-type RequestDetails struct { Question string; Options []string }
+type RequestDetails struct {
+	Question     string
+	Options      []string
+	Context      responseui.Context
+	Schema       responseui.Schema
+	SecondFactor bool
+}
+
+// AskResult is the structured ask_remote_human result
+type AskResult struct {
+	RequestID string `json:"request_id"`
+	Answer    string `json:"answer"`
+	Source    string `json:"source"` // "human" or "timeout"
+	TimedOut  bool   `json:"timed_out"`
+
+	Value interface{} `json:"value,omitempty"` // The answer as a typed value when the request had an answer schema
+}
+
+// askToolResult returns a result to the agent. Answers to an answer schema
+// come back as the result's JSON instead of text.
+func askToolResult(result AskResult, text string) *mcp.CallToolResult {
+	if result.Value != nil {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		text = string(data)
+	}
+	return mcp.NewToolResultStructured(result, text)
+}
+
+// requestTimeout reads timeout_seconds, falling back to REQUEST_TIMEOUT_SECONDS or 15 minutes
+func requestTimeout(args map[string]interface{}) time.Duration {
+	if seconds, ok := args["timeout_seconds"].(float64); ok && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("REQUEST_TIMEOUT_SECONDS")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 15 * time.Minute
+}
+
+func containsOption(options []string, option string) bool {
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
 var requestDetails sync.Map
 
+// resolveRequest delivers an answer that came via "telegram", "discord", "ntfy",
+// "web" or "timeout" without blocking and records who gave it; false if unknown
+// or already answered
+func resolveRequest(id string, vote responseui.Vote) bool {
+	ch, ok := pendingRequests.Load(id)
+	if !ok {
+		return false
+	}
+	if val, ok := requestDetails.Load(id); ok {
+		// Refuse answers that don't fit the answer schema; keep the rest in one form
+		normalized, err := val.(RequestDetails).Schema.Normalize(vote.Answer)
+		if err != nil {
+			logInfo(fmt.Sprintf("🚫 Ignored answer %q to %s: %v", vote.Answer, id, err))
+			return false
+		}
+		vote.Answer = normalized
+	}
+	vote.At = time.Now()
+	select {
+	case ch.(chan string) <- vote.Answer:
+		updateRequest(id, func(r *StoredRequest) {
+			r.Status, r.Answer, r.Via = "answered", vote.Answer, vote.Via
+			r.Votes = append(r.Votes, vote)
+		})
+		return true
+	default:
+		return false
+	}
+}
+
 func handleHTTPRequest(w http.ResponseWriter, r *http.Request) {
-	id := r.URL.Query().Get("id")
+	claims, token, ok := checkLink(w, r)
+	if !ok {
+		return
+	}
+	id := claims.RequestID
 	if val, ok := requestDetails.Load(id); ok {
 		details := val.(RequestDetails)
-		
-		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		
-		// Build option buttons HTML
-		optionsHTML := ""
-		for _, opt := range details.Options {
-			optionsHTML += fmt.Sprintf(`<button class="option-btn" onclick="submitAnswer('%s')">%s</button>`, opt, opt)
+		if details.SecondFactor {
+			responsePages().SecondFactor(w, 200, token, details.page(), "", "")
+			return
 		}
 		
-		fmt.Fprintf(w, `<!DOCTYPE html>
-<html>
-<head>
-	<meta charset="UTF-8">
-	<meta name="viewport" content="width=device-width, initial-scale=1.0">
-	<title>Remote Bridge - Respond</title>
-	<style>
-		body {
-			font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
-			background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%);
-			min-height: 100vh;
-			display: flex;
-			align-items: center;
-			justify-content: center;
-			margin: 0;
-			padding: 20px;
-		}
-		.container {
-			background: white;
-			border-radius: 20px;
-			box-shadow: 0 20px 60px rgba(0,0,0,0.3);
-			max-width: 500px;
-			width: 100%%;
-			padding: 40px;
-		}
-		h1 {
-			color: #333;
-			margin: 0 0 10px 0;
-			font-size: 24px;
-		}
-		.subtitle {
-			color: #666;
-			margin: 0 0 30px 0;
-			font-size: 14px;
-		}
-		.question {
-			background: #f8f9fa;
-			padding: 20px;
-			border-radius: 10px;
-			margin-bottom: 30px;
-			color: #333;
-			font-size: 16px;
-			line-height: 1.5;
-		}
-		.options {
-			display: flex;
-			flex-direction: column;
-			gap: 10px;
-			margin-bottom: 20px;
-		}
-		.option-btn {
-			background: #667eea;
-			color: white;
-			border: none;
-			padding: 15px 20px;
-			border-radius: 10px;
-			font-size: 16px;
-			cursor: pointer;
-			transition: all 0.3s;
-		}
-		.option-btn:hover {
-			background: #5568d3;
-			transform: translateY(-2px);
-			box-shadow: 0 5px 15px rgba(102, 126, 234, 0.4);
+		responsePages().Question(w, 200, token, details.page(), "")
+	} else {
+		writeRequestGone(w, id)
+	}
+}
+
+func handleHTTPSubmit(w http.ResponseWriter, r *http.Request) {
+	claims, token, ok := checkLink(w, r)
+	if !ok {
+		return
+	}
+	id := claims.RequestID
+	resp := claims.Answer // Option links carry their answer; the form sends its own
+	if resp == "" {
+		resp = strings.TrimSpace(r.FormValue("response"))
+	}
+	if resp == "" {
+		resp = strings.TrimSpace(r.FormValue("custom")) // Free text on the second-factor page
+	}
+	if _, ok := pendingRequests.Load(id); ok {
+		val, _ := requestDetails.Load(id)
+		details, _ := val.(RequestDetails)
+		if claims.Answer != "" && r.Method != "POST" && !details.SecondFactor {
+			// Option links are opened by link previews and mail scanners too, so
+			// they only answer from the confirm page's POST
+			responsePages().Confirm(w, token, details.page(), resp)
+			return
 		}
-		.divider {
-			text-align: center;
-			margin: 20px 0;
-			color: #999;
-			font-size: 14px;
+		if details.Schema.Structured() && claims.Answer == "" && r.Method == "POST" {
+			// Typed answers come from the schema's inputs; show the form again if they don't fit
+			var err error
+			if resp, err = details.Schema.FromForm(r.PostForm, "response"); err != nil {
+				pages := responsePages()
+				if details.SecondFactor {
+					pages.SecondFactor(w, 400, token, details.page(), "", pages.InvalidAnswer(err))
+				} else {
+					pages.Question(w, 400, token, details.page(), pages.InvalidAnswer(err))
+				}
+				return
+			}
 		}
-		.custom-input {
-			width: 100%%;
-			padding: 15px;
-			border: 2px solid #e0e0e0;
-			border-radius: 10px;
-			font-size: 16px;
-			box-sizing: border-box;
-			margin-bottom: 10px;
+		if resp != "" {
+			if _, err := details.Schema.Normalize(resp); err != nil {
+				pages := responsePages()
+				if details.SecondFactor {
+					pages.SecondFactor(w, 400, token, details.page(), "", pages.InvalidAnswer(err))
+				} else {
+					pages.Question(w, 400, token, details.page(), pages.InvalidAnswer(err))
+				}
+				return
+			}
 		}
-		.custom-input:focus {
-			outline: none;
-			border-color: #667eea;
+		if details.SecondFactor {
+			if !checkSecondFactor(w, r, token, id, details, resp) {
+				return
+			}
 		}
-		.submit-btn {
-			width: 100%%;
-			background: #764ba2;
-			color: white;
-			border: none;
-			padding: 15px;
-			border-radius: 10px;
-			font-size: 16px;
-			cursor: pointer;
-			font-weight: 600;
+		if resp == "" {
+			responsePages().Question(w, 400, token, details.page(), "")
+			return
 		}
-		.submit-btn:hover {
-			background: #653a8a;
+
+		// The link is spent only once the answer counts
+		linkMu.Lock()
+		defer linkMu.Unlock()
+		if linkUsed(id, token) {
+			responsePages().Error(w, 409, "link_used")
+			return
 		}
-	</style>
-</head>
-<body>
-	<div class="container">
-		<h1>🤖 Agent Question</h1>
-		<p class="subtitle">Please provide your response</p>
-		<div class="question">%s</div>
-		<div class="options">%s</div>
-		<div class="divider">OR</div>
-		<input type="text" id="customAnswer" class="custom-input" placeholder="Type your custom answer...">
-		<button class="submit-btn" onclick="submitCustom()">Send Custom Answer</button>
-	</div>
-	<script>
-		function submitAnswer(answer) {
-			window.location.href = '/submit?id=%s&response=' + encodeURIComponent(answer);
+		// ntfy's buttons post option links straight here
+		via := "web"
+		if r.Header.Get(ntfyViaHeader) == "ntfy" {
+			via = "ntfy"
 		}
-		function submitCustom() {
-			const custom = document.getElementById('customAnswer').value;
-			if (custom.trim()) {
-				window.location.href = '/submit?id=%s&response=' + encodeURIComponent(custom);
-			} else {
-				alert('Please enter an answer');
-			}
+		if !resolveRequest(id, responseui.Vote{Answer: resp, Via: via, ClientIP: clientIP(r)}) {
+			responsePages().Error(w, 409, "answered")
+			return
 		}
-	</script>
-</body>
-</html>`, details.Question, optionsHTML, id, id)
-	} else {
-		http.NotFound(w, r)
-	}
-}
-
-func handleHTTPSubmit(w http.ResponseWriter, r *http.Request) {
-	id := r.URL.Query().Get("id")
-	resp := r.FormValue("response")
-	if resp == "" {
-		resp = r.URL.Query().Get("response") // Also check URL params for JS submissions
-	}
-	if ch, ok := pendingRequests.Load(id); ok {
-		ch.(chan string) <- resp
-		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		fmt.Fprintf(w, `<!DOCTYPE html>
-<html>
-<head>
-	<meta charset="UTF-8">
-	<style>
-		body { font-family: sans-serif; background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); min-height: 100vh; display: flex; align-items: center; justify-content: center; margin: 0; }
-		.container { background: white; border-radius: 20px; padding: 40px; text-align: center; box-shadow: 0 20px 60px rgba(0,0,0,0.3); }
-		h1 { color: #4CAF50; margin: 0 0 20px 0; }
-		p { color: #666; margin: 10px 0; }
-		.answer { background: #f8f9fa; padding: 15px; border-radius: 10px; margin: 20px 0; color: #333; font-weight: 600; }
-	</style>
-</head>
-<body>
-	<div class="container">
-		<h1>✅ Response Sent!</h1>
-		<p>You answered:</p>
-		<div class="answer">%s</div>
-		<p>You can close this window.</p>
-	</div>
-</body>
-</html>`, resp)
+		spendLink(id, token, time.Unix(claims.Expires, 0))
+		responsePages().Confirmation(w, details.Schema.Display(resp))
 	} else {
-		http.Error(w, "Request not found or expired", 404)
+		writeRequestGone(w, id)
 	}
 }
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui"
+)
+
+// startRequestStore gives a test its own request store and no notification channels
+func startRequestStore(t *testing.T) {
+	t.Helper()
+	configPath = filepath.Join(t.TempDir(), "bridge-config.json")
+	publicURL = "https://bridge.example"
+	notifyConfig = NotificationConfig{}
+	openRequestStore()
+	t.Cleanup(func() { requestLog = nil })
+}
+
+func TestAskHumanTimeout(t *testing.T) {
+	startRequestStore(t)
+
+	tests := []struct {
+		name       string
+		args       map[string]interface{}
+		wantAnswer string
+		wantStatus string
+		wantText   string
+	}{
+		{
+			name:       "default option",
+			args:       map[string]interface{}{"default_option": "No"},
+			wantAnswer: "No",
+			wantStatus: "expired",
+			wantText:   "Default Option: No (no human answered before the timeout)",
+		},
+		{
+			name:       "no default",
+			args:       map[string]interface{}{},
+			wantStatus: "expired",
+			wantText:   "No human answered before the timeout.",
+		},
+		{
+			name:       "default normalized by the schema",
+			args:       map[string]interface{}{"answer_schema": map[string]interface{}{"type": "yes_no"}, "default_option": "N"},
+			wantAnswer: "no",
+			wantStatus: "expired",
+			wantText:   "Default Option: no (no human answered before the timeout)",
+		},
+	}
+
+	for _, tt := range tests {
+		tt.args["question"] = "Deploy?"
+		tt.args["options"] = []interface{}{"Yes", "No"}
+		tt.args["timeout_seconds"] = 0.05
+		result, text, err := askHuman(context.Background(), tt.args)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !result.TimedOut || result.Source != "timeout" || result.Answer != tt.wantAnswer || text != tt.wantText {
+			t.Errorf("%s: got %+v, %q", tt.name, result, text)
+		}
+		stored, _ := storedRequest(result.RequestID)
+		if stored.Status != tt.wantStatus {
+			t.Errorf("%s: status = %q, want %q", tt.name, stored.Status, tt.wantStatus)
+		}
+		// The request is closed: a late answer is refused and doesn't overwrite the outcome
+		if resolveRequest(result.RequestID, responseui.Vote{Answer: "Yes", Via: "web"}) {
+			t.Errorf("%s: late answer accepted", tt.name)
+		}
+		if stored, _ := storedRequest(result.RequestID); stored.Status != tt.wantStatus {
+			t.Errorf("%s: status after late answer = %q", tt.name, stored.Status)
+		}
+	}
+}
+
+// TestAskHumanAnswerBeatsTimeout checks that an answer recorded before the
+// timer fires is returned, not replaced by the timeout
+func TestAskHumanAnswerBeatsTimeout(t *testing.T) {
+	startRequestStore(t)
+
+	for _, defaultOption := range []string{"", "No"} {
+		done := make(chan AskResult, 1)
+		go func() {
+			result, _, _ := askHuman(context.Background(), map[string]interface{}{
+				"question":        "Deploy?",
+				"options":         []interface{}{"Yes", "No"},
+				"default_option":  defaultOption,
+				"timeout_seconds": 0.2,
+			})
+			done <- result
+		}()
+
+		var id string
+		for deadline := time.Now().Add(time.Second); id == "" && time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+			requestDetails.Range(func(key, _ interface{}) bool {
+				id = key.(string)
+				return false
+			})
+		}
+		if !resolveRequest(id, responseui.Vote{Answer: "Yes", Via: "web"}) {
+			t.Fatalf("default %q: answer to %q refused", defaultOption, id)
+		}
+
+		result := <-done
+		if result.TimedOut || result.Answer != "Yes" || result.Source != "human" {
+			t.Errorf("default %q: got %+v", defaultOption, result)
+		}
+		if stored, _ := storedRequest(id); stored.Status != "answered" || !stored.Delivered {
+			t.Errorf("default %q: stored %s, delivered %v", defaultOption, stored.Status, stored.Delivered)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -91,11 +92,101 @@ func main() {
 									},
 									"options": map[string]interface{}{
 										"type":        "array",
-										"description": "Available response options",
+										"description": "Available response options (the choices for answer_schema types choice and multi)",
 										"items":       map[string]interface{}{"type": "string"},
 									},
+									"category": map[string]interface{}{
+										"type":        "string",
+										"description": "Optional category used to route the question to approvers",
+									},
+									"timeout_seconds": map[string]interface{}{
+										"type":        "number",
+										"description": "How long to wait for an answer (default 15 minutes)",
+									},
+									"default_option": map[string]interface{}{
+										"type":        "string",
+										"description": "Option to use if nobody answers in time",
+									},
+									"risk_level": map[string]interface{}{
+										"type":        "string",
+										"enum":        []string{"low", "medium", "high"},
+										"description": "How risky the action is; \"high\" (e.g. destructive operations) requires the user's PIN or authenticator code",
+									},
+									"details": map[string]interface{}{
+										"type":        "string",
+										"description": "Markdown with more background, shown under the question on the response page",
+									},
+									"diff": map[string]interface{}{
+										"type":        "string",
+										"description": "Unified diff of the proposed change, shown in a diff viewer (max 256 KB)",
+									},
+									"command": map[string]interface{}{
+										"type":        "string",
+										"description": "Command line the agent wants to run",
+									},
+									"file_path": map[string]interface{}{
+										"type":        "string",
+										"description": "Path of the file the question is about",
+									},
+									"file_snippet": map[string]interface{}{
+										"type":        "string",
+										"description": "Excerpt of file_path to show, highlighted by its extension",
+									},
+									"links": map[string]interface{}{
+										"type":        "array",
+										"description": "Related http(s) URLs, such as a pull request or CI run",
+										"items":       map[string]interface{}{"type": "string"},
+									},
+									"answer_schema": map[string]interface{}{
+										"type":        "object",
+										"description": "Shape of the answer: {\"type\":\"choice\"}, {\"type\":\"multi\",\"min\":1,\"max\":2}, {\"type\":\"yes_no\"}, {\"type\":\"number\",\"min\":1,\"max\":10}, {\"type\":\"text\",\"pattern\":\"...\"} or {\"type\":\"form\",\"fields\":[{\"name\":\"replicas\",\"type\":\"number\"}]}. The answer comes back as JSON with a typed \"value\".",
+									},
+									"request_id": map[string]interface{}{
+										"type":        "string",
+										"description": "ID of a request asked before the bridge restarted, to wait for its answer instead of asking again",
+									},
+								},
+								"required": []string{"question"},
+							},
+						},
+						{
+							"name":        "request_permission",
+							"description": "Ask the user to approve or deny an action before taking it. Returns {\"approved\": bool, \"note\": string, \"approver\": string}; anything but an explicit approval, including a timeout, comes back as \"approved\": false.",
+							"inputSchema": map[string]interface{}{
+								"type": "object",
+								"properties": map[string]interface{}{
+									"action": map[string]interface{}{
+										"type":        "string",
+										"description": "What you want to do, e.g. \"Delete the build cache\"",
+									},
+									"command": map[string]interface{}{
+										"type":        "string",
+										"description": "Command line you would run",
+									},
+									"paths": map[string]interface{}{
+										"type":        "array",
+										"description": "Files or directories the action touches",
+										"items":       map[string]interface{}{"type": "string"},
+									},
+									"risk_level": map[string]interface{}{
+										"type":        "string",
+										"enum":        []string{"low", "medium", "high"},
+										"description": "How risky the action is; \"high\" requires the user's PIN or authenticator code",
+									},
+									"justification": map[string]interface{}{
+										"type":        "string",
+										"description": "Why the action is needed (Markdown)",
+									},
+									"category": map[string]interface{}{
+										"type":        "string",
+										"description": "Optional category used to route the request to approvers",
+									},
+									"timeout_seconds": map[string]interface{}{
+										"type":        "number",
+										"description": "How long to wait for a decision (default 15 minutes)",
+									},
 								},
-								"required": []string{"question", "options"},
+								"required": []string{"action"},
 							},
 						},
 					},
@@ -109,8 +200,8 @@ func main() {
 			toolName, _ := params["name"].(string)
 			args, _ := params["arguments"].(map[string]interface{})
 
-			if toolName == "ask_remote_human" {
-				result := callWailsBridge(args)
+			if toolName == "ask_remote_human" || toolName == "request_permission" {
+				result := callWailsBridge(toolName, args)
 				response := map[string]interface{}{
 					"jsonrpc": "2.0",
 					"id":      id,
@@ -134,7 +225,7 @@ func sendResponse(response map[string]interface{}) {
 	fmt.Println(string(data))
 }
 
-func callWailsBridge(args map[string]interface{}) string {
+func callWailsBridge(tool string, args map[string]interface{}) string {
 	// Read tunnel URL from file - check multiple locations
 	paths := []string{
 		"tunnel-url.txt",
@@ -142,11 +233,12 @@ func callWailsBridge(args map[string]interface{}) string {
 		"../tunnel-url.txt",
 	}
 	
-	var tunnelURL string
+	var tunnelURL, bridgeDir string
 	for _, p := range paths {
 		data, err := ioutil.ReadFile(p)
 		if err == nil {
 			tunnelURL = strings.TrimSpace(string(data))
+			bridgeDir = filepath.Dir(p)
 			break
 		}
 	}
@@ -155,30 +247,108 @@ func callWailsBridge(args map[string]interface{}) string {
 		return "Error: Bridge not running. Please start the Remote Bridge app first."
 	}
 
-	// Send notification request
-	question, _ := args["question"].(string)
-	options, _ := args["options"].([]interface{})
+	token := readAskToken(bridgeDir)
+	if token == "" {
+		return "Error: No bridge token found. Start the bridge once from the Remote Bridge app to generate it, or set MOMENTUM_ASK_TOKEN."
+	}
 
-	requestBody := map[string]interface{}{
-		"question": question,
-		"options":  options,
+	timeoutSeconds, _ := args["timeout_seconds"].(float64)
+	workspace, _ := os.Getwd()
+	endpoint := "/ask"
+	var requestBody map[string]interface{}
+	if tool == "request_permission" {
+		// The bridge words the approve/deny question and returns the typed decision
+		endpoint = "/permission"
+		requestBody = map[string]interface{}{
+			"workspace":       workspace,
+			"timeout_seconds": int(timeoutSeconds),
+		}
+		for _, key := range []string{"action", "command", "paths", "risk_level", "justification", "category"} {
+			if value, ok := args[key]; ok {
+				requestBody[key] = value
+			}
+		}
+	} else {
+		requestBody = askRequestBody(args, workspace, int(timeoutSeconds))
 	}
 	bodyData, _ := json.Marshal(requestBody)
 
-	resp, err := http.Post(tunnelURL+"/ask", "application/json", bytes.NewReader(bodyData))
+	req, _ := http.NewRequest("POST", tunnelURL+endpoint, bytes.NewReader(bodyData))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return fmt.Sprintf("Error connecting to bridge: %v", err)
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode == 401 {
+		return "Error: The bridge rejected our token. Restart the Remote Bridge app, or check MOMENTUM_ASK_TOKEN."
+	}
 
 	respBody, _ := ioutil.ReadAll(resp.Body)
 	
 	var result map[string]interface{}
 	json.Unmarshal(respBody, &result)
 	
+	// "text" includes who answered what and whether the timeout fallback was used
+	if text, ok := result["text"].(string); ok {
+		return text
+	}
 	if answer, ok := result["answer"].(string); ok {
 		return answer
 	}
 	
 	return string(respBody)
 }
+
+// askRequestBody is the /ask request for an ask_remote_human call
+func askRequestBody(args map[string]interface{}, workspace string, timeoutSeconds int) map[string]interface{} {
+	question, _ := args["question"].(string)
+	options, _ := args["options"].([]interface{})
+	category, _ := args["category"].(string)
+	defaultOption, _ := args["default_option"].(string)
+	riskLevel, _ := args["risk_level"].(string)
+	requestID, _ := args["request_id"].(string)
+
+	requestBody := map[string]interface{}{
+		"question":        question,
+		"options":         options,
+		"category":        category,
+		"workspace":       workspace,
+		"timeout_seconds": timeoutSeconds,
+		"default_option":  defaultOption,
+		"risk_level":      riskLevel,
+		"request_id":      requestID,
+	}
+	// Context and the answer schema are passed through as given; the bridge validates them
+	for _, key := range []string{"details", "diff", "command", "file_path", "file_snippet", "links", "answer_schema"} {
+		if value, ok := args[key]; ok {
+			requestBody[key] = value
+		}
+	}
+	return requestBody
+}
+
+// readAskToken returns the bearer token for /ask: MOMENTUM_ASK_TOKEN, or askToken from the
+// bridge-config.json that sits with tunnel-url.txt (or in build/bin when running wails dev)
+func readAskToken(bridgeDir string) string {
+	if token := os.Getenv("MOMENTUM_ASK_TOKEN"); token != "" {
+		return token
+	}
+	for _, p := range []string{
+		filepath.Join(bridgeDir, "bridge-config.json"),
+		filepath.Join(bridgeDir, "build", "bin", "bridge-config.json"),
+	} {
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		var cfg struct {
+			AskToken string `json:"askToken"`
+		}
+		if json.Unmarshal(data, &cfg) == nil && cfg.AskToken != "" {
+			return cfg.AskToken
+		}
+	}
+	return ""
+}
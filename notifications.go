@@ -1,6 +1,6 @@
 // Package main - notifications.go
 // Multi-channel notification system for Remote Bridge
-// Supports: Telegram, WhatsApp (CallMeBot)
+// Supports: Telegram, WhatsApp (CallMeBot), Discord (webhook / bot), ntfy
 
 package main
 
@@ -24,6 +24,19 @@ type NotificationConfig struct {
 	// WhatsApp (CallMeBot - Free API)
 	WhatsAppAPIKey string
 	UserPhone      string
+
+	// Discord - webhook mode (embed + link) or bot mode (buttons)
+	DiscordWebhookURL   string
+	DiscordBotToken     string
+	DiscordChannelID    string
+	DiscordPublicKey    string // Application public key, verifies button interactions
+	DiscordAllowedUsers string // Comma-separated user IDs that may press buttons
+	DiscordAllowedRoles string // Comma-separated role IDs that may press buttons
+
+	// ntfy push (server defaults to ntfy.sh)
+	NtfyServer string
+	NtfyTopic  string
+	NtfyToken  string
 }
 
 // notifyConfig is the global notification configuration
@@ -36,6 +49,17 @@ func initNotifications() {
 		TelegramChatID:   os.Getenv("TELEGRAM_CHAT_ID"),
 		WhatsAppAPIKey:   os.Getenv("WHATSAPP_API_KEY"),
 		UserPhone:        os.Getenv("USER_PHONE"),
+
+		DiscordWebhookURL:   os.Getenv("DISCORD_WEBHOOK_URL"),
+		DiscordBotToken:     os.Getenv("DISCORD_BOT_TOKEN"),
+		DiscordChannelID:    os.Getenv("DISCORD_CHANNEL_ID"),
+		DiscordPublicKey:    os.Getenv("DISCORD_PUBLIC_KEY"),
+		DiscordAllowedUsers: os.Getenv("DISCORD_ALLOWED_USERS"),
+		DiscordAllowedRoles: os.Getenv("DISCORD_ALLOWED_ROLES"),
+
+		NtfyServer: os.Getenv("NTFY_SERVER"),
+		NtfyTopic:  os.Getenv("NTFY_TOPIC"),
+		NtfyToken:  os.Getenv("NTFY_TOKEN"),
 	}
 
 	// Log which channels are configured
@@ -47,6 +71,20 @@ func initNotifications() {
 		channels = append(channels, "WhatsApp (CallMeBot)")
 		fmt.Fprintf(os.Stderr, "[BRIDGE] 🟢 WhatsApp configured for: %s\n", notifyConfig.UserPhone)
 	}
+	if discordBotEnabled() {
+		channels = append(channels, "Discord (bot)")
+		if notifyConfig.DiscordPublicKey == "" {
+			fmt.Fprintln(os.Stderr, "[BRIDGE] ⚠️  DISCORD_PUBLIC_KEY not set - Discord buttons cannot be verified")
+		}
+		if notifyConfig.DiscordAllowedUsers == "" && notifyConfig.DiscordAllowedRoles == "" {
+			fmt.Fprintln(os.Stderr, "[BRIDGE] ⚠️  DISCORD_ALLOWED_USERS/ROLES not set - Discord buttons will refuse everyone")
+		}
+	} else if notifyConfig.DiscordWebhookURL != "" {
+		channels = append(channels, "Discord (webhook)")
+	}
+	if notifyConfig.NtfyTopic != "" {
+		channels = append(channels, "ntfy")
+	}
 
 	if len(channels) > 0 {
 		fmt.Fprintf(os.Stderr, "[BRIDGE] 📢 Notification channels enabled: %s\n", strings.Join(channels, ", "))
@@ -65,7 +103,7 @@ func broadcastNotification(question string, options []string, remoteURL string,
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if err := sendTelegramNotification(question, options, remoteURL); err != nil {
+			if err := sendTelegramNotification(question, options, remoteURL, requestID); err != nil {
 				fmt.Fprintf(os.Stderr, "[BRIDGE] ⚠️  Telegram failed: %v\n", err)
 			} else {
 				fmt.Fprintln(os.Stderr, "[BRIDGE] 📱 Telegram notification sent!")
@@ -87,58 +125,117 @@ func broadcastNotification(question string, options []string, remoteURL string,
 		}()
 	}
 
+	// Channel C: Discord (bot buttons take precedence over the plain webhook)
+	if discordBotEnabled() || notifyConfig.DiscordWebhookURL != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			if discordBotEnabled() {
+				err = sendDiscordBot(question, options, remoteURL, requestID)
+			} else {
+				err = sendDiscordWebhook(question, options, remoteURL, requestID)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[BRIDGE] ⚠️  Discord failed: %v\n", err)
+			} else {
+				fmt.Fprintln(os.Stderr, "[BRIDGE] 💬 Discord notification sent!")
+			}
+		}()
+	}
+
+	// Channel D: ntfy push
+	if notifyConfig.NtfyTopic != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := sendNtfyNotification(question, options, remoteURL, requestID); err != nil {
+				fmt.Fprintf(os.Stderr, "[BRIDGE] ⚠️  ntfy failed: %v\n", err)
+			} else {
+				fmt.Fprintln(os.Stderr, "[BRIDGE] 🔔 ntfy notification sent!")
+			}
+		}()
+	}
+
 	// Wait for all notifications to complete
 	wg.Wait()
 	fmt.Fprintln(os.Stderr, "[BRIDGE] ✅ All notification channels completed")
 }
 
-// sendTelegramNotification sends a message via Telegram Bot API with single link
-func sendTelegramNotification(question string, options []string, remoteURL string) error {
+// discordBotEnabled reports whether bot mode credentials are present
+func discordBotEnabled() bool {
+	return notifyConfig.DiscordBotToken != "" && notifyConfig.DiscordChannelID != ""
+}
+
+// sendTelegramNotification sends a message via Telegram Bot API with one callback button per option
+func sendTelegramNotification(question string, options []string, remoteURL string, requestID string) error {
 	if telegramBot == nil {
 		return fmt.Errorf("telegram bot not initialized")
 	}
 
 	// Don't escape question - it breaks markdown link syntax
-	// Single "Tap to Decide" link (no answer in URL - opens interactive form)
-	message := fmt.Sprintf("🤖 *Agent Paused*\n\n❓ %s\n\n👇 *Choose an option:*\n\n👉 [Tap to Decide](%s)", question, remoteURL)
+	// Buttons answer in place; the link is only useful when the form is publicly reachable
+	message := fmt.Sprintf("🤖 *Agent Paused*\n\n❓ %s\n\n👇 *Choose an option* or reply to this message with your own answer:", question)
+	rows := telegramOptionButtons(options, requestID)
+	if strings.HasPrefix(remoteURL, "https://") {
+		message += fmt.Sprintf("\n\n👉 [Tap to Decide](%s)", remoteURL)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonURL("✏️ Custom answer", remoteURL)))
+	}
 
 	msg := tgbotapi.NewMessage(telegramChatID, message)
 	msg.ParseMode = "Markdown"
+	if len(rows) > 0 {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	}
 
-	_, err := telegramBot.Send(msg)
-	return err
+	sent, err := telegramBot.Send(msg)
+	if err != nil && strings.Contains(err.Error(), "can't parse entities") {
+		// A stray _ or * in the question or its context summary; send it as plain text instead
+		msg.Text = fmt.Sprintf("🤖 Agent Paused\n\n❓ %s\n\n👇 Choose an option or reply to this message with your own answer:", question)
+		if strings.HasPrefix(remoteURL, "https://") {
+			msg.Text += "\n\n👉 Tap to Decide: " + remoteURL
+		}
+		msg.ParseMode = ""
+		sent, err = telegramBot.Send(msg)
+	}
+	if err != nil {
+		return err
+	}
+	// Remember the message so replies to it can be matched to the request
+	telegramMessages.Store(requestID, sent.MessageID)
+	return nil
 }
 
 // sendWhatsAppNotification sends a message via CallMeBot API
 func sendWhatsAppNotification(question string, options []string, remoteURL string, requestID string) error {
 	// Construct the message
-// CallMeBot supports basic formatting: *bold*, _italic_, %0A for new line
-	
+	// CallMeBot supports basic formatting: *bold*, _italic_, %0A for new line
+
 	// Format:
 	// 🤖 Agent Paused
 	// ❓ Question
 	//
 	// 👉 Tap to Decide: <URL>
-	
+
 	// We need to encode specifically for URL parameters
 	// But first let's build the text string
-	
+
 	messageLines := []string{
 		"🤖 *Agent Paused*",
 		fmt.Sprintf("❓ %s", question),
 		"",
 		fmt.Sprintf("👉 Tap to Decide: %s", remoteURL),
 	}
-	
+
 	fullMessage := strings.Join(messageLines, "\n")
-	
+
 	// Create the request URL
 	baseURL := "https://api.callmebot.com/whatsapp.php"
 	params := url.Values{}
 	params.Add("phone", notifyConfig.UserPhone)
 	params.Add("text", fullMessage)
 	params.Add("apikey", notifyConfig.WhatsAppAPIKey)
-	
+
 	finalURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
 
 	// Send GET request
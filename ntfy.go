@@ -0,0 +1,116 @@
+// Package main - ntfy.go
+// ntfy push notifications for Remote Bridge (ntfy.sh or self-hosted)
+// Each option becomes an "http" action that POSTs straight to /submit
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultNtfyServer = "https://ntfy.sh"
+
+// ntfy allows at most three actions per notification
+const ntfyMaxActions = 3
+
+type ntfyAction struct {
+	Action  string            `json:"action"` // "http" or "view"
+	Label   string            `json:"label"`
+	URL     string            `json:"url"`
+	Method  string            `json:"method,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Clear   bool              `json:"clear,omitempty"`
+}
+
+// ntfyViaHeader marks answers from ntfy's buttons, which post option links to /submit like the confirm page
+const ntfyViaHeader = "X-Momentum-Via"
+
+type ntfyMessage struct {
+	Topic    string       `json:"topic"`
+	Title    string       `json:"title"`
+	Message  string       `json:"message"`
+	Priority int          `json:"priority,omitempty"`
+	Tags     []string     `json:"tags,omitempty"`
+	Click    string       `json:"click,omitempty"`
+	Actions  []ntfyAction `json:"actions,omitempty"`
+}
+
+// sendNtfyNotification publishes the question to the configured ntfy topic
+func sendNtfyNotification(question string, options []string, remoteURL string, requestID string) error {
+	server := strings.TrimRight(notifyConfig.NtfyServer, "/")
+	if server == "" {
+		server = defaultNtfyServer
+	}
+
+	msg := ntfyMessage{
+		Topic:    notifyConfig.NtfyTopic,
+		Title:    "🤖 Agent Paused",
+		Message:  question,
+		Priority: 4,
+		Tags:     []string{"robot"},
+		Click:    remoteURL,
+	}
+	// One-tap answers can't carry a PIN, so high-risk questions only open the response page
+	if !needsSecondFactor(requestID) {
+		msg.Actions = buildNtfyActions(options, publicURL, remoteURL, requestID, linkExpiry(requestID))
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	// JSON publishing goes to the server root, not the topic URL
+	req, err := http.NewRequest("POST", server, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if notifyConfig.NtfyToken != "" {
+		req.Header.Set("Authorization", "Bearer "+notifyConfig.NtfyToken)
+	}
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("ntfy returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// buildNtfyActions maps options to "http" actions; with too many options the
+// last slot opens the full response page instead. Option links stop working at expires.
+func buildNtfyActions(options []string, baseURL string, remoteURL string, requestID string, expires time.Time) []ntfyAction {
+	direct := options
+	if len(options) > ntfyMaxActions {
+		direct = options[:ntfyMaxActions-1]
+	}
+
+	var actions []ntfyAction
+	for _, opt := range direct {
+		actions = append(actions, ntfyAction{
+			Action:  "http",
+			Label:   opt,
+			URL:     responseLink(baseURL, "/submit", requestID, opt, expires),
+			Method:  "POST",
+			Headers: map[string]string{ntfyViaHeader: "ntfy"},
+			Clear:   true,
+		})
+	}
+	if len(options) > ntfyMaxActions {
+		actions = append(actions, ntfyAction{Action: "view", Label: "More…", URL: remoteURL, Clear: true})
+	}
+	return actions
+}
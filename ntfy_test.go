@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startFakeNtfy stands in for an ntfy server and hands over each published message
+func startFakeNtfy(t *testing.T) chan ntfyMessage {
+	t.Helper()
+	published := make(chan ntfyMessage, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tk_secret" {
+			http.Error(w, "unauthorized", 401)
+			return
+		}
+		var msg ntfyMessage
+		json.NewDecoder(r.Body).Decode(&msg)
+		published <- msg
+	}))
+	t.Cleanup(server.Close)
+	notifyConfig.NtfyServer, notifyConfig.NtfyTopic, notifyConfig.NtfyToken = server.URL, "agent", "tk_secret"
+	return published
+}
+
+func TestSendNtfyNotification(t *testing.T) {
+	configPath = filepath.Join(t.TempDir(), "bridge-config.json")
+	publicURL = "https://bridge.example"
+	published := startFakeNtfy(t)
+	requestDetails.Store("n1", RequestDetails{Question: "Deploy?", Options: []string{"Yes", "No"}})
+	requestDetails.Store("n2", RequestDetails{Question: "Drop the table?", Options: []string{"Yes", "No"}, SecondFactor: true})
+	defer requestDetails.Delete("n1")
+	defer requestDetails.Delete("n2")
+
+	tests := []struct {
+		name    string
+		id      string
+		options []string
+		want    []string // Action labels
+	}{
+		{"one action per option", "n1", []string{"Yes", "No"}, []string{"Yes", "No"}},
+		{"too many options", "n1", []string{"a", "b", "c", "d"}, []string{"a", "b", "More…"}},
+		{"high risk", "n2", []string{"Yes", "No"}, nil},
+	}
+	for _, tt := range tests {
+		if err := sendNtfyNotification("Question?", tt.options, "https://bridge.example/?t=page", tt.id); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		msg := <-published
+		if msg.Topic != "agent" || msg.Click != "https://bridge.example/?t=page" {
+			t.Errorf("%s: published %+v", tt.name, msg)
+		}
+		if len(msg.Actions) != len(tt.want) {
+			t.Errorf("%s: %d actions, want %v", tt.name, len(msg.Actions), tt.want)
+			continue
+		}
+		for i, action := range msg.Actions {
+			if action.Label != tt.want[i] {
+				t.Errorf("%s: action %d is %q, want %q", tt.name, i, action.Label, tt.want[i])
+			}
+			if action.Action == "view" {
+				continue
+			}
+			// Each button posts a signed link that answers only its own option
+			link, err := url.Parse(action.URL)
+			if err != nil || !strings.HasPrefix(action.URL, publicURL+"/submit?") || action.Method != "POST" {
+				t.Errorf("%s: action %q posts to %s %s", tt.name, action.Label, action.Method, action.URL)
+				continue
+			}
+			claims, err := linkSigner().Verify(link.Query().Get("t"), time.Now())
+			if err != nil || claims.RequestID != tt.id || claims.Answer != action.Label {
+				t.Errorf("%s: action %q carries %+v, %v", tt.name, action.Label, claims, err)
+			}
+			if action.Headers[ntfyViaHeader] != "ntfy" {
+				t.Errorf("%s: action %q is not marked as ntfy's", tt.name, action.Label)
+			}
+		}
+	}
+
+	notifyConfig.NtfyToken = "wrong"
+	if err := sendNtfyNotification("Question?", []string{"Yes"}, "", "n1"); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("a refused publish returned %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"os"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui"
+)
+
+// responsePages renders the pages behind response links, with the brand,
+// language and theme from the responsePage section of bridge-config.json
+func responsePages() responseui.Pages {
+	return responseui.Pages{
+		Config: responseui.Config{
+			Brand:    os.Getenv("RESPONSE_BRAND"),
+			Language: os.Getenv("RESPONSE_LANGUAGE"),
+			Theme:    os.Getenv("RESPONSE_THEME"),
+		},
+		Action: "/submit",
+		Field:  "response",
+	}
+}
+
+// page is what the response page shows of a request
+func (d RequestDetails) page() responseui.Request {
+	return responseui.Request{Question: d.Question, Options: d.Options, Context: d.Context, Schema: d.Schema}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui"
+)
+
+// permissionQuestion turns request_permission arguments into ask_remote_human
+// arguments: an approve/deny question with the command and paths as context.
+// There is no default option, so a request nobody answers is denied.
+func permissionQuestion(args map[string]interface{}) map[string]interface{} {
+	action, _ := args["action"].(string)
+	justification, _ := args["justification"].(string)
+	var paths []string
+	if list, ok := args["paths"].([]interface{}); ok {
+		for _, p := range list {
+			if path, ok := p.(string); ok {
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	options := []interface{}{}
+	for _, option := range responseui.PermissionOptions {
+		options = append(options, option)
+	}
+	question := map[string]interface{}{
+		"question":      "Permission requested: " + strings.TrimSpace(action),
+		"options":       options,
+		"details":       responseui.PermissionDetails(justification, paths),
+		"answer_schema": map[string]interface{}{"type": responseui.TypePermission},
+	}
+	for _, key := range []string{"command", "risk_level", "timeout_seconds"} {
+		if value, ok := args[key]; ok {
+			question[key] = value
+		}
+	}
+	return question
+}
+
+// newPermissionResult reads the decision out of an answered question and
+// names who gave it from the votes the request recorded
+func newPermissionResult(result AskResult) responseui.PermissionResult {
+	r, _ := storedRequest(result.RequestID)
+	return responseui.NewPermissionResult(result.RequestID, result.Value, result.TimedOut, r.Votes)
+}
@@ -0,0 +1,133 @@
+package responseui
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Size limits for context fields; anything bigger belongs in a link
+const (
+	maxDiffBytes    = 256 << 10
+	maxContextBytes = 64 << 10
+	maxLinks        = 10
+)
+
+// Context is what an agent can attach to a question so the user sees what
+// they are approving. Every field is optional.
+type Context struct {
+	Details  string   `json:"details,omitempty"`      // Markdown shown under the question
+	Diff     string   `json:"diff,omitempty"`         // Unified diff of the proposed change
+	Command  string   `json:"command,omitempty"`      // Command line the agent wants to run
+	FilePath string   `json:"file_path,omitempty"`    // File the snippet comes from
+	Snippet  string   `json:"file_snippet,omitempty"` // Excerpt of FilePath
+	Links    []string `json:"links,omitempty"`        // http(s) URLs, e.g. a PR or CI run
+}
+
+// ContextArguments reads the context fields from ask_remote_human arguments
+func ContextArguments(args map[string]interface{}) Context {
+	var c Context
+	c.Details, _ = args["details"].(string)
+	c.Diff, _ = args["diff"].(string)
+	c.Command, _ = args["command"].(string)
+	c.FilePath, _ = args["file_path"].(string)
+	c.Snippet, _ = args["file_snippet"].(string)
+	if links, ok := args["links"].([]interface{}); ok {
+		for _, link := range links {
+			if s, ok := link.(string); ok {
+				c.Links = append(c.Links, s)
+			}
+		}
+	}
+	return c
+}
+
+// IsZero reports whether no context was given
+func (c Context) IsZero() bool {
+	return c.Details == "" && c.Diff == "" && c.Command == "" && c.FilePath == "" && c.Snippet == "" && len(c.Links) == 0
+}
+
+// Validate rejects context the response page would not show properly
+func (c Context) Validate() error {
+	if len(c.Diff) > maxDiffBytes {
+		return fmt.Errorf("diff is larger than %d KB", maxDiffBytes>>10)
+	}
+	for name, value := range map[string]string{"details": c.Details, "command": c.Command, "file_snippet": c.Snippet} {
+		if len(value) > maxContextBytes {
+			return fmt.Errorf("%s is larger than %d KB", name, maxContextBytes>>10)
+		}
+	}
+	if c.Snippet != "" && c.FilePath == "" {
+		return fmt.Errorf("file_snippet needs a file_path")
+	}
+	if len(c.Links) > maxLinks {
+		return fmt.Errorf("at most %d links are allowed", maxLinks)
+	}
+	for _, link := range c.Links {
+		if !isWebLink(link) {
+			return fmt.Errorf("link %q must be an http(s) URL", link)
+		}
+	}
+	return nil
+}
+
+// WebLinks is Links without anything that isn't an http(s) URL, so a stored
+// request that skipped Validate still can't put another scheme on the page
+func (c Context) WebLinks() []string {
+	var links []string
+	for _, link := range c.Links {
+		if isWebLink(link) {
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+func isWebLink(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// Summary is a few short plain-text lines describing the context, for
+// notifications on a phone; the response page shows the full context
+func (c Context) Summary() string {
+	if c.IsZero() {
+		return ""
+	}
+	var lines []string
+	if details := strings.Join(strings.Fields(c.Details), " "); details != "" {
+		lines = append(lines, "📝 "+truncate(details, 160))
+	}
+	if command := strings.Join(strings.Fields(c.Command), " "); command != "" {
+		lines = append(lines, "💻 $ "+truncate(command, 100))
+	}
+	if c.FilePath != "" {
+		lines = append(lines, "📄 "+truncate(c.FilePath, 100))
+	}
+	if c.Diff != "" {
+		files, added, removed := diffStats(parseDiff(c.Diff))
+		lines = append(lines, fmt.Sprintf("± %d file(s) changed, +%d -%d", files, added, removed))
+	}
+	if len(c.Links) > 0 {
+		lines = append(lines, fmt.Sprintf("🔗 %d link(s)", len(c.Links)))
+	}
+	lines = append(lines, "Open the response link for the full details.")
+	return strings.Join(lines, "\n")
+}
+
+// WithSummary appends the context summary to a notification message
+func (c Context) WithSummary(message string) string {
+	if summary := c.Summary(); summary != "" {
+		return message + "\n\n" + summary
+	}
+	return message
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
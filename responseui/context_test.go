@@ -0,0 +1,184 @@
+package responseui
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// hostileContext puts the same input in every context field
+func hostileContext(input string) Context {
+	return Context{
+		Details:  input,
+		Diff:     "diff --git a/" + input + " b/" + input + "\n--- a/x\n+++ b/x\n@@ -1 +1 @@\n-" + input + "\n+" + input,
+		Command:  input,
+		FilePath: input + ".go",
+		Snippet:  input,
+		Links:    []string{input, "javascript:alert(1)", "https://ok.example/?q=" + input},
+	}
+}
+
+func TestHighlightEscapesHostileInput(t *testing.T) {
+	for _, input := range hostileInputs {
+		for _, lang := range []string{"", "go", "js", "shell", "sql", "json", "yaml", "python", "c"} {
+			assertNoInjection(t, string(highlight(input, lang)))
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		code, lang, want string
+	}{
+		{`return "x" // done`, "go", `<span class="k">return</span> <span class="s">&#34;x&#34;</span> <span class="c">// done</span>`},
+		{`SELECT 1 from t`, "query.sql", `<span class="k">SELECT</span> <span class="n">1</span> <span class="k">from</span> t`},
+		{`rm -rf "$DIR" # clean`, "bash", `<span class="k">rm</span> -rf <span class="s">&#34;$DIR&#34;</span> <span class="c"># clean</span>`},
+		{`/* a < b */ x`, "main.c", `<span class="c">/* a &lt; b */</span> x`},
+		{`if a < b`, "unknown", `if a &lt; b`},
+		{`"unterminated`, "go", `<span class="s">&#34;unterminated</span>`},
+	}
+	for _, tt := range tests {
+		if got := string(highlight(tt.code, tt.lang)); got != tt.want {
+			t.Errorf("highlight(%q, %q)\n got %s\nwant %s", tt.code, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestParseDiff(t *testing.T) {
+	diff := "diff --git a/main.go b/main.go\n" +
+		"index 1111111..2222222 100644\n" +
+		"--- a/main.go\n" +
+		"+++ b/main.go\n" +
+		"@@ -1,2 +1,2 @@\n" +
+		" package main\n" +
+		"-var x = 1\n" +
+		"+var x = 2\n" +
+		"diff --git a/new.txt b/new.txt\n" +
+		"new file mode 100644\n" +
+		"--- /dev/null\n" +
+		"+++ b/new.txt\n" +
+		"@@ -0,0 +1,2 @@\n" +
+		"+one\n" +
+		"+-- two\n"
+	files := parseDiff(diff)
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2: %+v", len(files), files)
+	}
+	if f := files[0]; f.Path != "main.go" || f.Added != 1 || f.Removed != 1 || len(f.Lines) != 4 || !f.Open() {
+		t.Errorf("first file = %+v", f)
+	}
+	if f := files[1]; f.Path != "new.txt" || f.Added != 2 || f.Removed != 0 || len(f.Lines) != 3 {
+		t.Errorf("second file = %+v", f)
+	}
+	classes := []string{}
+	for _, line := range files[0].Lines {
+		classes = append(classes, line.Class)
+	}
+	if got := strings.Join(classes, " "); got != "hunk ctx del add" {
+		t.Errorf("line classes = %s", got)
+	}
+
+	plain := parseDiff("--- old/a.py\t2024-01-01\n+++ new/a.py\t2024-01-02\n@@ -1 +1 @@\n--- x\n+++ y\n")
+	if len(plain) != 1 || plain[0].Path != "new/a.py" || plain[0].Added != 1 || plain[0].Removed != 1 {
+		t.Errorf("plain diff = %+v", plain)
+	}
+
+	bare := parseDiff("@@ -1 +1 @@\n-a\n+b")
+	if len(bare) != 1 || bare[0].Path != "" || len(bare[0].Lines) != 3 {
+		t.Errorf("bare hunk = %+v", bare)
+	}
+}
+
+func TestParseDiffCollapsesAndCaps(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("--- a/big.txt\n+++ b/big.txt\n@@ -0,0 +1,3000 @@\n")
+	for i := 0; i < 3000; i++ {
+		b.WriteString("+line\n")
+	}
+	files := parseDiff(b.String())
+	if len(files) != 1 {
+		t.Fatalf("got %d files", len(files))
+	}
+	f := files[0]
+	if f.Open() {
+		t.Error("a large file should start collapsed")
+	}
+	if len(f.Lines) != maxDiffLines || f.Hidden != 3001-maxDiffLines || f.Added != 3000 {
+		t.Errorf("lines %d, hidden %d, added %d", len(f.Lines), f.Hidden, f.Added)
+	}
+}
+
+func TestContextPage(t *testing.T) {
+	w := httptest.NewRecorder()
+	testPages.Question(w, 200, "tok", Request{Question: "Apply?", Context: Context{
+		Details:  "Fixes **the** bug",
+		Diff:     "--- a/x.go\n+++ b/x.go\n@@ -1 +1 @@\n-return 1\n+return 2",
+		Command:  "go test ./...",
+		FilePath: "cfg.yaml",
+		Snippet:  "debug: true",
+		Links:    []string{"https://ci.example/run/1", "javascript:alert(1)"},
+	}}, "")
+	body := w.Body.String()
+	assertNoInjection(t, body)
+	for _, want := range []string{
+		"<strong>the</strong>",
+		`<details class="diff-file" open>`,
+		`<span class="diff-added">+1</span>`,
+		`<span class="del">-<span class="k">return</span> <span class="n">1</span></span>`,
+		`<span class="k">go</span> test ./...`,
+		`<span class="context-path">cfg.yaml</span>`,
+		`debug: <span class="k">true</span>`,
+		`href="https://ci.example/run/1"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page is missing %s:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "javascript") {
+		t.Errorf("page shows a non-web link:\n%s", body)
+	}
+}
+
+func TestContextValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		c    Context
+		ok   bool
+	}{
+		{"empty", Context{}, true},
+		{"full", Context{Details: "d", Diff: "+a", Command: "ls", FilePath: "a.go", Snippet: "x", Links: []string{"https://a.example"}}, true},
+		{"path only", Context{FilePath: "a.go"}, true},
+		{"snippet without path", Context{Snippet: "x"}, false},
+		{"huge diff", Context{Diff: strings.Repeat("+", maxDiffBytes+1)}, false},
+		{"huge details", Context{Details: strings.Repeat("a", maxContextBytes+1)}, false},
+		{"javascript link", Context{Links: []string{"javascript:alert(1)"}}, false},
+		{"relative link", Context{Links: []string{"/local"}}, false},
+		{"too many links", Context{Links: strings.Split(strings.Repeat("https://a.example ", maxLinks+1), " ")[:maxLinks+1]}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.c.Validate(); (err == nil) != tt.ok {
+			t.Errorf("%s: Validate() = %v", tt.name, err)
+		}
+	}
+}
+
+func TestContextSummary(t *testing.T) {
+	if got := (Context{}).WithSummary("Deploy?"); got != "Deploy?" {
+		t.Errorf("empty context changed the message: %q", got)
+	}
+	c := Context{
+		Details: strings.Repeat("word ", 100),
+		Command: "make\n  deploy",
+		Diff:    "--- a/a\n+++ b/a\n@@ -1 +1,2 @@\n-x\n+y\n+z\n--- a/b\n+++ b/b\n@@ -1 +0,0 @@\n-q",
+		Links:   []string{"https://a.example"},
+	}
+	got := c.WithSummary("Deploy?")
+	for _, want := range []string{"Deploy?\n\n📝 word word", "…\n", "💻 $ make deploy", "± 2 file(s) changed, +2 -2", "🔗 1 link(s)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary is missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "+y") || len([]rune(got)) > 400 {
+		t.Errorf("summary should be short and leave the diff out:\n%s", got)
+	}
+}
@@ -0,0 +1,149 @@
+package responseui
+
+import (
+	"html/template"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Diffs longer than this many lines are cut short on the page
+const maxDiffLines = 2000
+
+// Files with more changed lines than this start collapsed
+const collapseDiffLines = 80
+
+// A hunk header; the line counts default to 1 when left out
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,(\d+))? \+\d+(?:,(\d+))? @@`)
+
+// diffFile is one file of a unified diff, ready for the diff viewer
+type diffFile struct {
+	Path    string
+	Added   int
+	Removed int
+	Lines   []diffLine
+	Hidden  int // Lines past maxDiffLines that aren't shown
+}
+
+// diffLine is one highlighted line; Class is "add", "del", "hunk" or "ctx"
+type diffLine struct {
+	Class string
+	HTML  template.HTML
+}
+
+// Open reports whether the file starts expanded
+func (f diffFile) Open() bool {
+	return len(f.Lines) <= collapseDiffLines
+}
+
+// parseDiff splits a unified diff (git or plain diff -u) into files. Header
+// lines are dropped; the file name heads each file in the viewer instead.
+func parseDiff(diff string) []diffFile {
+	var files []diffFile
+	var current *diffFile
+	inHeader, shown := false, 0
+	oldLeft, newLeft := 0, 0 // Lines still to come in the current hunk
+
+	start := func(path string) {
+		files = append(files, diffFile{Path: path})
+		current = &files[len(files)-1]
+		inHeader = true
+	}
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(diff, "\r\n", "\n"), "\n"), "\n")
+	for i, line := range lines {
+		inHunk := oldLeft > 0 || newLeft > 0
+		if inHunk && line != "" && !strings.ContainsRune(" +-\\", rune(line[0])) {
+			// A hunk that promised more lines than it had
+			oldLeft, newLeft, inHunk = 0, 0, false
+		}
+		switch {
+		case inHunk:
+			// Inside a hunk, so "--- " and "diff " are content, not headers
+		case strings.HasPrefix(line, "diff --git "):
+			path := line[len("diff --git "):]
+			if j := strings.Index(path, " b/"); j >= 0 {
+				path = path[j+3:]
+			}
+			start(path)
+			continue
+		case strings.HasPrefix(line, "--- ") && !inHeader && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			// A plain diff -u file header
+			start(diffPath(line[4:]))
+			continue
+		case inHeader && strings.HasPrefix(line, "+++ "):
+			if path := diffPath(line[4:]); path != "/dev/null" {
+				current.Path = path
+			}
+			continue
+		case inHeader && !strings.HasPrefix(line, "@@"):
+			continue // index, mode and rename lines
+		}
+
+		if current == nil {
+			start("") // A bare hunk with no file headers
+		}
+		inHeader = false
+
+		class := "ctx"
+		switch {
+		case !inHunk && strings.HasPrefix(line, "@@"):
+			class = "hunk"
+			if m := hunkHeader.FindStringSubmatch(line); m != nil {
+				oldLeft, newLeft = hunkCount(m[1]), hunkCount(m[2])
+			}
+		case strings.HasPrefix(line, "+"):
+			class = "add"
+			current.Added++
+			newLeft--
+		case strings.HasPrefix(line, "-"):
+			class = "del"
+			current.Removed++
+			oldLeft--
+		case strings.HasPrefix(line, "\\"):
+			// "\ No newline at end of file" belongs to neither side
+		default:
+			oldLeft--
+			newLeft--
+		}
+		if shown >= maxDiffLines {
+			current.Hidden++
+			continue
+		}
+		shown++
+		lineHTML := highlightString(line, "")
+		if class != "hunk" && line != "" {
+			lineHTML = highlightString(line[:1], "") + highlightString(line[1:], current.Path)
+		}
+		current.Lines = append(current.Lines, diffLine{Class: class, HTML: template.HTML(lineHTML)})
+	}
+	return files
+}
+
+// hunkCount reads a line count from a hunk header
+func hunkCount(count string) int {
+	if count == "" {
+		return 1
+	}
+	n, _ := strconv.Atoi(count)
+	return n
+}
+
+// diffPath strips the a/ or b/ prefix and any timestamp from a ---/+++ header
+func diffPath(header string) string {
+	if i := strings.IndexByte(header, '\t'); i >= 0 {
+		header = header[:i]
+	}
+	if strings.HasPrefix(header, "a/") || strings.HasPrefix(header, "b/") {
+		header = header[2:]
+	}
+	return header
+}
+
+// diffStats totals a parsed diff for the notification summary
+func diffStats(files []diffFile) (count, added, removed int) {
+	for _, f := range files {
+		added += f.Added
+		removed += f.Removed
+	}
+	return len(files), added, removed
+}
@@ -0,0 +1,166 @@
+package responseui
+
+import (
+	"html"
+	"html/template"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// syntax is just enough of a language to colour comments, strings, numbers
+// and keywords; it doesn't try to be a parser
+type syntax struct {
+	lineComments []string
+	blockComment [2]string
+	quotes       string // Characters that open (and close) a string
+	keywords     map[string]bool
+	foldCase     bool // Keywords match in any case, as in SQL
+}
+
+func words(list string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.Fields(list) {
+		set[w] = true
+	}
+	return set
+}
+
+var cLike = [2]string{"/*", "*/"}
+
+var syntaxes = map[string]syntax{
+	"go":     {[]string{"//"}, cLike, "\"'`", words(`break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var nil true false iota`), false},
+	"js":     {[]string{"//"}, cLike, "\"'`", words(`async await break case catch class const continue debugger default delete do else export extends finally for from function if import in instanceof interface let new null of return static super switch this throw true false try type typeof undefined var void while yield`), false},
+	"c":      {[]string{"//"}, cLike, "\"'", words(`auto bool break case catch char class const continue default delete do double else enum extern false final float fn for if impl import int let long match mod mut namespace new null package private protected pub public return self short signed static struct super switch this throw throws true try typedef union unsigned use using var void volatile where while`), false},
+	"python": {[]string{"#"}, [2]string{}, "\"'", words(`and as assert async await break class continue def del elif else except False finally for from global if import in is lambda None nonlocal not or pass raise return True try while with yield`), false},
+	"shell":  {[]string{"#"}, [2]string{}, "\"'", words(`if then else elif fi for while until do done case esac in function return export local readonly set unset sudo echo cd rm mv cp git npm go make`), false},
+	"sql":    {[]string{"--"}, cLike, "'\"", words(`select from where and or not insert into values update set delete create table drop alter index join left right inner outer on group by order having limit as null is in like distinct union all primary key references truncate`), true},
+	"json":   {nil, [2]string{}, "\"", words(`true false null`), false},
+	"yaml":   {[]string{"#"}, [2]string{}, "\"'", words(`true false null yes no on off`), false},
+}
+
+// Aliases from file extensions and code fence info strings
+var syntaxAliases = map[string]string{
+	"golang":     "go",
+	"javascript": "js", "jsx": "js", "mjs": "js", "cjs": "js", "ts": "js", "tsx": "js", "typescript": "js",
+	"h": "c", "cc": "c", "cpp": "c", "hpp": "c", "cs": "c", "java": "c", "kt": "c", "rs": "c", "rust": "c", "swift": "c",
+	"py": "python",
+	"sh": "shell", "bash": "shell", "zsh": "shell", "console": "shell", "ps1": "shell", "powershell": "shell",
+	"yml": "yaml",
+}
+
+// languageFor picks a syntax from a code fence info string or a file name
+func languageFor(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if ext := path.Ext(name); ext != "" {
+		name = ext[1:]
+	}
+	if alias, ok := syntaxAliases[name]; ok {
+		return alias
+	}
+	if _, ok := syntaxes[name]; ok {
+		return name
+	}
+	return ""
+}
+
+// highlight returns code as escaped HTML with <span> classes for comments (c),
+// strings (s), numbers (n) and keywords (k). Unknown languages are only escaped.
+func highlight(code, lang string) template.HTML {
+	return template.HTML(highlightString(code, lang))
+}
+
+func highlightString(code, lang string) string {
+	syn, ok := syntaxes[languageFor(lang)]
+	if !ok {
+		return html.EscapeString(code)
+	}
+
+	var b strings.Builder
+	span := func(class, text string) {
+		b.WriteString(`<span class="` + class + `">` + html.EscapeString(text) + `</span>`)
+	}
+	for i := 0; i < len(code); {
+		rest := code[i:]
+		if syn.lineCommentAt(rest) {
+			end := len(code)
+			if j := strings.IndexByte(rest, '\n'); j >= 0 {
+				end = i + j
+			}
+			span("c", code[i:end])
+			i = end
+			continue
+		}
+		if start, stop := syn.blockComment[0], syn.blockComment[1]; start != "" && strings.HasPrefix(rest, start) {
+			end := len(code)
+			if j := strings.Index(rest[len(start):], stop); j >= 0 {
+				end = i + len(start) + j + len(stop)
+			}
+			span("c", code[i:end])
+			i = end
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(rest)
+		switch {
+		case strings.ContainsRune(syn.quotes, r):
+			end := i + size
+			for end < len(code) && rune(code[end]) != r && (code[end] != '\n' || r == '`') {
+				if code[end] == '\\' && r != '`' {
+					end++
+				}
+				end++
+			}
+			if end < len(code) {
+				end++ // The closing quote
+			}
+			if end > len(code) {
+				end = len(code)
+			}
+			span("s", code[i:end])
+			i = end
+		case isWordRune(r):
+			end := i
+			for end < len(code) {
+				next, n := utf8.DecodeRuneInString(code[end:])
+				if !isWordRune(next) && !(unicode.IsDigit(r) && next == '.') {
+					break
+				}
+				end += n
+			}
+			word := code[i:end]
+			key := word
+			if syn.foldCase {
+				key = strings.ToLower(word)
+			}
+			switch {
+			case unicode.IsDigit(r):
+				span("n", word)
+			case syn.keywords[key]:
+				span("k", word)
+			default:
+				b.WriteString(html.EscapeString(word))
+			}
+			i = end
+		default:
+			b.WriteString(html.EscapeString(rest[:size]))
+			i += size
+		}
+	}
+	return b.String()
+}
+
+// lineCommentAt reports whether text starts with a line comment
+func (s syntax) lineCommentAt(text string) bool {
+	for _, prefix := range s.lineComments {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
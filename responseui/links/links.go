@@ -0,0 +1,107 @@
+// Package links signs and verifies response links for both the remote bridge
+// and the desktop app. The two read one key file next to bridge-config.json,
+// so either can verify the other's links.
+package links
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeyFileName holds the HMAC key for response links, next to bridge-config.json
+const KeyFileName = "bridge-link.key"
+
+var (
+	ErrInvalid = errors.New("invalid link")
+	ErrExpired = errors.New("link expired")
+)
+
+// Claims is what a response link vouches for
+type Claims struct {
+	RequestID string `json:"id"`
+	Answer    string `json:"a,omitempty"` // Empty = the form, where any answer may be given
+	Expires   int64  `json:"exp"`         // Unix seconds
+}
+
+// Signer signs links with the key kept at Path
+type Signer struct {
+	Path string // Empty keeps the key in memory only
+
+	once sync.Once
+	key  []byte
+	err  error
+}
+
+// Key loads the signing key, creating it on first use. If it can't be saved,
+// links are signed with a key that only lasts until the process exits, and
+// the error says why.
+func (s *Signer) Key() ([]byte, error) {
+	s.once.Do(func() {
+		if s.Path != "" {
+			if data, err := os.ReadFile(s.Path); err == nil {
+				if key, err := hex.DecodeString(strings.TrimSpace(string(data))); err == nil && len(key) >= 32 {
+					s.key = key
+					return
+				}
+			}
+		}
+
+		s.key = make([]byte, 32)
+		rand.Read(s.key)
+		if s.Path != "" {
+			s.err = os.WriteFile(s.Path, []byte(hex.EncodeToString(s.key)), 0600)
+		}
+	})
+	return s.key, s.err
+}
+
+// mac signs a payload
+func (s *Signer) mac(payload []byte) []byte {
+	key, _ := s.Key()
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// Sign encodes claims as payload.signature, both base64url
+func (s *Signer) Sign(claims Claims) string {
+	payload, _ := json.Marshal(claims)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(s.mac(payload))
+}
+
+// Verify checks a token's signature and expiry
+func (s *Signer) Verify(token string, now time.Time) (Claims, error) {
+	var claims Claims
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return claims, ErrInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return claims, ErrInvalid
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return claims, ErrInvalid
+	}
+
+	if !hmac.Equal(signature, s.mac(payload)) {
+		return claims, ErrInvalid
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.RequestID == "" {
+		return claims, ErrInvalid
+	}
+	if now.Unix() > claims.Expires {
+		return claims, ErrExpired
+	}
+	return claims, nil
+}
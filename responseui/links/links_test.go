@@ -0,0 +1,71 @@
+package links
+
+import (
+	"bytes"
+	"encoding/base64"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	s := &Signer{}
+	now := time.Now()
+	valid := s.Sign(Claims{RequestID: "req1", Answer: "Yes", Expires: now.Add(time.Hour).Unix()})
+	payload, signature, _ := strings.Cut(valid, ".")
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"id":"req1","a":"No","exp":9999999999}`)) + "." + signature
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{"valid", valid, nil},
+		{"expired", s.Sign(Claims{RequestID: "req1", Expires: now.Add(-time.Minute).Unix()}), ErrExpired},
+		{"answer swapped", forged, ErrInvalid},
+		{"signature cut short", payload + "." + signature[:10], ErrInvalid},
+		{"no signature", payload, ErrInvalid},
+		{"not base64", "!!!.???", ErrInvalid},
+		{"no request", s.Sign(Claims{Expires: now.Add(time.Hour).Unix()}), ErrInvalid},
+		{"signed with another key", (&Signer{}).Sign(Claims{RequestID: "req1", Answer: "Yes", Expires: now.Add(time.Hour).Unix()}), ErrInvalid},
+	}
+	for _, tt := range tests {
+		claims, err := s.Verify(tt.token, now)
+		if err != tt.wantErr {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if err == nil && (claims.RequestID != "req1" || claims.Answer != "Yes") {
+			t.Errorf("%s: claims = %+v", tt.name, claims)
+		}
+	}
+}
+
+// TestSharedKey checks that two processes reading one key file verify each other's links
+func TestSharedKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), KeyFileName)
+	bridge, app := &Signer{Path: path}, &Signer{Path: path}
+	bridgeKey, err := bridge.Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if appKey, _ := app.Key(); !bytes.Equal(appKey, bridgeKey) {
+		t.Fatal("the second signer made its own key")
+	}
+
+	token := bridge.Sign(Claims{RequestID: "req1", Expires: time.Now().Add(time.Hour).Unix()})
+	if _, err := app.Verify(token, time.Now()); err != nil {
+		t.Errorf("verify: %v", err)
+	}
+}
+
+func TestUnsavedKey(t *testing.T) {
+	s := &Signer{Path: filepath.Join(t.TempDir(), "missing", KeyFileName)}
+	if key, err := s.Key(); err == nil || len(key) != 32 {
+		t.Errorf("key %d bytes, err %v", len(key), err)
+	}
+	token := s.Sign(Claims{RequestID: "req1", Expires: time.Now().Add(time.Hour).Unix()})
+	if _, err := s.Verify(token, time.Now()); err != nil {
+		t.Errorf("the in-memory key doesn't verify: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package responseui
+
+import (
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+// Inline Markdown, matched against text that has already been escaped
+var (
+	mdLink   = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^\s)*]+)\)`)
+	mdBold   = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	mdItalic = regexp.MustCompile(`(^|[^\w*])\*([^*\s](?:[^*]*[^*\s])?)\*`)
+	mdOrder  = regexp.MustCompile(`^\d+[.)]\s+`)
+	mdHead   = regexp.MustCompile(`^#{1,6}\s+`)
+)
+
+// renderMarkdown turns the Markdown agents write in questions into HTML: fenced
+// code blocks, lists, headings, quotes, inline code, bold, italics and http(s)
+// links. Text is escaped before any tag is added, so the only markup in the
+// result is what this function writes.
+func renderMarkdown(text string) template.HTML {
+	var out, para, code []string
+	list := ""                 // "ul" or "ol" while a list is open
+	inCode, fence := false, "" // fence is the language after the opening ```
+
+	flush := func() {
+		if len(para) > 0 {
+			out = append(out, "<p>"+strings.Join(para, "<br>")+"</p>")
+			para = nil
+		}
+		if list != "" {
+			out = append(out, "</"+list+">")
+			list = ""
+		}
+	}
+	item := func(kind, content string) {
+		if len(para) > 0 || list != kind {
+			flush()
+			out = append(out, "<"+kind+">")
+			list = kind
+		}
+		out = append(out, "<li>"+renderInline(content)+"</li>")
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if inCode {
+			if strings.HasPrefix(trimmed, "```") {
+				out = append(out, "<pre><code>"+highlightString(strings.Join(code, "\n"), fence)+"</code></pre>")
+				code, inCode = nil, false
+			} else {
+				code = append(code, line)
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			inCode, fence = true, strings.TrimPrefix(trimmed, "```")
+		case trimmed == "":
+			flush()
+		case mdHead.MatchString(trimmed):
+			flush()
+			out = append(out, "<h3>"+renderInline(mdHead.ReplaceAllString(trimmed, ""))+"</h3>")
+		case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "), strings.HasPrefix(trimmed, "+ "):
+			item("ul", trimmed[2:])
+		case mdOrder.MatchString(trimmed):
+			item("ol", mdOrder.ReplaceAllString(trimmed, ""))
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			out = append(out, "<blockquote>"+renderInline(strings.TrimSpace(trimmed[1:]))+"</blockquote>")
+		default:
+			if list != "" {
+				flush()
+			}
+			para = append(para, renderInline(trimmed))
+		}
+	}
+	if inCode {
+		// An unclosed fence still shows as code rather than losing the text
+		out = append(out, "<pre><code>"+highlightString(strings.Join(code, "\n"), fence)+"</code></pre>")
+	}
+	flush()
+	return template.HTML(strings.Join(out, "\n"))
+}
+
+// renderInline escapes one line and applies inline code, links, bold and italics
+func renderInline(line string) string {
+	parts := strings.Split(line, "`")
+	if len(parts)%2 == 0 {
+		// A lone backtick is literal text
+		parts[len(parts)-2] += "`" + parts[len(parts)-1]
+		parts = parts[:len(parts)-1]
+	}
+
+	var b strings.Builder
+	for i, part := range parts {
+		if i%2 == 1 {
+			b.WriteString("<code>" + html.EscapeString(part) + "</code>")
+			continue
+		}
+		s := html.EscapeString(part)
+		s = mdLink.ReplaceAllString(s, `<a href="$2" target="_blank" rel="noopener noreferrer">$1</a>`)
+		s = mdBold.ReplaceAllString(s, "<strong>$1</strong>")
+		s = mdItalic.ReplaceAllString(s, "$1<em>$2</em>")
+		b.WriteString(s)
+	}
+	return b.String()
+}
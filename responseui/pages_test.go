@@ -0,0 +1,251 @@
+package responseui
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// Strings an agent (or whoever wrote the text it is quoting) could use to
+// break out of the page. None of them may reach the HTML unescaped.
+var hostileInputs = []string{
+	`<script>alert(1)</script>`,
+	`'); alert(document.cookie); ('`,
+	`"><img src=x onerror=alert(1)>`,
+	`</div><iframe src="https://evil.example"></iframe>`,
+	`<a href="javascript:alert(1)">click</a>`,
+	`<svg/onload=alert(1)>`,
+	"`</code><script>alert(1)</script>`",
+	"```\n</code></pre><script>alert(1)</script>\n```",
+	`[click](javascript:alert(1))`,
+	`[click](https://ok.example/" onmouseover="alert(1))`,
+	`**<b onclick=alert(1)>bold</b>**`,
+	"- <script>alert(1)</script>\n1. <img src=x onerror=alert(1)>",
+	"> <style>body{display:none}</style>",
+	`{{.Token}} %s %!v`,
+}
+
+// Tags and attributes the pages themselves use
+var (
+	allowedTags = map[string]bool{
+		"html": true, "head": true, "meta": true, "title": true, "style": true, "body": true,
+		"div": true, "h1": true, "h3": true, "p": true, "br": true, "form": true, "button": true, "input": true,
+		"ul": true, "ol": true, "li": true, "blockquote": true, "pre": true, "code": true, "strong": true, "em": true, "a": true,
+		"span": true, "details": true, "summary": true, "label": true, "select": true, "option": true,
+	}
+	allowedAttrs = map[string]bool{
+		"lang": true, "data-theme": true, "charset": true, "name": true, "content": true, "class": true, "method": true, "action": true,
+		"type": true, "value": true, "placeholder": true, "required": true, "autofocus": true, "inputmode": true,
+		"autocomplete": true, "disabled": true, "hidden": true, "aria-hidden": true, "href": true, "target": true, "rel": true,
+		"open": true, "step": true, "min": true, "max": true, "pattern": true,
+	}
+)
+
+// assertNoInjection parses the page and fails on any element, attribute or
+// link target the templates don't produce themselves
+func assertNoInjection(t *testing.T, body string) {
+	t.Helper()
+	z := html.NewTokenizer(strings.NewReader(body))
+	styles := 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		tok := z.Token()
+		if !allowedTags[tok.Data] {
+			t.Errorf("unexpected <%s> in page:\n%s", tok.Data, body)
+		}
+		if tok.Data == "style" {
+			styles++
+		}
+		for _, attr := range tok.Attr {
+			if !allowedAttrs[attr.Key] {
+				t.Errorf("unexpected attribute %s on <%s>:\n%s", attr.Key, tok.Data, body)
+			}
+			if attr.Key == "href" && !strings.HasPrefix(attr.Val, "https://") && !strings.HasPrefix(attr.Val, "http://") {
+				t.Errorf("unexpected link to %q:\n%s", attr.Val, body)
+			}
+		}
+	}
+	if styles > 1 {
+		t.Errorf("page has %d <style> elements:\n%s", styles, body)
+	}
+}
+
+func TestRenderMarkdownEscapesHostileInput(t *testing.T) {
+	for _, input := range hostileInputs {
+		assertNoInjection(t, string(renderMarkdown(input)))
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain text", "<p>plain text</p>"},
+		{"line one\nline two", "<p>line one<br>line two</p>"},
+		{"Run `rm -rf build`?", "<p>Run <code>rm -rf build</code>?</p>"},
+		{"```go\nif a < b {\n}\n```", "<pre><code><span class=\"k\">if</span> a &lt; b {\n}</code></pre>"},
+		{"```\nunclosed", "<pre><code>unclosed</code></pre>"},
+		{"- one\n- two", "<ul>\n<li>one</li>\n<li>two</li>\n</ul>"},
+		{"1. first\n2) second", "<ol>\n<li>first</li>\n<li>second</li>\n</ol>"},
+		{"## Plan", "<h3>Plan</h3>"},
+		{"> quoted", "<blockquote>quoted</blockquote>"},
+		{"**bold** and *em*", "<p><strong>bold</strong> and <em>em</em></p>"},
+		{"2*3*4 and snake_case_name", "<p>2*3*4 and snake_case_name</p>"},
+		{"see [docs](https://example.com/a?b=1&c=2)", `<p>see <a href="https://example.com/a?b=1&amp;c=2" target="_blank" rel="noopener noreferrer">docs</a></p>`},
+		{"a lone ` backtick", "<p>a lone ` backtick</p>"},
+	}
+	for _, tt := range tests {
+		if got := string(renderMarkdown(tt.in)); got != tt.want {
+			t.Errorf("renderMarkdown(%q)\n got: %q\nwant: %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// testPages posts back the way the desktop app does
+var testPages = Pages{Action: "/respond", Field: "answer"}
+
+func TestQuestionPageEscapesHostileInput(t *testing.T) {
+	for _, input := range hostileInputs {
+		w := httptest.NewRecorder()
+		testPages.Question(w, 400, input, Request{Question: input, Options: []string{input, "Yes"}, Context: hostileContext(input)}, input)
+		body := w.Body.String()
+		assertNoInjection(t, body)
+		if !strings.Contains(body, `value="Yes"`) {
+			t.Errorf("option button missing for %q", input)
+		}
+	}
+}
+
+func TestQuestionPageHasNoInlineScript(t *testing.T) {
+	w := httptest.NewRecorder()
+	testPages.Question(w, 200, "tok", Request{Question: "Deploy?", Options: []string{"Yes", "No"}, Context: Context{Command: "rm -rf build", Diff: "--- a/x.go\n+++ b/x.go\n@@ -1 +1 @@\n-a\n+b"}}, "")
+	body := w.Body.String()
+	for _, bad := range []string{"<script", "onclick", "window.location"} {
+		if strings.Contains(body, bad) {
+			t.Errorf("question page contains %q", bad)
+		}
+	}
+	if !strings.Contains(body, `action="/respond?t=tok"`) || !strings.Contains(body, `name="answer" value="No"`) {
+		t.Errorf("question page does not post answers back:\n%s", body)
+	}
+}
+
+func TestTokenIsQueryEscaped(t *testing.T) {
+	w := httptest.NewRecorder()
+	testPages.Question(w, 200, `a"b&c d`, Request{Question: "Q"}, "")
+	if !strings.Contains(w.Body.String(), `action="/respond?t=a%22b%26c%20d"`) {
+		t.Errorf("token not escaped in form action:\n%s", w.Body.String())
+	}
+}
+
+func TestSecondFactorPageEscapesHostileInput(t *testing.T) {
+	for _, input := range hostileInputs {
+		for _, answer := range []string{"", input} {
+			w := httptest.NewRecorder()
+			testPages.SecondFactor(w, 401, input, Request{Question: input, Options: []string{input}, Context: hostileContext(input)}, answer, input)
+			if w.Code != 401 {
+				t.Errorf("status = %d, want 401", w.Code)
+			}
+			assertNoInjection(t, w.Body.String())
+		}
+	}
+}
+
+func TestConfirmPage(t *testing.T) {
+	w := httptest.NewRecorder()
+	testPages.Confirm(w, "tok", Request{Question: "Deploy?", Options: []string{"Yes", "No"}}, "Yes")
+	body := w.Body.String()
+	if !strings.Contains(body, `method="POST" action="/respond?t=tok"`) || !strings.Contains(body, `name="answer" value="Yes"`) {
+		t.Errorf("confirm page does not post the answer back:\n%s", body)
+	}
+	if strings.Contains(body, `value="No"`) {
+		t.Errorf("confirm page offers another answer:\n%s", body)
+	}
+	for _, input := range hostileInputs {
+		w := httptest.NewRecorder()
+		testPages.Confirm(w, input, Request{Question: input, Context: hostileContext(input)}, input)
+		assertNoInjection(t, w.Body.String())
+	}
+}
+
+func TestResultPagesEscapeHostileInput(t *testing.T) {
+	for _, input := range hostileInputs {
+		w := httptest.NewRecorder()
+		testPages.Confirmation(w, input)
+		assertNoInjection(t, w.Body.String())
+
+		w = httptest.NewRecorder()
+		testPages.Error(w, 409, "answered_via", input, input)
+		if w.Code != 409 {
+			t.Errorf("status = %d, want 409", w.Code)
+		}
+		assertNoInjection(t, w.Body.String())
+
+		// Unknown keys are shown as they are, so they must be escaped too
+		w = httptest.NewRecorder()
+		testPages.Expired(w, input)
+		if w.Code != 410 {
+			t.Errorf("status = %d, want 410", w.Code)
+		}
+		assertNoInjection(t, w.Body.String())
+	}
+}
+
+func TestBrandIsEscaped(t *testing.T) {
+	for _, input := range hostileInputs {
+		w := httptest.NewRecorder()
+		Pages{Config: Config{Brand: input}, Action: "/respond", Field: "answer"}.Question(w, 200, "tok", Request{Question: "Q"}, "")
+		assertNoInjection(t, w.Body.String())
+	}
+}
+
+func TestBrandThemeAndLanguage(t *testing.T) {
+	tests := []struct {
+		cfg  Config
+		want []string
+	}{
+		{Config{}, []string{`<html lang="en">`, "<title>Remote Bridge - Respond</title>", `content="light dark"`, "Agent Question"}},
+		{Config{Brand: "Acme Ops", Theme: "dark"}, []string{`<html lang="en" data-theme="dark">`, "<title>Acme Ops - Respond</title>", `content="dark"`}},
+		{Config{Theme: "purple"}, []string{`<html lang="en">`}},
+		{Config{Language: "ES"}, []string{`<html lang="es">`, "Pregunta del agente"}},
+		{Config{Language: "xx"}, []string{`<html lang="en">`, "Agent Question"}},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		Pages{Config: tt.cfg, Action: "/respond", Field: "answer"}.Question(w, 200, "tok", Request{Question: "Q"}, "")
+		for _, want := range tt.want {
+			if !strings.Contains(w.Body.String(), want) {
+				t.Errorf("%+v: page does not contain %q", tt.cfg, want)
+			}
+		}
+	}
+}
+
+func TestTranslationsAreComplete(t *testing.T) {
+	for _, code := range Languages() {
+		for key, english := range locales["en"] {
+			text, ok := locales[code][key]
+			if !ok {
+				t.Errorf("%s.json is missing %q", code, key)
+				continue
+			}
+			if strings.Count(text, "%") != strings.Count(english, "%") {
+				t.Errorf("%s.json %q has different format verbs from English", code, key)
+			}
+		}
+		for key := range locales[code] {
+			if _, ok := locales["en"][key]; !ok {
+				t.Errorf("%s.json has %q, which English does not", code, key)
+			}
+		}
+	}
+}
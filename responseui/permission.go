@@ -0,0 +1,168 @@
+package responseui
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// PermissionOptions are the buttons a permission request offers in chats
+var PermissionOptions = []string{"Approve", "Deny"}
+
+// Words that start an approving or denying reply; anything else is refused
+// rather than guessed at
+var (
+	approveWords = []string{"approve", "approved", "allow", "yes", "y", "ok"}
+	denyWords    = []string{"deny", "denied", "reject", "no", "n"}
+)
+
+// Permission is the answer to a permission request
+type Permission struct {
+	Approved bool   `json:"approved"`
+	Note     string `json:"note,omitempty"` // Anything the approver added, e.g. "use a branch"
+}
+
+// String is the decision as people read it
+func (p Permission) String() string {
+	decision := "Denied"
+	if p.Approved {
+		decision = "Approved"
+	}
+	if p.Note != "" {
+		return decision + ": " + p.Note
+	}
+	return decision
+}
+
+// parsePermission reads a reply such as "Approve", "deny: use a branch" or
+// "Yes, create it", or the normalized JSON form
+func parsePermission(answer string) (Permission, error) {
+	var p Permission
+	if strings.HasPrefix(answer, "{") {
+		if err := json.Unmarshal([]byte(answer), &p); err != nil {
+			return Permission{}, fmt.Errorf("expected approve or deny")
+		}
+		p.Note = strings.TrimSpace(p.Note)
+		return p, nil
+	}
+
+	end := strings.IndexFunc(answer, func(r rune) bool { return !unicode.IsLetter(r) })
+	if end < 0 {
+		end = len(answer)
+	}
+	word := strings.ToLower(answer[:end])
+	switch {
+	case containsWord(approveWords, word):
+		p.Approved = true
+	case containsWord(denyWords, word):
+	default:
+		return Permission{}, fmt.Errorf("start the answer with approve or deny")
+	}
+	p.Note = strings.TrimSpace(strings.TrimLeft(answer[end:], " \t\n:,.;-–—!"))
+	return p, nil
+}
+
+// permissionFromForm reads the response page's decision button and note box
+func permissionFromForm(form url.Values, field string) (Permission, error) {
+	p, err := parsePermission(strings.TrimSpace(form.Get(field)))
+	if err != nil {
+		return Permission{}, err
+	}
+	if note := strings.TrimSpace(form.Get(field + ".note")); note != "" {
+		p.Note = note
+	}
+	return p, nil
+}
+
+func containsWord(words []string, word string) bool {
+	for _, w := range words {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionResult is the typed request_permission decision returned to the
+// agent. Anything but an explicit approval - a denial, a timeout, no
+// consensus - is not approved.
+type PermissionResult struct {
+	RequestID string `json:"request_id"`
+	Approved  bool   `json:"approved"`
+	Note      string `json:"note,omitempty"`
+	Approver  string `json:"approver,omitempty"` // Who decided: approver names, else the chat user or channel
+	TimedOut  bool   `json:"timed_out"`
+}
+
+// NewPermissionTool declares request_permission; options add the parameters
+// only one bridge understands
+func NewPermissionTool(options ...mcp.ToolOption) mcp.Tool {
+	return mcp.NewTool("request_permission", append([]mcp.ToolOption{
+		mcp.WithDescription(`Ask the user to approve or deny an action before taking it. Returns {"approved": bool, "note": string, "approver": string}; ` +
+			`anything but an explicit approval, including a timeout, comes back as "approved": false.`),
+		mcp.WithString("action", mcp.Required(), mcp.Description("What you want to do, e.g. \"Delete the build cache\"")),
+		mcp.WithString("command", mcp.Description("Command line you would run")),
+		mcp.WithArray("paths", mcp.Description("Files or directories the action touches")),
+		mcp.WithString("risk_level", mcp.Enum("low", "medium", "high"), mcp.Description("How risky the action is; \"high\" requires the user's PIN or authenticator code")),
+		mcp.WithString("justification", mcp.Description("Why the action is needed (Markdown)")),
+		mcp.WithNumber("timeout_seconds", mcp.Description("How long to wait for a decision (default 15 minutes)")),
+	}, options...)...)
+}
+
+// PermissionDetails is the Markdown shown with a permission request: the
+// justification, then the paths the action touches
+func PermissionDetails(justification string, paths []string) string {
+	details := strings.TrimSpace(justification)
+	if len(paths) == 0 {
+		return details
+	}
+	var sb strings.Builder
+	if details != "" {
+		sb.WriteString(details + "\n\n")
+	}
+	sb.WriteString("**Paths**\n")
+	for _, path := range paths {
+		fmt.Fprintf(&sb, "\n- `%s`", strings.ReplaceAll(path, "`", "'"))
+	}
+	return sb.String()
+}
+
+// NewPermissionResult reads the decision out of an answered permission
+// request, given its parsed answer and the votes cast. Every voter who chose
+// the decision is named and their notes are kept.
+func NewPermissionResult(requestID string, value interface{}, timedOut bool, votes []Vote) PermissionResult {
+	decision, ok := value.(Permission)
+	out := PermissionResult{RequestID: requestID, Approved: ok && decision.Approved, TimedOut: timedOut}
+	if !ok {
+		return out
+	}
+
+	schema := Schema{Field: Field{Type: TypePermission}}
+	var approvers, notes []string
+	for _, vote := range votes {
+		v, ok := schema.Value(vote.Answer).(Permission)
+		if !ok || v.Approved != decision.Approved {
+			continue
+		}
+		approvers = append(approvers, vote.Name())
+		if v.Note != "" && !containsWord(notes, v.Note) {
+			notes = append(notes, v.Note)
+		}
+	}
+	out.Approver = strings.Join(approvers, ", ")
+	out.Note = strings.Join(notes, "; ")
+	if out.Note == "" {
+		out.Note = decision.Note
+	}
+	return out
+}
+
+// Text renders the result for the agent as JSON
+func (r PermissionResult) Text() string {
+	data, _ := json.MarshalIndent(r, "", "  ")
+	return string(data)
+}
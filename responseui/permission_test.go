@@ -0,0 +1,48 @@
+package responseui
+
+import "testing"
+
+func TestNewPermissionResult(t *testing.T) {
+	votes := []Vote{
+		{Approver: "ana", Answer: `{"approved":true,"note":"after 6pm"}`, Via: "slack"},
+		{Answer: `{"approved":false}`, Via: "telegram", User: "@bo"},
+		{Answer: `{"approved":true}`, Via: "discord", User: "cy#1"},
+		{Answer: `{"approved":true}`, Via: "ntfy"},
+	}
+	tests := []struct {
+		name     string
+		value    interface{}
+		votes    []Vote
+		timedOut bool
+		want     PermissionResult
+	}{
+		{"approved by several", Permission{Approved: true}, votes, false, PermissionResult{RequestID: "r1", Approved: true, Note: "after 6pm", Approver: "ana, cy#1, ntfy"}},
+		{"denied by a chat user", Permission{Approved: false}, votes[1:2], false, PermissionResult{RequestID: "r1", Approver: "@bo"}},
+		{"note without votes", Permission{Approved: false, Note: "use a branch"}, nil, false, PermissionResult{RequestID: "r1", Note: "use a branch"}},
+		{"timed out", nil, nil, true, PermissionResult{RequestID: "r1", TimedOut: true}},
+		{"not a permission", "Approve", votes, false, PermissionResult{RequestID: "r1"}},
+	}
+	for _, tt := range tests {
+		if got := NewPermissionResult("r1", tt.value, tt.timedOut, tt.votes); got != tt.want {
+			t.Errorf("%s: NewPermissionResult = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPermissionDetails(t *testing.T) {
+	tests := []struct {
+		name          string
+		justification string
+		paths         []string
+		want          string
+	}{
+		{"justification only", " Frees 2 GB ", nil, "Frees 2 GB"},
+		{"paths only", "", []string{"build/"}, "**Paths**\n\n- `build/`"},
+		{"both", "Frees 2 GB", []string{"a`b", "c"}, "Frees 2 GB\n\n**Paths**\n\n- `a'b`\n- `c`"},
+	}
+	for _, tt := range tests {
+		if got := PermissionDetails(tt.justification, tt.paths); got != tt.want {
+			t.Errorf("%s: PermissionDetails = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
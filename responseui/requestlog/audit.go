@@ -0,0 +1,58 @@
+package requestlog
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// AuditFileName sits next to bridge-config.json. Unlike the request log it is
+// only ever appended to.
+const AuditFileName = "bridge-audit.jsonl"
+
+// AuditEntry is one line of the audit log
+type AuditEntry struct {
+	Time         time.Time  `json:"time"`
+	Event        string     `json:"event"` // "response", "rejected" or "decision"
+	RequestID    string     `json:"request_id"`
+	Question     string     `json:"question"`
+	Options      []string   `json:"options"`
+	Notified     []string   `json:"notified,omitempty"` // Channels a notification was sent to
+	Deliveries   []Delivery `json:"deliveries,omitempty"`
+	Approver     string     `json:"approver,omitempty"`
+	User         string     `json:"user,omitempty"`
+	Via          string     `json:"via,omitempty"`
+	ClientIP     string     `json:"client_ip,omitempty"`
+	Answer       string     `json:"answer"`
+	Outcome      string     `json:"outcome,omitempty"`       // Decisions: "answered", "no_consensus", "expired", "undelivered" or "cancelled"; rejections: why
+	LatencyMs    int64      `json:"latency_ms"`              // Since the request was created
+	SecondFactor string     `json:"second_factor,omitempty"` // How a high-risk answer was confirmed
+}
+
+// AppendAudit writes one entry to the end of the audit log at path. Like the
+// request log it takes the OS lock on path + ".lock", which also keeps
+// goroutines of one process from interleaving their lines.
+func AppendAudit(path string, entry AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	lock, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+	if err := lockFile(lock); err != nil {
+		return err
+	}
+	defer unlockFile(lock)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,49 @@
+package requestlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// TestAppendAudit checks that concurrent appends each land as one whole line
+func TestAppendAudit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), AuditFileName)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			entry := AuditEntry{Event: "response", RequestID: fmt.Sprintf("req%d", i), Deliveries: []Delivery{{Channel: "slack", OK: true}}}
+			if err := AppendAudit(path, entry); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		seen[entry.RequestID] = true
+	}
+	if len(seen) != 50 {
+		t.Errorf("%d distinct entries, want 50", len(seen))
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("mode %v", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+
+package requestlog
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile blocks until this process holds an exclusive lock on f
+func lockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+}
+
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
+
+// processAlive reports whether a process with this PID is running
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
@@ -0,0 +1,403 @@
+// Package requestlog persists requests for both the remote bridge and the
+// desktop app as an append-only JSON-lines file, one line per change, where the
+// last line for an ID wins.
+//
+// The desktop app and bridges started with --mcp share one file, so every
+// change takes an OS file lock, and each process first replays whatever the
+// others appended. A pending request belongs to the process waiting on it; the
+// others only take it over once that process is gone. The audit log beside it
+// is shared the same way.
+package requestlog
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui"
+)
+
+// FileName is the log's name; both binaries keep it next to bridge-config.json
+const FileName = "bridge-requests.jsonl"
+
+// Retention is how long finished requests are kept when the log is compacted
+const Retention = 30 * 24 * time.Hour
+
+// Request is the persisted state of a request
+type Request struct {
+	ID           string              `json:"id"`
+	Question     string              `json:"question"`
+	Options      []string            `json:"options"`
+	Category     string              `json:"category,omitempty"`
+	Workspace    string              `json:"workspace,omitempty"`
+	Risk         string              `json:"risk,omitempty"`
+	Context      *responseui.Context `json:"context,omitempty"`       // Nil when the agent sent none
+	Schema       *responseui.Schema  `json:"answer_schema,omitempty"` // Nil for a classic question
+	Status       string              `json:"status"`                  // "pending", "answered", "expired" or "cancelled"
+	Answer       string              `json:"answer,omitempty"`
+	Via          string              `json:"via,omitempty"` // Channel that decided it, or "timeout"
+	Approvers    []string            `json:"approvers,omitempty"`
+	Needed       int                 `json:"needed,omitempty"`
+	Votes        []responseui.Vote   `json:"votes,omitempty"`
+	Deliveries   []Delivery          `json:"deliveries,omitempty"`
+	UsedLinks    []string            `json:"used_links,omitempty"` // LinkIDs of single-use links spent on it
+	Delivered    bool                `json:"delivered"`            // The decision reached the agent
+	Owner        int                 `json:"owner,omitempty"`      // PID of the bridge process waiting on it
+	URL          string              `json:"url,omitempty"`        // Owner's loopback server, where the desktop app sends answers
+	CreatedAt    time.Time           `json:"created_at"`
+	Deadline     time.Time           `json:"deadline"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+	SecondFactor bool                `json:"second_factor,omitempty"` // Answers need a PIN or TOTP code
+}
+
+// Delivery records whether a notification reached one channel
+type Delivery struct {
+	Channel string    `json:"channel"`
+	OK      bool      `json:"ok"`
+	Error   string    `json:"error,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// LinkID identifies a response link token in UsedLinks without storing the token itself
+func LinkID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:16])
+}
+
+// OptionalContext is what Request keeps of a request's context
+func OptionalContext(c responseui.Context) *responseui.Context {
+	if c.IsZero() {
+		return nil
+	}
+	return &c
+}
+
+// QuestionContext is the request's context, empty when none was stored
+func (r Request) QuestionContext() responseui.Context {
+	if r.Context == nil {
+		return responseui.Context{}
+	}
+	return *r.Context
+}
+
+// OptionalSchema is what Request keeps of a request's answer schema
+func OptionalSchema(s responseui.Schema) *responseui.Schema {
+	if s.IsZero() {
+		return nil
+	}
+	return &s
+}
+
+// AnswerSchema is the request's answer schema, zero when none was stored
+func (r Request) AnswerSchema() responseui.Schema {
+	if r.Schema == nil {
+		return responseui.Schema{}
+	}
+	return *r.Schema
+}
+
+// Undelivered reports whether the request was answered but the answer never
+// reached the agent
+func (r Request) Undelivered() bool {
+	return r.Status == "answered" && !r.Delivered
+}
+
+// Log is this process's view of the shared file. A nil Log is a no-op.
+type Log struct {
+	mu       sync.Mutex
+	path     string
+	lock     *os.File // path + ".lock", locked around every read-modify-write of path
+	pid      int
+	url      string
+	requests map[string]Request
+	file     os.FileInfo // The file replayed into requests, to notice compaction by another process
+	offset   int64       // How much of file has been replayed
+
+	OnChange func(Request) // Called after every write by this process
+}
+
+// Open replays the log at path and rewrites it without finished requests past Retention
+func Open(path string) (*Log, error) {
+	lock, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	l := &Log{path: path, lock: lock, pid: os.Getpid(), requests: make(map[string]Request)}
+	err = l.locked(func() error {
+		// A failed compaction (say, Windows refusing to replace a file another
+		// process is reading) only leaves the log longer until the next start
+		l.compact()
+		return nil
+	})
+	if err != nil {
+		lock.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// Read replays the log at path without opening it for writing, e.g. to list
+// requests held by other processes
+func Read(path string) (map[string]Request, error) {
+	requests := make(map[string]Request)
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return requests, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	_, err = replay(f, requests)
+	return requests, err
+}
+
+// replay applies every complete line of r to requests and returns how many bytes it consumed
+func replay(r io.Reader, requests map[string]Request) (int64, error) {
+	var consumed int64
+	reader := bufio.NewReaderSize(r, 64*1024)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return consumed, nil // A partial last line is left for the next replay
+		}
+		if err != nil {
+			return consumed, err
+		}
+		consumed += int64(len(line))
+		var req Request
+		if json.Unmarshal(line, &req) == nil && req.ID != "" {
+			requests[req.ID] = req
+		}
+	}
+}
+
+// locked runs fn holding the file lock, after catching up with what other
+// processes appended; callers hold l.mu or own l exclusively
+func (l *Log) locked(fn func() error) error {
+	if err := lockFile(l.lock); err != nil {
+		return err
+	}
+	defer unlockFile(l.lock)
+	if err := l.refresh(); err != nil {
+		return err
+	}
+	return fn()
+}
+
+// refresh replays lines appended since the last replay, or the whole file
+// after another process compacted it
+func (l *Log) refresh() error {
+	info, err := os.Stat(l.path)
+	if os.IsNotExist(err) {
+		l.file, l.offset = nil, 0
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if l.file == nil || !os.SameFile(l.file, info) || info.Size() < l.offset {
+		l.requests = make(map[string]Request)
+		l.offset = 0
+	}
+	if info.Size() == l.offset {
+		l.file = info
+		return nil
+	}
+
+	f, err := os.Open(l.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Seek(l.offset, io.SeekStart); err != nil {
+		return err
+	}
+	n, err := replay(f, l.requests)
+	l.file, l.offset = info, l.offset+n
+	return err
+}
+
+// compact rewrites the log with one line per request, dropping finished ones past Retention
+func (l *Log) compact() error {
+	cutoff := time.Now().Add(-Retention)
+	tmp := l.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(f)
+	for _, r := range Sort(l.requests) {
+		if r.Status != "pending" && r.UpdatedAt.Before(cutoff) {
+			delete(l.requests, r.ID)
+			continue
+		}
+		if err := enc.Encode(r); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	err = os.Rename(tmp, l.path)
+	if err != nil {
+		os.Remove(tmp)
+	}
+	l.file, l.offset = nil, 0 // Replay from scratch, compacted or not
+	if rerr := l.refresh(); err == nil {
+		err = rerr
+	}
+	return err
+}
+
+// SetURL sets the desktop answer server address stamped on requests this process saves or claims
+func (l *Log) SetURL(url string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.url = url
+}
+
+// Save records the full state of a new request, owned by this process
+func (l *Log) Save(r Request) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r.Owner, r.URL = l.pid, l.url
+	return l.locked(func() error { return l.write(r) })
+}
+
+// Update applies fn to the latest state of a request and saves it; unknown IDs are ignored
+func (l *Log) Update(id string, fn func(r *Request)) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.locked(func() error {
+		r, ok := l.requests[id]
+		if !ok {
+			return nil
+		}
+		fn(&r)
+		return l.write(r)
+	})
+}
+
+// write appends a request's state to the log; callers hold l.mu and the file lock
+func (l *Log) write(r Request) error {
+	r.UpdatedAt = time.Now()
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	// Nobody else appends while the lock is held, so this line is the last one
+	if err := l.refresh(); err != nil {
+		return err
+	}
+	if l.OnChange != nil {
+		l.OnChange(r)
+	}
+	return nil
+}
+
+// Get returns the latest state of a request
+func (l *Log) Get(id string) (Request, bool) {
+	if l == nil {
+		return Request{}, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.locked(func() error { return nil })
+	r, ok := l.requests[id]
+	return r, ok
+}
+
+// List returns all requests, newest first
+func (l *Log) List() []Request {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.locked(func() error { return nil })
+	return Sort(l.requests)
+}
+
+// ClaimOrphans takes over pending requests whose owning process is gone. Those
+// past their deadline are marked expired instead of being claimed. Answered
+// requests the owner died before delivering are claimed too, with their answer,
+// until their deadline.
+func (l *Log) ClaimOrphans(now time.Time) (claimed, expired []Request, err error) {
+	if l == nil {
+		return nil, nil, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	err = l.locked(func() error {
+		for _, r := range Sort(l.requests) {
+			if r.Owner == l.pid || processAlive(r.Owner) {
+				continue
+			}
+			switch {
+			case r.Undelivered() && r.Deadline.After(now):
+				r.Owner, r.URL = l.pid, l.url
+				claimed = append(claimed, r)
+			case r.Status != "pending":
+				continue
+			case !r.Deadline.After(now):
+				r.Status = "expired"
+				expired = append(expired, r)
+			default:
+				r.Owner, r.URL = l.pid, l.url
+				claimed = append(claimed, r)
+			}
+			if err := l.write(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return claimed, expired, err
+}
+
+// Close releases the lock file
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.lock.Close()
+}
+
+// Sort flattens requests, newest first
+func Sort(byID map[string]Request) []Request {
+	requests := make([]Request, 0, len(byID))
+	for _, r := range byID {
+		requests = append(requests, r)
+	}
+	sort.Slice(requests, func(i, j int) bool { return requests[i].CreatedAt.After(requests[j].CreatedAt) })
+	return requests
+}
@@ -0,0 +1,202 @@
+package requestlog
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestSharedLog has two processes' logs - the desktop app and an --mcp bridge -
+// append to one file while a third process starts and compacts it
+func TestSharedLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	app, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Close()
+	mcp, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mcp.Close()
+
+	const perWriter = 200
+	var wg sync.WaitGroup
+	for name, l := range map[string]*Log{"app": app, "mcp": mcp} {
+		wg.Add(1)
+		go func(name string, l *Log) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				id := fmt.Sprintf("%s-%d", name, i)
+				if err := l.Save(Request{ID: id, Status: "pending", CreatedAt: time.Now()}); err != nil {
+					t.Error(err)
+				}
+				l.Update(id, func(r *Request) { r.Status = "answered" })
+			}
+		}(name, l)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			restarted, err := Open(path)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			restarted.Close()
+		}
+	}()
+	wg.Wait()
+
+	requests, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2*perWriter {
+		t.Fatalf("%d requests survived, want %d", len(requests), 2*perWriter)
+	}
+	for id, r := range requests {
+		if r.Status != "answered" {
+			t.Errorf("%s: status %q", id, r.Status)
+		}
+	}
+
+	// Each process sees the other's changes
+	if r, ok := app.Get("mcp-7"); !ok || r.Status != "answered" {
+		t.Errorf("the app sees mcp-7 as %+v", r)
+	}
+	mcp.Update("app-3", func(r *Request) { r.Delivered = true })
+	app.Update("app-3", func(r *Request) { r.Via = "desktop" })
+	if r, _ := mcp.Get("app-3"); !r.Delivered || r.Via != "desktop" {
+		t.Errorf("an update was lost: %+v", r)
+	}
+}
+
+func TestCompactionDropsOldFinishedRequests(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Save(Request{ID: "old", Status: "pending"})
+	l.Save(Request{ID: "stale-pending", Status: "pending"})
+	l.Save(Request{ID: "recent", Status: "answered"})
+	l.Close()
+
+	// Backdate two requests past the retention period
+	requests, _ := Read(path)
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	for _, id := range []string{"old", "stale-pending"} {
+		r := requests[id]
+		r.UpdatedAt = time.Now().Add(-Retention - time.Hour)
+		if id == "old" {
+			r.Status = "answered"
+		}
+		fmt.Fprintf(f, `{"id":%q,"status":%q,"updated_at":%q}`+"\n", r.ID, r.Status, r.UpdatedAt.Format(time.RFC3339Nano))
+	}
+	f.Close()
+
+	l, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if _, ok := l.Get("old"); ok {
+		t.Error("an old answered request survived compaction")
+	}
+	for _, id := range []string{"stale-pending", "recent"} {
+		if _, ok := l.Get(id); !ok {
+			t.Errorf("%s was dropped", id)
+		}
+	}
+}
+
+// deadPID returns the PID of a process that has exited
+func deadPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	return cmd.Process.Pid
+}
+
+func TestClaimOrphans(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	now := time.Now()
+	dead := deadPID(t)
+	requests := []struct {
+		id          string
+		owner       int
+		status      string
+		delivered   bool
+		deadline    time.Time
+		wantClaimed bool
+		wantExpired bool
+	}{
+		{"mine", os.Getpid(), "pending", false, now.Add(time.Hour), false, false},
+		{"live-owner", os.Getppid(), "pending", false, now.Add(time.Hour), false, false},
+		{"dead-owner", dead, "pending", false, now.Add(time.Hour), true, false},
+		{"no-owner", 0, "pending", false, now.Add(time.Hour), true, false},
+		{"dead-and-late", dead, "pending", false, now.Add(-time.Minute), false, true},
+		{"dead-and-delivered", dead, "answered", true, now.Add(time.Hour), false, false},
+		{"dead-before-delivering", dead, "answered", false, now.Add(time.Hour), true, false},
+		{"undelivered-and-late", dead, "answered", false, now.Add(-time.Minute), false, false},
+		{"live-owner-delivering", os.Getppid(), "answered", false, now.Add(time.Hour), false, false},
+	}
+	for _, r := range requests {
+		owner := r.owner
+		l.Save(Request{ID: r.id, Status: r.status, Delivered: r.delivered, Answer: "Yes", Deadline: r.deadline})
+		l.Update(r.id, func(req *Request) { req.Owner = owner })
+	}
+
+	// The bridge that claims a request is where answers for it go from now on
+	l.SetURL("https://claimer.example")
+	claimed, expired, err := l.ClaimOrphans(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := func(rs []Request) map[string]bool {
+		m := map[string]bool{}
+		for _, r := range rs {
+			m[r.ID] = true
+		}
+		return m
+	}
+	claimedIDs, expiredIDs := ids(claimed), ids(expired)
+	for _, r := range requests {
+		if claimedIDs[r.id] != r.wantClaimed || expiredIDs[r.id] != r.wantExpired {
+			t.Errorf("%s: claimed %v, expired %v", r.id, claimedIDs[r.id], expiredIDs[r.id])
+		}
+		stored, _ := l.Get(r.id)
+		if r.wantClaimed && (stored.Owner != os.Getpid() || stored.URL != "https://claimer.example") {
+			t.Errorf("%s: owner %d at %q after claiming", r.id, stored.Owner, stored.URL)
+		}
+		if r.wantClaimed && stored.Status != r.status {
+			t.Errorf("%s: status %q after claiming", r.id, stored.Status)
+		}
+		if r.wantExpired && stored.Status != "expired" {
+			t.Errorf("%s: status %q", r.id, stored.Status)
+		}
+	}
+
+	// A second bridge finds nothing left to claim
+	other, _ := Open(path)
+	defer other.Close()
+	other.pid = dead + 1
+	if claimed, expired, _ := other.ClaimOrphans(now); len(claimed)+len(expired) != 0 {
+		t.Errorf("claimed twice: %v %v", claimed, expired)
+	}
+}
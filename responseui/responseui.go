@@ -0,0 +1,215 @@
+// Package responseui renders the pages behind response links for both the
+// remote bridge and the desktop app. Templates, styles and translations are
+// embedded, so neither binary needs extra files next to it.
+//
+// Everything from the agent goes through html/template; the question is also
+// rendered as a safe subset of Markdown.
+package responseui
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultBrand is shown in page titles when no brand is configured
+const DefaultBrand = "Remote Bridge"
+
+//go:embed templates locales
+var files embed.FS
+
+var (
+	templates = template.Must(template.New("").Funcs(template.FuncMap{
+		"markdown":  renderMarkdown,
+		"highlight": highlight,
+		"diffFiles": parseDiff,
+		"input":     input,
+	}).ParseFS(files, "templates/*.html", "templates/*.css"))
+	locales = loadLocales()
+)
+
+// Config is the user's choice of look and language for the response pages
+type Config struct {
+	Brand    string `json:"brand,omitempty"`    // Page title and header; DefaultBrand when empty
+	Language string `json:"language,omitempty"` // One of Languages(); English when empty or unknown
+	Theme    string `json:"theme,omitempty"`    // "light", "dark" or empty to follow the device
+}
+
+// Pages renders the response pages for one binary
+type Pages struct {
+	Config
+	Action string // Path the forms post back to; the link token is added as ?t=
+	Field  string // Form field that carries the answer
+}
+
+// Request is the question a response page asks
+type Request struct {
+	Question string
+	Options  []string
+	Context  Context
+	Schema   Schema
+}
+
+// view is the data every template gets
+type view struct {
+	Pages
+	Request
+	Lang    string
+	Status  int
+	Title   string
+	Message string
+	Answer  string
+	Error   string
+	Token   string
+}
+
+// fieldView is what the "field" template gets: one input and its form name
+type fieldView struct {
+	view
+	Input Field
+	Name  string
+}
+
+func input(v view, f Field, name string) fieldView {
+	return fieldView{view: v, Input: f, Name: name}
+}
+
+// loadLocales reads every locales/*.json into language -> key -> text
+func loadLocales() map[string]map[string]string {
+	entries, err := files.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	all := map[string]map[string]string{}
+	for _, entry := range entries {
+		data, err := files.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		messages := map[string]string{}
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("responseui: %s: %v", entry.Name(), err))
+		}
+		all[strings.TrimSuffix(entry.Name(), ".json")] = messages
+	}
+	return all
+}
+
+// Languages lists the language codes with a translation
+func Languages() []string {
+	codes := make([]string, 0, len(locales))
+	for code := range locales {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// lang returns the configured language if there is a translation for it
+func (p Pages) lang() string {
+	code := strings.ToLower(strings.TrimSpace(p.Language))
+	if _, ok := locales[code]; ok {
+		return code
+	}
+	return "en"
+}
+
+// T translates a message key, formatting any args into it. Keys missing from
+// a translation fall back to English.
+func (p Pages) T(key string, args ...interface{}) string {
+	text, ok := locales[p.lang()][key]
+	if !ok {
+		if text, ok = locales["en"][key]; !ok {
+			text = key
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// BrandName is the configured brand or DefaultBrand
+func (p Pages) BrandName() string {
+	if brand := strings.TrimSpace(p.Brand); brand != "" {
+		return brand
+	}
+	return DefaultBrand
+}
+
+// ThemeName is "light" or "dark" when one is forced, else empty
+func (p Pages) ThemeName() string {
+	switch p.Theme {
+	case "light", "dark":
+		return p.Theme
+	}
+	return ""
+}
+
+// Range describes a field's min and max in the page's language, or is empty
+func (p Pages) Range(f Field) string {
+	switch {
+	case f.Min != nil && f.Max != nil:
+		return p.T("answer.range_between", f.MinText(), f.MaxText())
+	case f.Min != nil:
+		return p.T("answer.range_min", f.MinText())
+	case f.Max != nil:
+		return p.T("answer.range_max", f.MaxText())
+	}
+	return ""
+}
+
+// Question shows the question and its context with inputs for the answer:
+// a button per option and a free-text box unless the schema asks otherwise.
+// errorMessage explains why a previous answer was refused.
+func (p Pages) Question(w http.ResponseWriter, code int, token string, req Request, errorMessage string) {
+	p.render(w, code, "question.html", view{Title: p.T("question.title"), Request: req, Error: errorMessage, Token: token})
+}
+
+// SecondFactor asks for the PIN or authenticator code along with the answer.
+// With a preset answer (an option link) only that answer is offered.
+func (p Pages) SecondFactor(w http.ResponseWriter, code int, token string, req Request, answer, errorMessage string) {
+	p.render(w, code, "secondfactor.html", view{Title: p.T("secondfactor.title"), Request: req, Answer: answer, Error: errorMessage, Token: token})
+}
+
+// Confirm asks the user to send the answer an option link carries. Link
+// previews and mail scanners open links by themselves, so only the POST from
+// this page answers.
+func (p Pages) Confirm(w http.ResponseWriter, token string, req Request, answer string) {
+	p.render(w, 200, "confirm.html", view{Title: p.T("confirm.title"), Request: req, Answer: answer, Token: token})
+}
+
+// Confirmation confirms the answer that was sent, as Schema.Display shows it
+func (p Pages) Confirmation(w http.ResponseWriter, answer string) {
+	p.render(w, 200, "confirmation.html", view{Title: p.T("confirmation.title"), Answer: answer})
+}
+
+// InvalidAnswer is the message Question and SecondFactor show for an answer
+// the schema refused
+func (p Pages) InvalidAnswer(err error) string {
+	return p.T("answer.invalid", err.Error())
+}
+
+// Expired tells the user a link or question has run out of time. key names a
+// pair of messages, key.title and key.message; args are formatted into the message.
+func (p Pages) Expired(w http.ResponseWriter, key string, args ...interface{}) {
+	p.render(w, 410, "expired.html", view{Title: p.T(key + ".title"), Message: p.T(key+".message", args...)})
+}
+
+// Error explains why an answer could not be taken; key works as for Expired
+func (p Pages) Error(w http.ResponseWriter, code int, key string, args ...interface{}) {
+	p.render(w, code, "error.html", view{Title: p.T(key + ".title"), Message: p.T(key+".message", args...)})
+}
+
+// render executes one template; a failure part way through only truncates the page
+func (p Pages) render(w http.ResponseWriter, code int, name string, v view) {
+	v.Pages, v.Lang, v.Status = p, p.lang(), code
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(code)
+	templates.ExecuteTemplate(w, name, v)
+}
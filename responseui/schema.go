@@ -0,0 +1,528 @@
+package responseui
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Answer types. An empty type is the classic question: buttons for the
+// options plus free text.
+const (
+	TypeChoice = "choice" // Exactly one of the options
+	TypeMulti  = "multi"  // One or more of the options
+	TypeYesNo  = "yes_no"
+	TypeNumber = "number" // Optionally between Min and Max
+	TypeText   = "text"   // Optionally matching Pattern
+	TypeForm   = "form"   // Named Fields, answered together
+
+	// Approve or deny with an optional note; the value is a Permission
+	TypePermission = "permission"
+)
+
+// Forms are meant to be filled in on a phone
+const maxFormFields = 20
+
+var fieldName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,39}$`)
+
+// Field is one answer: the whole answer, or one named field of a form
+type Field struct {
+	Name     string   `json:"name,omitempty"`  // Form fields only; the key in the answer
+	Label    string   `json:"label,omitempty"` // Form fields only; defaults to Name
+	Type     string   `json:"type,omitempty"`
+	Options  []string `json:"options,omitempty"`  // choice and multi
+	Min      *float64 `json:"min,omitempty"`      // number: smallest value; multi: fewest picks
+	Max      *float64 `json:"max,omitempty"`      // number: largest value; multi: most picks
+	Pattern  string   `json:"pattern,omitempty"`  // text: regular expression the whole answer must match
+	Optional bool     `json:"optional,omitempty"` // Form fields only; may be left empty
+}
+
+// Schema describes the answer an agent wants back. The zero Schema asks the
+// classic way and takes any text.
+type Schema struct {
+	Field
+	Fields []Field `json:"fields,omitempty"` // form
+}
+
+// SchemaArguments reads the answer_schema argument of ask_remote_human. The
+// tool's options are used when the schema lists none of its own.
+func SchemaArguments(args map[string]interface{}, options []string) (Schema, error) {
+	var s Schema
+	if raw, ok := args["answer_schema"]; ok && raw != nil {
+		data, err := json.Marshal(raw)
+		if err == nil {
+			err = json.Unmarshal(data, &s)
+		}
+		if err != nil {
+			return Schema{}, fmt.Errorf("answer_schema: %v", err)
+		}
+	}
+	return s.WithOptions(options), nil
+}
+
+// WithOptions fills in the choices of a choice or multi schema that lists
+// none of its own
+func (s Schema) WithOptions(options []string) Schema {
+	if len(s.Options) == 0 && (s.Type == TypeChoice || s.Type == TypeMulti) {
+		s.Options = options
+	}
+	return s
+}
+
+// IsZero reports whether no schema was given
+func (s Schema) IsZero() bool {
+	return s.Type == "" && len(s.Fields) == 0
+}
+
+// Structured reports whether answers are checked and returned as typed
+// values rather than as free text
+func (s Schema) Structured() bool {
+	return s.Type != ""
+}
+
+// Validate rejects schemas an answer could never satisfy
+func (s Schema) Validate() error {
+	if s.Type == "" {
+		if len(s.Fields) > 0 {
+			return fmt.Errorf("answer_schema: fields need type %q", TypeForm)
+		}
+		return nil
+	}
+	if s.Type != TypeForm {
+		if len(s.Fields) > 0 {
+			return fmt.Errorf("answer_schema: only type %q has fields", TypeForm)
+		}
+		return s.Field.validate("answer_schema")
+	}
+	if len(s.Fields) == 0 || len(s.Fields) > maxFormFields {
+		return fmt.Errorf("answer_schema: a form needs between 1 and %d fields", maxFormFields)
+	}
+	seen := map[string]bool{}
+	for _, f := range s.Fields {
+		if !fieldName.MatchString(f.Name) {
+			return fmt.Errorf("answer_schema: field name %q must be a letter followed by letters, digits or _", f.Name)
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("answer_schema: field %q appears twice", f.Name)
+		}
+		seen[f.Name] = true
+		if f.Type == TypeForm || f.Type == TypePermission || f.Type == "" {
+			return fmt.Errorf("answer_schema: field %q needs a type other than %q or %q", f.Name, TypeForm, TypePermission)
+		}
+		if err := f.validate("answer_schema field " + f.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f Field) validate(where string) error {
+	switch f.Type {
+	case TypeChoice, TypeMulti:
+		if len(f.Options) == 0 {
+			return fmt.Errorf("%s: type %q needs options", where, f.Type)
+		}
+	case TypeYesNo, TypeNumber, TypePermission:
+	case TypeText:
+		if _, err := f.pattern(); err != nil {
+			return fmt.Errorf("%s: pattern: %v", where, err)
+		}
+	default:
+		return fmt.Errorf("%s: unknown type %q", where, f.Type)
+	}
+	if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+		return fmt.Errorf("%s: min is larger than max", where)
+	}
+	return nil
+}
+
+// pattern compiles Pattern so that it has to match the whole answer
+func (f Field) pattern() (*regexp.Regexp, error) {
+	if f.Pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile(`^(?:` + f.Pattern + `)$`)
+}
+
+// Parse checks an answer against the schema and returns it as a typed value:
+// a string, bool, float64, []string, Permission, or for a form a map of field
+// name to value. Answers arrive as text from chat channels, so a multi answer may be
+// a JSON array or a comma separated list, and a form answer a JSON object.
+// A classic question takes any text.
+func (s Schema) Parse(answer string) (interface{}, error) {
+	answer = strings.TrimSpace(answer)
+	if s.Type != TypeForm {
+		return s.Field.parse(answer, nil)
+	}
+
+	values := map[string]interface{}{}
+	if answer != "" {
+		if err := json.Unmarshal([]byte(answer), &values); err != nil {
+			return nil, fmt.Errorf("expected a JSON object with the form fields")
+		}
+	}
+	result := map[string]interface{}{}
+	for name := range values {
+		if _, ok := s.field(name); !ok {
+			return nil, fmt.Errorf("there is no field %q", name)
+		}
+	}
+	for _, f := range s.Fields {
+		var text string
+		var list []string
+		switch v := values[f.Name].(type) {
+		case nil:
+		case string:
+			text = v
+		case bool:
+			text = "no"
+			if v {
+				text = "yes"
+			}
+		case float64:
+			text = strconv.FormatFloat(v, 'f', -1, 64)
+		case []interface{}:
+			for _, item := range v {
+				list = append(list, fmt.Sprint(item))
+			}
+		default:
+			return nil, fmt.Errorf("%s: unexpected value", f.label())
+		}
+		value, err := f.parse(strings.TrimSpace(text), list)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f.label(), err)
+		}
+		if !omitted(value) {
+			result[f.Name] = value
+		}
+	}
+	return result, nil
+}
+
+// parse checks one answer. list carries the picks of a multi answer that did
+// not arrive as text.
+func (f Field) parse(answer string, list []string) (interface{}, error) {
+	if answer == "" && len(list) == 0 && f.Type != TypeMulti {
+		if f.Optional {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("an answer is required")
+	}
+
+	switch f.Type {
+	case "":
+		return answer, nil
+	case TypeChoice:
+		if option, ok := matchOption(f.Options, answer); ok {
+			return option, nil
+		}
+		return nil, fmt.Errorf("%q is not one of the options", answer)
+	case TypeYesNo:
+		switch strings.ToLower(answer) {
+		case "yes", "y", "true":
+			return true, nil
+		case "no", "n", "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("answer yes or no")
+	case TypeNumber:
+		n, err := strconv.ParseFloat(answer, 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, fmt.Errorf("%q is not a number", answer)
+		}
+		if (f.Min != nil && n < *f.Min) || (f.Max != nil && n > *f.Max) {
+			return nil, fmt.Errorf("%s is out of range (%s)", answer, f.RangeText())
+		}
+		return n, nil
+	case TypeText:
+		re, _ := f.pattern()
+		if re != nil && !re.MatchString(answer) {
+			return nil, fmt.Errorf("%q does not have the expected format", answer)
+		}
+		return answer, nil
+	case TypePermission:
+		return parsePermission(answer)
+	case TypeMulti:
+		if list == nil && answer != "" && json.Unmarshal([]byte(answer), &list) != nil {
+			list = strings.Split(answer, ",")
+		}
+		picked := map[string]bool{}
+		for _, item := range list {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			option, ok := matchOption(f.Options, item)
+			if !ok {
+				return nil, fmt.Errorf("%q is not one of the options", item)
+			}
+			picked[option] = true
+		}
+		// In option order, so the same picks always give the same answer
+		choices := []string{}
+		for _, option := range f.Options {
+			if picked[option] {
+				choices = append(choices, option)
+			}
+		}
+		least := 1.0
+		if f.Optional {
+			least = 0
+		}
+		if f.Min != nil {
+			least = *f.Min
+		}
+		if float64(len(choices)) < least || (f.Max != nil && float64(len(choices)) > *f.Max) {
+			return nil, fmt.Errorf("pick %s", f.RangeText())
+		}
+		if len(choices) == 0 {
+			// An answer of no picks, not a missing one
+			return []string{}, nil
+		}
+		return choices, nil
+	}
+	return nil, fmt.Errorf("unknown type %q", f.Type)
+}
+
+// matchOption finds answer among options, ignoring case
+func matchOption(options []string, answer string) (string, bool) {
+	for _, option := range options {
+		if strings.EqualFold(option, answer) {
+			return option, true
+		}
+	}
+	return "", false
+}
+
+// Normalize checks an answer and rewrites it in one canonical form, so that
+// equal answers compare equal (quorum votes, stored answers). Strings stay as
+// they are; every other value is JSON.
+func (s Schema) Normalize(answer string) (string, error) {
+	if !s.Structured() {
+		return answer, nil
+	}
+	value, err := s.Parse(answer)
+	if err != nil {
+		return "", err
+	}
+	return formatValue(value), nil
+}
+
+// Key is what quorum votes compare: the normalized answer, or only the
+// decision of a permission, so that approvers' notes don't split the vote
+func (s Schema) Key(answer string) string {
+	if p, ok := s.Value(answer).(Permission); ok {
+		return formatValue(Permission{Approved: p.Approved})
+	}
+	return answer
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// FromForm reads an answer posted by the response page. Form fields are
+// posted as field.name; multi picks are repeated values.
+func (s Schema) FromForm(form url.Values, field string) (string, error) {
+	// Confirming on the second-factor page re-posts an answer that was
+	// already normalized, as a single value
+	if v := form[field]; len(v) == 1 && (s.Type == TypeForm || (s.Type == TypeMulti && strings.HasPrefix(v[0], "["))) {
+		return s.Normalize(v[0])
+	}
+	if s.Type == TypePermission {
+		p, err := permissionFromForm(form, field)
+		if err != nil {
+			return "", err
+		}
+		return formatValue(p), nil
+	}
+	if s.Type != TypeForm {
+		values := form[field]
+		if s.Type == TypeMulti {
+			value, err := s.Field.parse("", nonEmpty(values))
+			if err != nil {
+				return "", err
+			}
+			return formatValue(value), nil
+		}
+		return s.Normalize(strings.TrimSpace(form.Get(field)))
+	}
+
+	result := map[string]interface{}{}
+	for _, f := range s.Fields {
+		key := field + "." + f.Name
+		var value interface{}
+		var err error
+		if f.Type == TypeMulti {
+			value, err = f.parse("", nonEmpty(form[key]))
+		} else {
+			value, err = f.parse(strings.TrimSpace(form.Get(key)), nil)
+		}
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", f.label(), err)
+		}
+		if !omitted(value) {
+			result[f.Name] = value
+		}
+	}
+	return formatValue(result), nil
+}
+
+// omitted reports whether a form field is left out of the answer: an
+// optional field left empty, or a multi with no picks
+func omitted(value interface{}) bool {
+	list, isList := value.([]string)
+	return value == nil || isList && len(list) == 0
+}
+
+func nonEmpty(values []string) []string {
+	list := []string{}
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// Value returns a normalized answer as the typed value the agent gets back,
+// or nil for a classic question or no answer
+func (s Schema) Value(answer string) interface{} {
+	if !s.Structured() || answer == "" {
+		return nil
+	}
+	value, err := s.Parse(answer)
+	if err != nil {
+		return nil
+	}
+	return value
+}
+
+// Display is an answer as people read it, for confirmations and chat messages
+func (s Schema) Display(answer string) string {
+	value := s.Value(answer)
+	switch v := value.(type) {
+	case Permission:
+		return v.String()
+	case []string:
+		return strings.Join(v, ", ")
+	case map[string]interface{}:
+		var parts []string
+		for _, f := range s.Fields {
+			if fv, ok := v[f.Name]; ok {
+				text := formatValue(fv)
+				if list, ok := fv.([]string); ok {
+					text = strings.Join(list, ", ")
+				}
+				parts = append(parts, f.label()+": "+text)
+			}
+		}
+		return strings.Join(parts, "; ")
+	}
+	return answer
+}
+
+// Buttons are the options a notification can offer as one tap each; other
+// answers are typed as a reply or given on the response page
+func (s Schema) Buttons(options []string) []string {
+	switch s.Type {
+	case "", TypeChoice, TypeYesNo, TypePermission:
+		return options
+	}
+	return nil
+}
+
+// Hint tells someone answering from a chat what shape of answer is expected
+func (s Schema) Hint() string {
+	switch s.Type {
+	case TypeChoice:
+		return "Reply with one of the options."
+	case TypeMulti:
+		return "Reply with one or more of " + strings.Join(s.Options, ", ") + ", separated by commas (" + s.RangeText() + ")."
+	case TypeYesNo:
+		return "Reply yes or no."
+	case TypeNumber:
+		return "Reply with a number (" + s.RangeText() + ")."
+	case TypeText:
+		if s.Pattern != "" {
+			return "Reply with text matching " + s.Pattern
+		}
+	case TypeForm:
+		return "Open the response link to fill in the form."
+	case TypePermission:
+		return "Reply approve or deny, optionally followed by a note (e.g. \"deny: use a branch\")."
+	}
+	return ""
+}
+
+// WithHint appends the hint to a notification message
+func (s Schema) WithHint(message string) string {
+	if hint := s.Hint(); hint != "" {
+		return message + "\n\n" + hint
+	}
+	return message
+}
+
+// RangeText describes Min and Max, e.g. "1 to 10" or "at least 2"
+func (f Field) RangeText() string {
+	switch {
+	case f.Min != nil && f.Max != nil:
+		return f.MinText() + " to " + f.MaxText()
+	case f.Min != nil:
+		return "at least " + f.MinText()
+	case f.Max != nil:
+		return "at most " + f.MaxText()
+	case f.Type == TypeMulti && !f.Optional:
+		return "at least 1"
+	}
+	return "any"
+}
+
+// MinText is Min for the page's min attribute, or empty
+func (f Field) MinText() string {
+	if f.Min == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f.Min, 'f', -1, 64)
+}
+
+// MaxText is Max for the page's max attribute, or empty
+func (f Field) MaxText() string {
+	if f.Max == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f.Max, 'f', -1, 64)
+}
+
+// label is what a form field is called on the page and in errors
+func (f Field) label() string {
+	if f.Label != "" {
+		return f.Label
+	}
+	return f.Name
+}
+
+// field finds a form field by name
+func (s Schema) field(name string) (Field, bool) {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
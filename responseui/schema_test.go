@@ -0,0 +1,223 @@
+package responseui
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func number(n float64) *float64 { return &n }
+
+var deployForm = Schema{
+	Field: Field{Type: TypeForm},
+	Fields: []Field{
+		{Name: "env", Label: "Environment", Type: TypeChoice, Options: []string{"staging", "prod"}},
+		{Name: "replicas", Type: TypeNumber, Min: number(1), Max: number(10)},
+		{Name: "notify", Type: TypeYesNo, Optional: true},
+		{Name: "regions", Type: TypeMulti, Options: []string{"eu", "us", "ap"}, Optional: true},
+		{Name: "ticket", Type: TypeText, Pattern: `[A-Z]+-\d+`},
+	},
+}
+
+func TestSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		s    Schema
+		ok   bool
+	}{
+		{"classic", Schema{}, true},
+		{"choice", Schema{Field: Field{Type: TypeChoice, Options: []string{"a"}}}, true},
+		{"choice without options", Schema{Field: Field{Type: TypeChoice}}, false},
+		{"number range", Schema{Field: Field{Type: TypeNumber, Min: number(1), Max: number(2)}}, true},
+		{"inverted range", Schema{Field: Field{Type: TypeNumber, Min: number(3), Max: number(2)}}, false},
+		{"bad pattern", Schema{Field: Field{Type: TypeText, Pattern: `(`}}, false},
+		{"unknown type", Schema{Field: Field{Type: "date"}}, false},
+		{"form", deployForm, true},
+		{"empty form", Schema{Field: Field{Type: TypeForm}}, false},
+		{"fields without form", Schema{Fields: deployForm.Fields}, false},
+		{"bad field name", Schema{Field: Field{Type: TypeForm}, Fields: []Field{{Name: `a"b`, Type: TypeText}}}, false},
+		{"duplicate field", Schema{Field: Field{Type: TypeForm}, Fields: []Field{{Name: "a", Type: TypeText}, {Name: "a", Type: TypeText}}}, false},
+		{"nested form", Schema{Field: Field{Type: TypeForm}, Fields: []Field{{Name: "a", Type: TypeForm}}}, false},
+		{"permission", Schema{Field: Field{Type: TypePermission}}, true},
+		{"permission field", Schema{Field: Field{Type: TypeForm}, Fields: []Field{{Name: "a", Type: TypePermission}}}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.s.Validate(); (err == nil) != tt.ok {
+			t.Errorf("%s: Validate() = %v", tt.name, err)
+		}
+	}
+}
+
+func TestSchemaNormalize(t *testing.T) {
+	multi := Schema{Field: Field{Type: TypeMulti, Options: []string{"eu", "us", "ap"}, Max: number(2)}}
+	tests := []struct {
+		name   string
+		s      Schema
+		answer string
+		want   string // "" = refused
+	}{
+		{"classic keeps text", Schema{}, " anything ", " anything "},
+		{"choice", Schema{Field: Field{Type: TypeChoice, Options: []string{"Deploy", "Wait"}}}, "deploy", "Deploy"},
+		{"choice not an option", Schema{Field: Field{Type: TypeChoice, Options: []string{"Deploy"}}}, "maybe", ""},
+		{"yes", Schema{Field: Field{Type: TypeYesNo}}, "Yes", "yes"},
+		{"no", Schema{Field: Field{Type: TypeYesNo}}, "n", "no"},
+		{"yes_no other", Schema{Field: Field{Type: TypeYesNo}}, "perhaps", ""},
+		{"number", Schema{Field: Field{Type: TypeNumber, Min: number(1), Max: number(10)}}, "3.50", "3.5"},
+		{"number too big", Schema{Field: Field{Type: TypeNumber, Max: number(10)}}, "11", ""},
+		{"not a number", Schema{Field: Field{Type: TypeNumber}}, "NaN", ""},
+		{"text pattern", Schema{Field: Field{Type: TypeText, Pattern: `[A-Z]+-\d+`}}, "OPS-12", "OPS-12"},
+		{"text pattern is anchored", Schema{Field: Field{Type: TypeText, Pattern: `[A-Z]+-\d+`}}, "see OPS-12", ""},
+		{"empty text", Schema{Field: Field{Type: TypeText}}, "  ", ""},
+		{"multi list", multi, "us, EU", `["eu","us"]`},
+		{"multi json", multi, `["ap"]`, `["ap"]`},
+		{"multi too many", multi, "eu,us,ap", ""},
+		{"multi none", multi, "", ""},
+		{"multi none allowed", Schema{Field: Field{Type: TypeMulti, Options: []string{"eu", "us"}, Min: number(0)}}, "", `[]`},
+		{"multi none optional", Schema{Field: Field{Type: TypeMulti, Options: []string{"eu", "us"}, Optional: true}}, " ", `[]`},
+		{"multi empty json", Schema{Field: Field{Type: TypeMulti, Options: []string{"eu", "us"}, Min: number(0)}}, `[]`, `[]`},
+		{"multi unknown", multi, "eu,mars", ""},
+		{"form", deployForm, `{"env":"PROD","replicas":3,"notify":true,"ticket":"OPS-1"}`, `{"env":"prod","notify":true,"replicas":3,"ticket":"OPS-1"}`},
+		{"form missing field", deployForm, `{"env":"prod","ticket":"OPS-1"}`, ""},
+		{"form unknown field", deployForm, `{"env":"prod","replicas":3,"ticket":"OPS-1","x":1}`, ""},
+		{"form not json", deployForm, "prod, 3", ""},
+		{"approve", Schema{Field: Field{Type: TypePermission}}, "Approve", `{"approved":true}`},
+		{"approve with note", Schema{Field: Field{Type: TypePermission}}, "Yes, create it", `{"approved":true,"note":"create it"}`},
+		{"deny with note", Schema{Field: Field{Type: TypePermission}}, "deny: use a branch", `{"approved":false,"note":"use a branch"}`},
+		{"permission json", Schema{Field: Field{Type: TypePermission}}, `{"approved":false}`, `{"approved":false}`},
+		{"permission unclear", Schema{Field: Field{Type: TypePermission}}, "yesterday was fine", ""},
+		{"permission empty", Schema{Field: Field{Type: TypePermission}}, " ", ""},
+	}
+	for _, tt := range tests {
+		got, err := tt.s.Normalize(tt.answer)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("%s: Normalize(%q) = %q, want an error", tt.name, tt.answer, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s: Normalize(%q) = %q, %v; want %q", tt.name, tt.answer, got, err, tt.want)
+		}
+	}
+}
+
+func TestSchemaFromForm(t *testing.T) {
+	form := url.Values{
+		"answer.env":      {"staging"},
+		"answer.replicas": {"2"},
+		"answer.regions":  {"ap", "eu", ""},
+		"answer.ticket":   {"OPS-7"},
+	}
+	got, err := deployForm.FromForm(form, "answer")
+	if err != nil || got != `{"env":"staging","regions":["eu","ap"],"replicas":2,"ticket":"OPS-7"}` {
+		t.Fatalf("FromForm = %q, %v", got, err)
+	}
+	if display := deployForm.Display(got); display != "Environment: staging; replicas: 2; regions: eu, ap; ticket: OPS-7" {
+		t.Errorf("Display = %q", display)
+	}
+	value := deployForm.Value(got).(map[string]interface{})
+	if value["replicas"] != 2.0 || !reflect.DeepEqual(value["regions"], []string{"eu", "ap"}) {
+		t.Errorf("Value = %#v", value)
+	}
+
+	if again, err := deployForm.FromForm(url.Values{"answer": {got}}, "answer"); err != nil || again != got {
+		t.Errorf("re-posting the normalized answer = %q, %v", again, err)
+	}
+
+	form.Set("answer.replicas", "20")
+	if _, err := deployForm.FromForm(form, "answer"); err == nil || !strings.Contains(err.Error(), "replicas") {
+		t.Errorf("out of range replicas: %v", err)
+	}
+
+	multi := Schema{Field: Field{Type: TypeMulti, Options: []string{"a", "b"}}}
+	if got, err := multi.FromForm(url.Values{"answer": {"b", "a"}}, "answer"); err != nil || got != `["a","b"]` {
+		t.Errorf("multi FromForm = %q, %v", got, err)
+	}
+	noPicks := Schema{Field: Field{Type: TypeMulti, Options: []string{"a", "b"}, Optional: true}}
+	if got, err := noPicks.FromForm(url.Values{}, "answer"); err != nil || got != `[]` {
+		t.Errorf("multi FromForm with no picks = %q, %v", got, err)
+	}
+	yesNo := Schema{Field: Field{Type: TypeYesNo}}
+	if got, err := yesNo.FromForm(url.Values{"answer": {"no"}}, "answer"); err != nil || got != "no" || yesNo.Value(got) != false {
+		t.Errorf("yes_no FromForm = %q, %v", got, err)
+	}
+
+	permission := Schema{Field: Field{Type: TypePermission}}
+	got, err = permission.FromForm(url.Values{"answer": {"deny"}, "answer.note": {" not on prod "}}, "answer")
+	if err != nil || got != `{"approved":false,"note":"not on prod"}` || permission.Display(got) != "Denied: not on prod" {
+		t.Errorf("permission FromForm = %q, %v", got, err)
+	}
+	if value, ok := permission.Value(got).(Permission); !ok || value.Approved || value.Note != "not on prod" {
+		t.Errorf("permission Value = %#v", permission.Value(got))
+	}
+	if again, err := permission.FromForm(url.Values{"answer": {got}}, "answer"); err != nil || again != got {
+		t.Errorf("re-posting the normalized permission = %q, %v", again, err)
+	}
+}
+
+func TestSchemaArguments(t *testing.T) {
+	args := map[string]interface{}{"answer_schema": map[string]interface{}{"type": "multi", "max": 2.0}}
+	s, err := SchemaArguments(args, []string{"a", "b", "c"})
+	if err != nil || s.Type != TypeMulti || *s.Max != 2 || len(s.Options) != 3 {
+		t.Errorf("SchemaArguments = %+v, %v", s, err)
+	}
+	if _, err := SchemaArguments(map[string]interface{}{"answer_schema": "number"}, nil); err == nil {
+		t.Error("a string schema should be refused")
+	}
+	if s, err := SchemaArguments(map[string]interface{}{}, []string{"a"}); err != nil || !s.IsZero() {
+		t.Errorf("no schema = %+v, %v", s, err)
+	}
+}
+
+func TestSchemaPages(t *testing.T) {
+	tests := []struct {
+		schema Schema
+		want   []string
+	}{
+		{Schema{Field: Field{Type: TypeYesNo}}, []string{`name="answer" value="yes">Yes</button>`, `value="no">No</button>`}},
+		{Schema{Field: Field{Type: TypeChoice, Options: []string{"Deploy"}}}, []string{`value="Deploy">Deploy</button>`}},
+		{Schema{Field: Field{Type: TypeNumber, Min: number(1), Max: number(5)}}, []string{`type="number"`, `min="1"`, `max="5"`, `placeholder="A number (1 to 5)"`}},
+		{Schema{Field: Field{Type: TypeText, Pattern: `[A-Z]+`}}, []string{`pattern="[A-Z]&#43;"`}},
+		{Schema{Field: Field{Type: TypeMulti, Options: []string{"eu", "us"}}}, []string{`type="checkbox" name="answer" value="eu"`, "Pick one or more"}},
+		{Schema{Field: Field{Type: TypePermission}}, []string{`name="answer" value="approve">Approve</button>`, `value="deny">Deny</button>`, `name="answer.note"`}},
+		{deployForm, []string{`<select name="answer.env"`, `name="answer.replicas"`, `type="radio" name="answer.notify" value="yes">`, `Environment`, "(optional)"}},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		testPages.Question(w, 200, "tok", Request{Question: "Q", Schema: tt.schema}, "")
+		body := w.Body.String()
+		assertNoInjection(t, body)
+		for _, want := range tt.want {
+			if !strings.Contains(body, want) {
+				t.Errorf("%s page is missing %s:\n%s", tt.schema.Type, want, body)
+			}
+		}
+		if strings.Contains(body, "custom.placeholder") || strings.Contains(body, `{{`) {
+			t.Errorf("%s page has an untranslated key:\n%s", tt.schema.Type, body)
+		}
+
+		w = httptest.NewRecorder()
+		testPages.SecondFactor(w, 200, "tok", Request{Question: "Q", Schema: tt.schema}, "", "")
+		if body := w.Body.String(); !strings.Contains(body, `name="code"`) || !strings.Contains(body, tt.want[0]) {
+			t.Errorf("%s second-factor page lacks the code or the answer inputs:\n%s", tt.schema.Type, body)
+		}
+	}
+}
+
+func TestSchemaPagesEscapeHostileInput(t *testing.T) {
+	for _, input := range hostileInputs {
+		schemas := []Schema{
+			{Field: Field{Type: TypeChoice, Options: []string{input}}},
+			{Field: Field{Type: TypeMulti, Options: []string{input}}},
+			{Field: Field{Type: TypeText, Pattern: input}},
+			{Field: Field{Type: TypeForm}, Fields: []Field{{Name: "f", Label: input, Type: TypeChoice, Options: []string{input}}}},
+		}
+		for _, schema := range schemas {
+			w := httptest.NewRecorder()
+			testPages.Question(w, 400, input, Request{Question: input, Schema: schema}, input)
+			assertNoInjection(t, w.Body.String())
+		}
+	}
+}
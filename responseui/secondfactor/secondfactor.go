@@ -0,0 +1,240 @@
+// Package secondfactor checks the PIN or authenticator code that answers to
+// high-risk questions need, for both the remote bridge and the desktop app.
+package secondfactor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Wrong codes allowed within Lockout from one source for one request, before
+// no more codes are taken from it
+const (
+	MaxFailures = 5
+	Lockout     = 15 * time.Minute
+)
+
+// TOTP parameters; the common authenticator apps only support these
+const (
+	Period = 30
+	Digits = 6
+)
+
+// FileName keeps recent wrong codes and used TOTP steps next to bridge-config.json
+const FileName = "bridge-factor-failures.json"
+
+// A PIN is saved as "scrypt:<salt>:<hash>", both hex. Older versions saved a
+// single salted SHA-256 as "sha256:<salt>:<hash>", which still verifies.
+const (
+	pinHashPrefix       = "scrypt:"
+	legacyPINHashPrefix = "sha256:"
+)
+
+// scrypt cost: tens of milliseconds a guess, so the few digits of a PIN can't
+// be run through in an instant from a copy of bridge-config.json
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// HashPIN returns a salted hash of pin to save in place of the PIN itself
+func HashPIN(pin string) string {
+	salt := make([]byte, 16)
+	rand.Read(salt)
+	return pinHashPrefix + hex.EncodeToString(salt) + ":" + hex.EncodeToString(pinDigest(salt, pin))
+}
+
+// IsPINHash reports whether saved is a hash, of either kind, rather than a plain PIN
+func IsPINHash(saved string) bool {
+	return strings.HasPrefix(saved, pinHashPrefix) || strings.HasPrefix(saved, legacyPINHashPrefix)
+}
+
+// NeedsRehash reports whether saved is a plain PIN or an old SHA-256 hash, to
+// replace with HashPIN once the PIN is known
+func NeedsRehash(saved string) bool {
+	return saved != "" && !strings.HasPrefix(saved, pinHashPrefix)
+}
+
+func pinDigest(salt []byte, pin string) []byte {
+	key, _ := scrypt.Key([]byte(pin), salt, scryptN, scryptR, scryptP, 32)
+	return key
+}
+
+func legacyPINDigest(salt []byte, pin string) []byte {
+	sum := sha256.Sum256(append(append([]byte{}, salt...), pin...))
+	return sum[:]
+}
+
+// VerifyPIN checks code against a saved PIN: a HashPIN result, an older
+// SHA-256 hash, or a plain PIN from the environment
+func VerifyPIN(saved, code string) bool {
+	if !IsPINHash(saved) {
+		return subtle.ConstantTimeCompare([]byte(code), []byte(saved)) == 1
+	}
+	digest, prefix := pinDigest, pinHashPrefix
+	if strings.HasPrefix(saved, legacyPINHashPrefix) {
+		digest, prefix = legacyPINDigest, legacyPINHashPrefix
+	}
+	salt, sum, ok := strings.Cut(strings.TrimPrefix(saved, prefix), ":")
+	if !ok {
+		return false
+	}
+	saltBytes, err := hex.DecodeString(salt)
+	if err != nil {
+		return false
+	}
+	want, err := hex.DecodeString(sum)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(digest(saltBytes, code), want) == 1
+}
+
+// totpCode is the RFC 6238 code for one time step
+func totpCode(secret []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", Digits, value%1000000)
+}
+
+// ValidTOTP accepts the code for now or one step either side, to allow for clock drift
+func ValidTOTP(secret, code string, now time.Time) bool {
+	_, ok := matchTOTP(secret, code, now)
+	return ok
+}
+
+// matchTOTP is ValidTOTP that also returns the time step the code is for
+func matchTOTP(secret, code string, now time.Time) (uint64, bool) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil || len(code) != Digits {
+		return 0, false
+	}
+	counter := uint64(now.Unix() / Period)
+	for _, step := range []uint64{counter - 1, counter, counter + 1} {
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// Source names who is guessing at what: a request and the address the codes
+// come from. Lockouts are per source, so a stranger guessing from elsewhere
+// can't lock the real user out.
+func Source(requestID, client string) string {
+	return requestID + " " + client
+}
+
+// Limiter counts wrong codes per source and remembers the TOTP steps already
+// used, in a file, so neither a restart nor a replayed code brings a fresh
+// guess. The desktop app and --mcp bridges sharing the file share both.
+type Limiter struct {
+	Path string // Empty keeps everything in memory only
+
+	mu    sync.Mutex
+	state limiterState
+}
+
+// limiterState is what Limiter keeps in its file
+type limiterState struct {
+	Failures  map[string][]time.Time `json:"failures"`   // By Source
+	TOTPSteps []uint64               `json:"totp_steps"` // Accepted, and still inside the drift window
+}
+
+// load reads the file and drops what has run out; callers hold l.mu
+func (l *Limiter) load(now time.Time) {
+	if l.Path != "" {
+		var saved limiterState
+		if data, err := os.ReadFile(l.Path); err == nil && json.Unmarshal(data, &saved) == nil {
+			l.state = saved
+		}
+	}
+	failures := make(map[string][]time.Time)
+	for source, times := range l.state.Failures {
+		var recent []time.Time
+		for _, at := range times {
+			if now.Sub(at) < Lockout {
+				recent = append(recent, at)
+			}
+		}
+		if len(recent) > 0 {
+			failures[source] = recent
+		}
+	}
+	l.state.Failures = failures
+
+	oldest := uint64(now.Unix()/Period) - 1
+	steps := l.state.TOTPSteps[:0]
+	for _, step := range l.state.TOTPSteps {
+		if step >= oldest {
+			steps = append(steps, step)
+		}
+	}
+	l.state.TOTPSteps = steps
+}
+
+// save writes the state back; callers hold l.mu
+func (l *Limiter) save() {
+	if l.Path != "" {
+		data, _ := json.Marshal(l.state)
+		os.WriteFile(l.Path, data, 0600)
+	}
+}
+
+// Locked reports whether source gave too many wrong codes recently
+func (l *Limiter) Locked(source string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.load(now)
+	return len(l.state.Failures[source]) >= MaxFailures
+}
+
+// Fail counts a wrong code from source and returns how many tries it has left
+func (l *Limiter) Fail(source string, now time.Time) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.load(now)
+	l.state.Failures[source] = append(l.state.Failures[source], now)
+	l.save()
+	return MaxFailures - len(l.state.Failures[source])
+}
+
+// SpendTOTP checks an authenticator code like ValidTOTP and uses up its time
+// step, so the same code can't be replayed while it would still be accepted
+func (l *Limiter) SpendTOTP(secret, code string, now time.Time) bool {
+	step, ok := matchTOTP(secret, code, now)
+	if !ok {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.load(now)
+	for _, used := range l.state.TOTPSteps {
+		if used == step {
+			return false
+		}
+	}
+	l.state.TOTPSteps = append(l.state.TOTPSteps, step)
+	l.save()
+	return true
+}
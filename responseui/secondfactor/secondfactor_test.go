@@ -0,0 +1,130 @@
+package secondfactor
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidTOTP(t *testing.T) {
+	// RFC 6238 appendix B, SHA-1 secret "12345678901234567890", last six digits
+	const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	tests := []struct {
+		name string
+		unix int64
+		code string
+		want bool
+	}{
+		{"rfc vector 59", 59, "287082", true},
+		{"rfc vector 1111111109", 1111111109, "081804", true},
+		{"rfc vector 1234567890", 1234567890, "005924", true},
+		{"rfc vector 2000000000", 2000000000, "279037", true},
+		{"previous step", 1111111109 + Period, "081804", true},
+		{"next step", 1111111109 - Period, "081804", true},
+		{"two steps late", 1111111109 + 2*Period, "081804", false},
+		{"wrong code", 59, "287083", false},
+		{"too short", 59, "28708", false},
+		{"empty", 59, "", false},
+	}
+	for _, tt := range tests {
+		if got := ValidTOTP(secret, tt.code, time.Unix(tt.unix, 0)); got != tt.want {
+			t.Errorf("%s: ValidTOTP = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if ValidTOTP("not base32!", "287082", time.Unix(59, 0)) {
+		t.Error("accepted a code for a malformed secret")
+	}
+}
+
+func TestVerifyPIN(t *testing.T) {
+	hashed := HashPIN("4821")
+	if !IsPINHash(hashed) || NeedsRehash(hashed) || strings.Contains(hashed, "4821") {
+		t.Fatalf("HashPIN = %q", hashed)
+	}
+	// "sha256:<salt>:<hash>" of "4821" with salt 00112233445566778899aabbccddeeff, as older versions saved it
+	const legacy = "sha256:00112233445566778899aabbccddeeff:b2a9866d7973243c5189a355e48a3421c49ca6d5f45b1cf110445ebd8c56bf55"
+	if HashPIN("4821") == hashed {
+		t.Error("two hashes of one PIN share a salt")
+	}
+
+	tests := []struct {
+		name  string
+		saved string
+		code  string
+		want  bool
+	}{
+		{"hashed", hashed, "4821", true},
+		{"hashed, wrong PIN", hashed, "4822", false},
+		{"hashed, the hash itself", hashed, hashed, false},
+		{"plain from the environment", "4821", "4821", true},
+		{"plain, wrong PIN", "4821", "1234", false},
+		{"older SHA-256 hash", legacy, "4821", true},
+		{"older SHA-256 hash, wrong PIN", legacy, "4822", false},
+		{"corrupt hash", "scrypt:zz:zz", "4821", false},
+		{"corrupt older hash", "sha256:zz:zz", "4821", false},
+		{"hash without salt", "scrypt:abcd", "4821", false},
+	}
+	for _, tt := range tests {
+		if got := VerifyPIN(tt.saved, tt.code); got != tt.want {
+			t.Errorf("%s: VerifyPIN = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if !NeedsRehash(legacy) || !NeedsRehash("4821") || NeedsRehash("") {
+		t.Error("NeedsRehash doesn't pick out plain PINs and older hashes")
+	}
+}
+
+func TestLimiterOutlastsRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	now := time.Now()
+	source := Source("req1", "203.0.113.7")
+
+	l := &Limiter{Path: path}
+	for i := 1; i < MaxFailures; i++ {
+		if left := l.Fail(source, now); left != MaxFailures-i {
+			t.Fatalf("after %d wrong codes %d tries left", i, left)
+		}
+	}
+	if l.Locked(source, now) {
+		t.Fatal("locked before the last try")
+	}
+
+	// A restarted bridge, or another process, continues the count
+	restarted := &Limiter{Path: path}
+	if left := restarted.Fail(source, now); left != 0 {
+		t.Errorf("%d tries left after a restart", left)
+	}
+	if !restarted.Locked(source, now) || !l.Locked(source, now) {
+		t.Error("not locked after the last try")
+	}
+	if l.Locked(source, now.Add(Lockout)) {
+		t.Error("still locked after the lockout")
+	}
+
+	// Someone else, or the same guesser on another request, is not locked out
+	if l.Locked(Source("req1", "198.51.100.2"), now) || l.Locked(Source("req2", "203.0.113.7"), now) {
+		t.Error("the lockout spread to other sources")
+	}
+}
+
+func TestSpendTOTP(t *testing.T) {
+	const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	path := filepath.Join(t.TempDir(), FileName)
+	at := time.Unix(1111111109, 0)
+
+	l := &Limiter{Path: path}
+	if !l.SpendTOTP(secret, "081804", at) {
+		t.Fatal("a valid code was refused")
+	}
+	if l.SpendTOTP(secret, "081804", at) {
+		t.Error("the same code was accepted twice")
+	}
+	// Another process sharing the file, a step later while the code still passes ValidTOTP
+	if other := (&Limiter{Path: path}); other.SpendTOTP(secret, "081804", at.Add(Period*time.Second)) {
+		t.Error("the code was replayed through another process")
+	}
+	if l.SpendTOTP(secret, "000000", at) {
+		t.Error("a wrong code was accepted")
+	}
+}
@@ -0,0 +1,25 @@
+package responseui
+
+import "time"
+
+// Vote is one answer to a request, from any channel
+type Vote struct {
+	Approver     string    `json:"approver"` // Empty for unrouted requests
+	Answer       string    `json:"answer"`
+	Via          string    `json:"via"`
+	User         string    `json:"user,omitempty"`      // Sender as the channel identifies them
+	ClientIP     string    `json:"client_ip,omitempty"` // For answers submitted over HTTP
+	At           time.Time `json:"at"`
+	SecondFactor string    `json:"second_factor,omitempty"` // "pin", "totp" or "desktop" for high-risk answers
+}
+
+// Name says who cast a vote: the approver, else the sender, else the channel
+func (v Vote) Name() string {
+	switch {
+	case v.Approver != "":
+		return v.Approver
+	case v.User != "":
+		return v.User
+	}
+	return v.Via
+}
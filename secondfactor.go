@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui/secondfactor"
+)
+
+// secondFactorHint tells the user where a high-risk question can be answered
+const secondFactorHint = "🔐 HIGH RISK - answer on the response page with your PIN or authenticator code"
+
+// factorLimiter counts wrong codes and used authenticator codes, in a file shared with the desktop app
+var factorLimiter *secondfactor.Limiter
+
+// openFactorLimiter keeps wrong codes next to bridge-config.json, so a restart doesn't reset them
+func openFactorLimiter() {
+	factorLimiter = &secondfactor.Limiter{Path: filepath.Join(filepath.Dir(configPath), secondfactor.FileName)}
+}
+
+// secondFactorEnabled reports whether APPROVAL_PIN or TOTP_SECRET is set
+func secondFactorEnabled() bool {
+	return os.Getenv("APPROVAL_PIN") != "" || os.Getenv("TOTP_SECRET") != ""
+}
+
+// needsSecondFactor reports whether answers to a request must come with a code
+func needsSecondFactor(id string) bool {
+	val, ok := requestDetails.Load(id)
+	return ok && val.(RequestDetails).SecondFactor
+}
+
+// verifySecondFactor returns "pin" or "totp" for a code that matches, or "" if
+// none does. An authenticator code can only be used once.
+func verifySecondFactor(code string, now time.Time) string {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return ""
+	}
+	if pin := os.Getenv("APPROVAL_PIN"); pin != "" && secondfactor.VerifyPIN(pin, code) {
+		return "pin"
+	}
+	if secret := os.Getenv("TOTP_SECRET"); secret != "" && factorLimiter.SpendTOTP(secret, code, now) {
+		return "totp"
+	}
+	return ""
+}
+
+// clientIP is the address an answer came from. The tunnel reaches the server
+// from localhost and appends the real client as the last X-Forwarded-For hop.
+func clientIP(r *http.Request) string {
+	if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+		hops := strings.Split(fwd, ",")
+		return strings.TrimSpace(hops[len(hops)-1])
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// checkSecondFactor gates an answer to a high-risk request on a PIN or TOTP code,
+// counting wrong codes per request and client address. Every refusal is logged
+// and audited; when it returns false the page to show has been written.
+func checkSecondFactor(w http.ResponseWriter, r *http.Request, token, id string, details RequestDetails, answer string) bool {
+	now := time.Now()
+	client := clientIP(r)
+	source := secondfactor.Source(id, client)
+	if answer == "" {
+		responsePages().SecondFactor(w, 200, token, details.page(), "", "")
+		return false
+	}
+	if factorLimiter.Locked(source, now) {
+		logInfo(fmt.Sprintf("🔐 Too many wrong codes recently, refused %q to %s from %s", answer, id, client))
+		auditRejection(id, details, answer, client, "second_factor_locked")
+		responsePages().Error(w, 429, "too_many_attempts")
+		return false
+	}
+	code := r.FormValue("code")
+	if code == "" {
+		responsePages().SecondFactor(w, 401, token, details.page(), answer, "")
+		return false
+	}
+
+	method := verifySecondFactor(code, now)
+	if method == "" {
+		left := factorLimiter.Fail(source, now)
+		logInfo(fmt.Sprintf("🔐 Wrong code for %s from %s (%d tries left)", id, client, left))
+		auditRejection(id, details, answer, client, "second_factor_failed")
+		pages := responsePages()
+		pages.SecondFactor(w, 401, token, details.page(), answer, pages.T("secondfactor.wrong_code", left))
+		return false
+	}
+	logInfo(fmt.Sprintf("🔐 %s confirmed with %s from %s", id, method, client))
+	return true
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui/requestlog"
+)
+
+// StoredRequest is the persisted state of a request, shared with the desktop app
+type StoredRequest = requestlog.Request
+
+var (
+	// requestLog is bridge-requests.jsonl; nil when it can't be opened
+	requestLog *requestlog.Log
+
+	// orphanRequests holds restored request IDs no ask call has reattached to yet
+	orphanRequests sync.Map
+)
+
+// openRequestStore opens bridge-requests.jsonl next to bridge-config.json and takes over
+// requests left pending by bridges that have since exited, so their links and buttons
+// keep working. Requests of a bridge that is still running stay its own.
+func openRequestStore() {
+	var err error
+	requestLog, err = requestlog.Open(filepath.Join(filepath.Dir(configPath), requestlog.FileName))
+	if err != nil {
+		logInfo("⚠️ Request store unavailable: " + err.Error())
+		return
+	}
+
+	claimed, expired, err := requestLog.ClaimOrphans(time.Now())
+	if err != nil {
+		logInfo("⚠️ Could not restore pending requests: " + err.Error())
+	}
+	for _, r := range claimed {
+		ch := make(chan string, 1)
+		if r.Undelivered() {
+			// Answered before its bridge died: the answer waits for the agent
+			ch <- r.Answer
+		}
+		pendingRequests.Store(r.ID, ch)
+		requestDetails.Store(r.ID, RequestDetails{Question: r.Question, Options: r.Options, Context: r.QuestionContext(), Schema: r.AnswerSchema(), SecondFactor: r.SecondFactor})
+		orphanRequests.Store(r.ID, true)
+		go expireOrphan(r.ID, r.Deadline)
+	}
+	if len(claimed) > 0 || len(expired) > 0 {
+		logInfo(fmt.Sprintf("♻️ Restored %d pending request(s); %d expired while the bridge was down", len(claimed), len(expired)))
+	}
+}
+
+// saveRequest records the full state of a new request
+func saveRequest(r StoredRequest) {
+	if err := requestLog.Save(r); err != nil {
+		logInfo("⚠️ Could not persist request " + r.ID + ": " + err.Error())
+	}
+}
+
+// updateRequest applies fn to a stored request and saves it; unknown IDs are ignored
+func updateRequest(id string, fn func(r *StoredRequest)) {
+	if err := requestLog.Update(id, fn); err != nil {
+		logInfo("⚠️ Could not persist request " + id + ": " + err.Error())
+	}
+}
+
+// storedRequest returns the latest state of a request
+func storedRequest(id string) (StoredRequest, bool) {
+	return requestLog.Get(id)
+}
+
+// adoptOrphan hands a restored request to a new ask call, so an answer given while
+// the agent was disconnected is not lost. The agent names the request by the
+// request_id it was given; without one, a request with the same question and
+// options is taken, which is best effort.
+func adoptOrphan(requestID, question string, options []string) (string, chan string, bool, error) {
+	if requestID == "" {
+		orphanRequests.Range(func(key, _ interface{}) bool {
+			r, _ := storedRequest(key.(string))
+			if r.Question == question && equalOptions(r.Options, options) {
+				requestID = key.(string)
+				return false
+			}
+			return true
+		})
+		if requestID == "" {
+			return "", nil, false, nil
+		}
+	}
+	if _, loaded := orphanRequests.LoadAndDelete(requestID); !loaded {
+		if r, ok := storedRequest(requestID); ok {
+			return "", nil, false, fmt.Errorf("request %s is %s and can't be resumed", requestID, r.Status)
+		}
+		return "", nil, false, fmt.Errorf("unknown request %s", requestID)
+	}
+	ch, ok := pendingRequests.Load(requestID)
+	if !ok {
+		return "", nil, false, fmt.Errorf("request %s expired", requestID)
+	}
+	return requestID, ch.(chan string), true, nil
+}
+
+// expireOrphan drops a restored request nobody reattached to by its deadline.
+// An answer it got meanwhile never reached an agent, so it is expired too.
+func expireOrphan(id string, deadline time.Time) {
+	time.Sleep(time.Until(deadline))
+	if _, loaded := orphanRequests.LoadAndDelete(id); !loaded {
+		return
+	}
+	pendingRequests.Delete(id)
+	requestDetails.Delete(id)
+	updateRequest(id, func(r *StoredRequest) { r.Status = "expired" })
+}
+
+// writeRequestGone explains why a request is no longer pending
+func writeRequestGone(w http.ResponseWriter, id string) {
+	r, ok := storedRequest(id)
+	switch {
+	case ok && r.Status == "answered":
+		responsePages().Error(w, 409, "answered_with", r.Answer)
+	case ok && (r.Status == "expired" || r.Status == "pending"):
+		responsePages().Expired(w, "expired", r.Deadline.Local().Format("2006-01-02 15:04"))
+	default:
+		responsePages().Error(w, 404, "not_found")
+	}
+}
+
+func equalOptions(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,171 @@
+// Package main - telegram.go
+// Telegram update loop for Remote Bridge
+// Inline callback buttons and message replies answer pending requests without opening the HTML form
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HarshalPatel1972/remote-bridge/responseui"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// telegramMessages maps request ID -> Telegram message ID of its notification
+var telegramMessages sync.Map
+
+// telegramPoller is the bot currently long-polling for updates
+var (
+	telegramPoller   *tgbotapi.BotAPI
+	telegramPollerMu sync.Mutex
+)
+
+// telegramOptionButtons renders one callback button per option ("<requestID>:<index>")
+func telegramOptionButtons(options []string, requestID string) [][]tgbotapi.InlineKeyboardButton {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, opt := range options {
+		data := fmt.Sprintf("%s:%d", requestID, i)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(opt, data)))
+	}
+	return rows
+}
+
+// parseTelegramCallback reads the "<requestID>:<index>" data of an option button
+func parseTelegramCallback(data string) (string, int, bool) {
+	requestID, number, ok := strings.Cut(data, ":")
+	index, err := strconv.Atoi(number)
+	if !ok || requestID == "" || err != nil {
+		return "", 0, false
+	}
+	return requestID, index, true
+}
+
+// startTelegramUpdates (re)starts the long-polling loop for the current bot
+func startTelegramUpdates(bot *tgbotapi.BotAPI) {
+	telegramPollerMu.Lock()
+	defer telegramPollerMu.Unlock()
+
+	if telegramPoller != nil {
+		telegramPoller.StopReceivingUpdates()
+	}
+	telegramPoller = bot
+
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = 30
+	u.AllowedUpdates = []string{"message", "callback_query"}
+	updates := bot.GetUpdatesChan(u)
+
+	go func() {
+		for update := range updates {
+			if update.CallbackQuery != nil {
+				handleTelegramCallback(bot, update.CallbackQuery)
+			} else if update.Message != nil && update.Message.ReplyToMessage != nil {
+				handleTelegramReply(bot, update.Message)
+			}
+		}
+	}()
+	logInfo("📥 Telegram update loop started")
+}
+
+// handleTelegramCallback resolves a pending request from an inline button press
+func handleTelegramCallback(bot *tgbotapi.BotAPI, cb *tgbotapi.CallbackQuery) {
+	// Only the configured chat may answer
+	if cb.Message == nil || cb.Message.Chat.ID != telegramChatID {
+		bot.Request(tgbotapi.NewCallback(cb.ID, "⛔ Not allowed"))
+		return
+	}
+
+	reqID, index, ok := parseTelegramCallback(cb.Data)
+	if !ok {
+		bot.Request(tgbotapi.NewCallback(cb.ID, ""))
+		return
+	}
+
+	val, ok := requestDetails.Load(reqID)
+	details, _ := val.(RequestDetails)
+	if !ok || index < 0 || index >= len(details.Options) {
+		bot.Request(tgbotapi.NewCallback(cb.ID, "⌛ Expired or already answered"))
+		return
+	}
+
+	if details.SecondFactor {
+		logInfo(fmt.Sprintf("🔐 Ignored Telegram button for high-risk %s", reqID))
+		bot.Request(tgbotapi.NewCallback(cb.ID, secondFactorHint))
+		return
+	}
+
+	answer := details.Options[index]
+	user := telegramUserName(cb.From)
+	if !resolveRequest(reqID, responseui.Vote{Answer: answer, Via: "telegram", User: user}) {
+		bot.Request(tgbotapi.NewCallback(cb.ID, "⌛ Already answered"))
+		return
+	}
+
+	logInfo(fmt.Sprintf("📥 Telegram response from %s: %s -> %s", user, reqID, answer))
+	bot.Request(tgbotapi.NewCallback(cb.ID, "✅ Sent: "+answer))
+	markTelegramAnswered(bot, cb.Message, answer, user)
+}
+
+// handleTelegramReply treats a reply to a notification as a custom answer
+func handleTelegramReply(bot *tgbotapi.BotAPI, msg *tgbotapi.Message) {
+	if msg.Chat.ID != telegramChatID || strings.TrimSpace(msg.Text) == "" {
+		return
+	}
+
+	original := msg.ReplyToMessage
+	reqID := ""
+	telegramMessages.Range(func(key, value interface{}) bool {
+		if value.(int) == original.MessageID {
+			reqID = key.(string)
+			return false
+		}
+		return true
+	})
+	if reqID == "" {
+		return
+	}
+
+	answer := strings.TrimSpace(msg.Text)
+	reply := tgbotapi.NewMessage(msg.Chat.ID, "⌛ That request has expired or was already answered.")
+	reply.ReplyToMessageID = msg.MessageID
+	if needsSecondFactor(reqID) {
+		logInfo(fmt.Sprintf("🔐 Ignored Telegram reply for high-risk %s", reqID))
+		reply.Text = secondFactorHint
+		bot.Send(reply)
+		return
+	}
+	user := telegramUserName(msg.From)
+	if !resolveRequest(reqID, responseui.Vote{Answer: answer, Via: "telegram", User: user}) {
+		bot.Send(reply)
+		return
+	}
+
+	logInfo(fmt.Sprintf("📥 Telegram reply from %s: %s -> %s", user, reqID, answer))
+	reply.Text = "✅ Sent to your agent."
+	bot.Send(reply)
+
+	markTelegramAnswered(bot, original, answer, user)
+}
+
+// markTelegramAnswered replaces a notification's buttons with the outcome
+func markTelegramAnswered(bot *tgbotapi.BotAPI, original *tgbotapi.Message, answer string, user string) {
+	text := fmt.Sprintf("%s\n\n✅ %s by %s at %s", original.Text, answer, user, time.Now().Format("15:04"))
+	if _, err := bot.Send(tgbotapi.NewEditMessageText(original.Chat.ID, original.MessageID, text)); err != nil {
+		logInfo("⚠️ Telegram edit failed: " + err.Error())
+	}
+}
+
+// telegramUserName returns a display name for the user who answered
+func telegramUserName(u *tgbotapi.User) string {
+	if u == nil {
+		return "unknown"
+	}
+	if u.UserName != "" {
+		return "@" + u.UserName
+	}
+	return u.FirstName
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// telegramCall is one Bot API method the bridge called, with its text
+type telegramCall struct {
+	Method string
+	Text   string
+}
+
+// startFakeTelegram stands in for the Bot API and returns a bot that talks to it
+func startFakeTelegram(t *testing.T) (*tgbotapi.BotAPI, chan telegramCall) {
+	t.Helper()
+	calls := make(chan telegramCall, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		switch method {
+		case "getMe":
+			io.WriteString(w, `{"ok":true,"result":{"id":1,"is_bot":true,"username":"bridge_bot"}}`)
+		case "answerCallbackQuery":
+			calls <- telegramCall{method, r.FormValue("text")}
+			io.WriteString(w, `{"ok":true,"result":true}`)
+		default:
+			calls <- telegramCall{method, r.FormValue("text")}
+			io.WriteString(w, `{"ok":true,"result":{"message_id":99,"chat":{"id":100}}}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	bot, err := tgbotapi.NewBotAPIWithAPIEndpoint("token", server.URL+"/bot%s/%s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bot, calls
+}
+
+func TestParseTelegramCallback(t *testing.T) {
+	tests := []struct {
+		data      string
+		wantID    string
+		wantIndex int
+		wantOK    bool
+	}{
+		{"ab12cd34:1", "ab12cd34", 1, true},
+		{"ab12cd34:0", "ab12cd34", 0, true},
+		{"ab12cd34:-1", "ab12cd34", -1, true}, // Refused later as out of range
+		{"ab12cd34:x", "", 0, false},
+		{"ab12cd34:", "", 0, false},
+		{"ab12cd34", "", 0, false},
+		{":1", "", 0, false},
+		{"", "", 0, false},
+	}
+	for _, tt := range tests {
+		id, index, ok := parseTelegramCallback(tt.data)
+		if id != tt.wantID || index != tt.wantIndex || ok != tt.wantOK {
+			t.Errorf("parseTelegramCallback(%q) = %q, %d, %v", tt.data, id, index, ok)
+		}
+	}
+}
+
+func TestHandleTelegramCallback(t *testing.T) {
+	bot, calls := startFakeTelegram(t)
+	telegramChatID = 100
+
+	ch := make(chan string, 1)
+	pendingRequests.Store("t1", ch)
+	requestDetails.Store("t1", RequestDetails{Question: "Deploy?", Options: []string{"Yes", "No"}})
+	defer pendingRequests.Delete("t1")
+	defer requestDetails.Delete("t1")
+
+	press := func(chatID int64, data string) *tgbotapi.CallbackQuery {
+		return &tgbotapi.CallbackQuery{
+			ID:      "cb",
+			From:    &tgbotapi.User{ID: 5, UserName: "ana"},
+			Message: &tgbotapi.Message{MessageID: 99, Chat: &tgbotapi.Chat{ID: chatID}, Text: "Deploy?"},
+			Data:    data,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		cb       *tgbotapi.CallbackQuery
+		want     string // Text of the callback answer
+		answered bool
+	}{
+		{"other chat", press(200, "t1:0"), "⛔ Not allowed", false},
+		{"no message", &tgbotapi.CallbackQuery{ID: "cb", Data: "t1:0"}, "⛔ Not allowed", false},
+		{"bad data", press(100, "t1:yes"), "", false},
+		{"index out of range", press(100, "t1:2"), "⌛ Expired or already answered", false},
+		{"negative index", press(100, "t1:-1"), "⌛ Expired or already answered", false},
+		{"unknown request", press(100, "zz:0"), "⌛ Expired or already answered", false},
+		{"answer", press(100, "t1:1"), "✅ Sent: No", true},
+	}
+	for _, tt := range tests {
+		handleTelegramCallback(bot, tt.cb)
+		if call := <-calls; call.Method != "answerCallbackQuery" || call.Text != tt.want {
+			t.Errorf("%s: answered the press with %+v, want %q", tt.name, call, tt.want)
+		}
+		if answered := len(ch) == 1; answered != tt.answered {
+			t.Errorf("%s: answered = %v", tt.name, answered)
+		}
+	}
+	if answer := <-ch; answer != "No" {
+		t.Errorf("answer = %q", answer)
+	}
+	if call := <-calls; call.Method != "editMessageText" || !strings.Contains(call.Text, "No by @ana") {
+		t.Errorf("the notification was not closed: %+v", call)
+	}
+}